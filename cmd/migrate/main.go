@@ -0,0 +1,159 @@
+// migrate 数据库迁移工具，按 database.driver 执行编译时嵌入的 sqls/migrations/{driver} 脚本
+//
+// 用法: migrate [-env dev] <command> [args]
+//
+//	up           执行全部未执行的迁移
+//	down [N]     回滚最近执行的 N 个迁移，默认 1
+//	status       查看迁移的执行状态
+//	redo         回滚并重新执行最近执行的一个迁移
+//	create NAME  在 sqls/migrations 下为每个方言创建新的迁移脚本
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	_ "github.com/go-sql-driver/mysql" // MySQL驱动
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq" // PostgreSQL驱动
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/infrastructure/database/dialect"
+	"github.com/lyonnee/go-template/internal/infrastructure/database/migrate"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"github.com/lyonnee/go-template/sqls"
+)
+
+// migrationsDir create 命令写入新脚本的目录，相对于项目根目录，每个方言一个子目录
+const migrationsDir = "sqls/migrations"
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	defer log.Sync()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		usage()
+		return 2
+	}
+
+	if args[0] == "create" {
+		if len(args) < 2 {
+			usage()
+			return 2
+		}
+		// 各方言使用相同的版本号，分别创建脚本
+		for _, name := range []string{dialect.Postgres, dialect.Mysql} {
+			upPath, downPath, err := migrate.Create(filepath.Join(migrationsDir, name), args[1])
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "create migration: %v\n", err)
+				return 1
+			}
+			fmt.Printf("Created %s\nCreated %s\n", upPath, downPath)
+		}
+		return 0
+	}
+
+	conf := di.Get[config.Config]().Database
+	d, err := dialect.Get(conf.Driver)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	dsn := conf.Postgres.DSN
+	if d.Name() == dialect.Mysql {
+		dsn = conf.Mysql.DSN
+	}
+	db, err := sqlx.Connect(d.Name(), dsn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "connect database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, d, sqls.Migrations(d.Name()), di.Get[*log.Logger]())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load migrations: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate up: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Applied %d migration(s)\n", count)
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n <= 0 {
+				fmt.Fprintf(os.Stderr, "invalid N: %s\n", args[1])
+				return 2
+			}
+		}
+		count, err := migrator.Down(ctx, n)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate down: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Rolled back %d migration(s)\n", count)
+	case "redo":
+		if err := migrator.Redo(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "migrate redo: %v\n", err)
+			return 1
+		}
+		fmt.Println("Redo completed")
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate status: %v\n", err)
+			return 1
+		}
+		printStatus(statuses)
+	default:
+		usage()
+		return 2
+	}
+
+	return 0
+}
+
+func printStatus(statuses []migrate.Status) {
+	fmt.Printf("%-8s %-40s %-10s %s\n", "VERSION", "NAME", "STATE", "APPLIED AT")
+	for _, s := range statuses {
+		state, appliedAt := "pending", ""
+		if s.Applied {
+			state = "applied"
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		switch {
+		case s.Missing:
+			state = "missing"
+		case s.Modified:
+			state = "modified"
+		}
+		fmt.Printf("%06d   %-40s %-10s %s\n", s.Version, s.Name, state, appliedAt)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: migrate [-env dev] <command> [args]
+
+Commands:
+  up           apply all pending migrations
+  down [N]     roll back the last N applied migrations (default 1)
+  status       show migration status
+  redo         roll back and re-apply the last applied migration
+  create NAME  create a new migration for each dialect in sqls/migrations`)
+}
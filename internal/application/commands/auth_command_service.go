@@ -3,12 +3,17 @@ package commands
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/lyonnee/go-template/pkg/log"
 
+	"github.com/lyonnee/go-template/internal/domain/entity"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
 	"github.com/lyonnee/go-template/internal/domain/repository"
+	"github.com/lyonnee/go-template/internal/domain/service"
 	"github.com/lyonnee/go-template/internal/infrastructure/auth"
 	"github.com/lyonnee/go-template/internal/infrastructure/database"
+	"github.com/lyonnee/go-template/internal/infrastructure/eventbus"
 	"github.com/lyonnee/go-template/pkg/di"
 	"go.uber.org/zap"
 )
@@ -17,7 +22,20 @@ type AuthCommandService struct {
 	logger    *log.Logger
 	dbContext *database.Database
 
-	userRepo repository.UserRepository
+	userRepo         repository.UserRepository
+	userIdentityRepo repository.UserIdentityRepository
+	roleRepo         repository.RoleRepository
+	recoveryCodeRepo repository.RecoveryCodeRepository
+
+	userDomainService *service.UserService
+
+	sessionManager *auth.SessionManager
+	siweVerifier   *auth.SiweVerifier
+	oauthManager   *auth.OAuthManager
+	mfaManager     *auth.MfaManager
+	loginGuard     *auth.LoginGuard
+
+	eventBus *eventbus.EventBus
 }
 
 func init() {
@@ -30,7 +48,20 @@ func NewAuthCommandService() (*AuthCommandService, error) {
 		logger:    di.Get[*log.Logger](),
 		dbContext: di.Get[*database.Database](),
 
-		userRepo: di.Get[repository.UserRepository](),
+		userRepo:         di.Get[repository.UserRepository](),
+		userIdentityRepo: di.Get[repository.UserIdentityRepository](),
+		roleRepo:         di.Get[repository.RoleRepository](),
+		recoveryCodeRepo: di.Get[repository.RecoveryCodeRepository](),
+
+		userDomainService: di.Get[*service.UserService](),
+
+		sessionManager: di.Get[*auth.SessionManager](),
+		siweVerifier:   di.Get[*auth.SiweVerifier](),
+		oauthManager:   di.Get[*auth.OAuthManager](),
+		mfaManager:     di.Get[*auth.MfaManager](),
+		loginGuard:     di.Get[*auth.LoginGuard](),
+
+		eventBus: di.Get[*eventbus.EventBus](),
 	}, nil
 }
 
@@ -38,50 +69,163 @@ func NewAuthCommandService() (*AuthCommandService, error) {
 type LoginCmd struct {
 	Username string
 	Password string
+	ClientIP string
 }
 
 // LoginResult 登录结果
+// 用户已启用多因素认证时 MfaRequired 为 true，不返回令牌，需使用 MfaToken 调用 VerifyMfa 完成登录
 type LoginResult struct {
 	AccessToken  string
 	RefreshToken string
+	MfaRequired  bool
+	MfaToken     string
 }
 
 // Login 用户登录
+// 用户名或IP失败次数过多时返回 *auth.LockedError，失败后的响应会逐次延迟
 func (s *AuthCommandService) Login(ctx context.Context, cmd *LoginCmd) (*LoginResult, error) {
-	s.logger.Debug("Login attempt", zap.String("username", cmd.Username))
+	s.logger.Debug("Login attempt", zap.String("username", cmd.Username), zap.String("ip", cmd.ClientIP))
 
-	var accessToken, refreshToken string
-	if err := s.dbContext.Conn(ctx, func(ctx context.Context) error {
+	if err := s.loginGuard.Check(ctx, cmd.Username, cmd.ClientIP); err != nil {
+		s.logger.Warn("Login rejected - locked", zap.String("username", cmd.Username), zap.String("ip", cmd.ClientIP), zap.Error(err))
+		return nil, err
+	}
+
+	var accessToken, refreshToken, mfaToken string
+	// 登录事件与签发令牌在同一个事务中，签发失败时事件不会写入 outbox
+	if err := s.dbContext.Transaction(ctx, nil, func(ctx context.Context) error {
 		// 查找用户
 		user, err := s.userRepo.FindByUsername(ctx, cmd.Username)
 		if err != nil {
+			if errors.Is(err, domainErrors.ErrUserNotFound) {
+				s.logger.Warn("Login failed - user not found", zap.String("username", cmd.Username))
+				return errInvalidCredentials
+			}
 			return err
 		}
 
 		if err := user.Login(cmd.Password); err != nil {
+			if errors.Is(err, domainErrors.ErrMfaRequired) {
+				// 密码正确但需要第二因素，创建登录挑战，暂不签发令牌
+				mfaToken, err = s.mfaManager.IssueChallenge(ctx, user.ID, "password")
+				if err != nil {
+					return err
+				}
+				s.logger.Info("Login requires mfa", zap.String("username", cmd.Username), zap.Uint64("userId", user.ID))
+				return nil
+			}
 			s.logger.Warn("Login failed - invalid password", zap.String("username", cmd.Username), zap.Uint64("userId", user.ID))
-			return errors.New("invalid username or password")
+			return errInvalidCredentials
 		}
 
-		jwtGenerator := di.Get[*auth.JWTGenerator]()
-
-		accessToken, err = jwtGenerator.GenerateAccessToken(user.ID, user.Username)
+		tokens, err := s.issueTokens(ctx, user.ID, user.Username)
 		if err != nil {
 			return err
 		}
+		accessToken, refreshToken = tokens.AccessToken, tokens.RefreshToken
 
-		refreshToken, err = jwtGenerator.GenerateRefreshToken(user.ID, user.Username)
-		if err != nil {
+		if err := s.eventBus.Collect(ctx, user.PullEvents()...); err != nil {
 			return err
 		}
 
 		s.logger.Info("User logged in successfully", zap.String("username", cmd.Username), zap.Uint64("userId", user.ID))
 		return nil
 	}); err != nil {
+		if errors.Is(err, errInvalidCredentials) {
+			return nil, s.recordLoginFailure(ctx, cmd.Username, cmd.ClientIP, err)
+		}
 		s.logger.Error("Database connection failed", zap.Error(err))
 		return nil, err
 	}
 
+	if mfaToken != "" {
+		// 第二因素校验通过前不清除失败计数
+		return &LoginResult{
+			MfaRequired: true,
+			MfaToken:    mfaToken,
+		}, nil
+	}
+
+	s.recordLoginSuccess(ctx, cmd.Username)
+
+	return &LoginResult{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// VerifyMfaCmd 两步登录的第二步，提交 TOTP 验证码或恢复码
+type VerifyMfaCmd struct {
+	MfaToken string
+	Code     string
+	ClientIP string
+}
+
+// VerifyMfa 校验第二因素并签发令牌
+// 验证码错误超过最大次数后挑战失效，需要重新输入密码
+// 验证码错误同样计入用户名和IP的登录失败次数
+func (s *AuthCommandService) VerifyMfa(ctx context.Context, cmd *VerifyMfaCmd) (*LoginResult, error) {
+	challenge, err := s.mfaManager.Challenge(ctx, cmd.MfaToken)
+	if err != nil {
+		s.logger.Warn("Invalid mfa challenge", zap.Error(err))
+		return nil, err
+	}
+	userID := challenge.UserID
+
+	var username string
+	var accessToken, refreshToken string
+	// 恢复码的核销、登录事件与签发令牌在同一个事务中，签发失败时一并回滚
+	if err := s.dbContext.Transaction(ctx, nil, func(ctx context.Context) error {
+		user, err := s.userRepo.FindById(ctx, userID)
+		if err != nil {
+			return err
+		}
+		username = user.Username
+
+		if err := s.loginGuard.Check(ctx, user.Username, cmd.ClientIP); err != nil {
+			s.logger.Warn("Mfa verification rejected - locked", zap.Uint64("userId", userID), zap.String("ip", cmd.ClientIP), zap.Error(err))
+			return err
+		}
+
+		factor, err := verifySecondFactor(ctx, s.mfaManager, s.recoveryCodeRepo, user, cmd.Code)
+		if err != nil {
+			if errors.Is(err, domainErrors.ErrInvalidMfaCode) {
+				s.logger.Warn("Mfa verification failed - invalid code", zap.Uint64("userId", userID))
+				return s.mfaManager.FailChallenge(ctx, cmd.MfaToken)
+			}
+			return err
+		}
+
+		// 挑战只能完成一次
+		if err := s.mfaManager.CompleteChallenge(ctx, cmd.MfaToken); err != nil {
+			return err
+		}
+
+		if err := user.CompleteMfaLogin(challenge.Method, factor); err != nil {
+			return err
+		}
+
+		tokens, err := s.issueTokens(ctx, user.ID, user.Username)
+		if err != nil {
+			return err
+		}
+		accessToken, refreshToken = tokens.AccessToken, tokens.RefreshToken
+
+		if err := s.eventBus.Collect(ctx, user.PullEvents()...); err != nil {
+			return err
+		}
+
+		s.logger.Info("User logged in with mfa successfully", zap.Uint64("userId", user.ID), zap.String("factor", factor))
+		return nil
+	}); err != nil {
+		if username != "" && (errors.Is(err, domainErrors.ErrInvalidMfaCode) || errors.Is(err, domainErrors.ErrInvalidMfaChallenge)) {
+			return nil, s.recordLoginFailure(ctx, username, cmd.ClientIP, err)
+		}
+		return nil, err
+	}
+
+	s.recordLoginSuccess(ctx, username)
+
 	return &LoginResult{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -95,31 +239,370 @@ type RefreshTokenCmd struct {
 
 // RefreshTokenResult 刷新token结果
 type RefreshTokenResult struct {
-	AccessToken string
+	AccessToken  string
+	RefreshToken string
 }
 
-// RefreshToken 刷新token
+// RefreshToken 刷新token，每次刷新都会轮换刷新令牌，旧的刷新令牌随即失效
 func (s *AuthCommandService) RefreshToken(ctx context.Context, cmd *RefreshTokenCmd) (*RefreshTokenResult, error) {
 	s.logger.Debug("RefreshToken called")
-	jwtManager := di.Get[*auth.JWTGenerator]()
 
-	// 验证刷新token
-	claims, err := jwtManager.ValidateToken(cmd.RefreshToken)
+	tokens, err := s.sessionManager.Rotate(ctx, cmd.RefreshToken, s.loadRoleNames)
 	if err != nil {
-		s.logger.Warn("Invalid refresh token provided", zap.Error(err))
-		return nil, errors.New("invalid refresh token")
+		if errors.Is(err, domainErrors.ErrRefreshTokenReused) {
+			s.logger.Warn("Refresh token reuse detected, session revoked", zap.Error(err))
+		} else {
+			s.logger.Warn("Invalid refresh token provided", zap.Error(err))
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Token refreshed successfully", zap.String("sessionId", tokens.SessionID))
+
+	return &RefreshTokenResult{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}, nil
+}
+
+// LogoutCmd 退出登录命令
+type LogoutCmd struct {
+	UserID    uint64
+	SessionID string
+}
+
+// Logout 退出登录，撤销当前会话
+func (s *AuthCommandService) Logout(ctx context.Context, cmd *LogoutCmd) error {
+	if err := s.sessionManager.Revoke(ctx, cmd.SessionID); err != nil {
+		s.logger.Error("Failed to revoke session", zap.Error(err), zap.Uint64("userId", cmd.UserID))
+		return err
 	}
 
-	// 生成新的访问token
-	newAccessToken, err := jwtManager.GenerateAccessToken(claims.UserId, claims.AlternativeID)
+	s.logger.Info("User logged out successfully", zap.Uint64("userId", cmd.UserID), zap.String("sessionId", cmd.SessionID))
+	return nil
+}
+
+// SiweNonce 生成 Sign-In with Ethereum 登录所需的一次性 nonce
+func (s *AuthCommandService) SiweNonce(ctx context.Context) (string, error) {
+	nonce, err := s.siweVerifier.IssueNonce(ctx)
 	if err != nil {
-		s.logger.Error("Failed to generate new access token", zap.Error(err), zap.Uint64("userId", claims.UserId))
+		s.logger.Error("Failed to issue siwe nonce", zap.Error(err))
+		return "", err
+	}
+
+	return nonce, nil
+}
+
+// SiweLoginCmd 钱包登录命令
+type SiweLoginCmd struct {
+	Message   string
+	Signature string
+}
+
+// SiweLoginResult 钱包登录结果
+// 用户已启用多因素认证时 MfaRequired 为 true，不返回令牌，需使用 MfaToken 调用 VerifyMfa 完成登录
+type SiweLoginResult struct {
+	AccessToken  string
+	RefreshToken string
+	MfaRequired  bool
+	MfaToken     string
+	User         *entity.User
+	Created      bool // 是否为首次登录新建的用户
+}
+
+// SiweLogin 校验 EIP-4361 消息和签名，按钱包地址查找用户，不存在时自动创建
+// 用户已启用多因素认证时与密码登录相同，创建登录挑战而不签发令牌
+func (s *AuthCommandService) SiweLogin(ctx context.Context, cmd *SiweLoginCmd) (*SiweLoginResult, error) {
+	msg, err := s.siweVerifier.Verify(ctx, cmd.Message, cmd.Signature)
+	if err != nil {
+		s.logger.Warn("Siwe verification failed", zap.Error(err))
 		return nil, err
 	}
 
-	s.logger.Info("Access token refreshed successfully", zap.Uint64("userId", claims.UserId))
+	s.logger.Debug("Siwe login attempt", zap.String("address", msg.Address))
 
-	return &RefreshTokenResult{
-		AccessToken: newAccessToken,
-	}, nil
+	var result SiweLoginResult
+	if err := s.dbContext.Transaction(ctx, nil, func(ctx context.Context) error {
+		user, err := s.userRepo.FindByWalletAddress(ctx, msg.Address)
+		if err != nil && !errors.Is(err, domainErrors.ErrUserNotFound) {
+			return err
+		}
+
+		if user == nil {
+			user, err = s.userDomainService.NewWalletUser(ctx, msg.Address)
+			if err != nil {
+				return err
+			}
+
+			if err := s.userRepo.Create(ctx, user); err != nil {
+				return err
+			}
+
+			user.Register()
+			result.Created = true
+		}
+
+		result.User = user
+
+		if err := user.LoginWithWallet(msg.Address); err != nil {
+			if errors.Is(err, domainErrors.ErrMfaRequired) {
+				result.MfaRequired = true
+				result.MfaToken, err = s.mfaManager.IssueChallenge(ctx, user.ID, "wallet")
+				if err != nil {
+					return err
+				}
+				s.logger.Info("Wallet login requires mfa", zap.String("address", msg.Address), zap.Uint64("userId", user.ID))
+				return nil
+			}
+			return err
+		}
+
+		tokens, err := s.issueTokens(ctx, user.ID, user.WalletAddress)
+		if err != nil {
+			return err
+		}
+		result.AccessToken, result.RefreshToken = tokens.AccessToken, tokens.RefreshToken
+
+		return s.eventBus.Collect(ctx, user.PullEvents()...)
+	}); err != nil {
+		s.logger.Error("Siwe login failed", zap.Error(err), zap.String("address", msg.Address))
+		return nil, err
+	}
+
+	if result.MfaRequired {
+		return &result, nil
+	}
+
+	s.logger.Info("User logged in with wallet successfully",
+		zap.String("address", msg.Address),
+		zap.Uint64("userId", result.User.ID),
+		zap.Bool("created", result.Created))
+
+	return &result, nil
+}
+
+// OAuthLoginURL 生成第三方登录的授权地址
+func (s *AuthCommandService) OAuthLoginURL(ctx context.Context, provider string) (string, error) {
+	authURL, err := s.oauthManager.AuthCodeURL(ctx, provider)
+	if err != nil {
+		s.logger.Error("Failed to build oauth authorization url", zap.Error(err), zap.String("provider", provider))
+		return "", err
+	}
+
+	return authURL, nil
+}
+
+// OAuthCallbackCmd 第三方登录回调命令
+type OAuthCallbackCmd struct {
+	Provider string
+	Code     string
+	State    string
+}
+
+// OAuthLoginResult 第三方登录结果
+// 用户已启用多因素认证时 MfaRequired 为 true，不返回令牌，需使用 MfaToken 调用 VerifyMfa 完成登录
+type OAuthLoginResult struct {
+	AccessToken  string
+	RefreshToken string
+	MfaRequired  bool
+	MfaToken     string
+	User         *entity.User
+	Created      bool // 是否为首次登录新建的用户
+}
+
+// OAuthCallback 使用授权码换取第三方身份并登录
+// 已关联的身份直接登录；未关联时按已验证的邮箱关联已有用户，否则新建用户
+// 用户已启用多因素认证时创建登录挑战而不签发令牌，通过邮箱关联的身份同样需要完成第二因素
+func (s *AuthCommandService) OAuthCallback(ctx context.Context, cmd *OAuthCallbackCmd) (*OAuthLoginResult, error) {
+	identity, err := s.oauthManager.Exchange(ctx, cmd.Provider, cmd.Code, cmd.State)
+	if err != nil {
+		s.logger.Warn("OAuth exchange failed", zap.Error(err), zap.String("provider", cmd.Provider))
+		return nil, err
+	}
+
+	s.logger.Debug("OAuth login attempt", zap.String("provider", identity.Provider), zap.String("subject", identity.Subject))
+
+	var result OAuthLoginResult
+	if err := s.dbContext.Transaction(ctx, nil, func(ctx context.Context) error {
+		user, err := s.findOrCreateOAuthUser(ctx, identity, &result.Created)
+		if err != nil {
+			return err
+		}
+
+		result.User = user
+
+		if err := user.LoginWithOAuth(identity.Provider); err != nil {
+			if errors.Is(err, domainErrors.ErrMfaRequired) {
+				result.MfaRequired = true
+				result.MfaToken, err = s.mfaManager.IssueChallenge(ctx, user.ID, "oauth:"+identity.Provider)
+				if err != nil {
+					return err
+				}
+				s.logger.Info("OAuth login requires mfa", zap.String("provider", identity.Provider), zap.Uint64("userId", user.ID))
+				return nil
+			}
+			return err
+		}
+
+		alternativeID := user.Email
+		if alternativeID == "" {
+			alternativeID = user.Username
+		}
+
+		tokens, err := s.issueTokens(ctx, user.ID, alternativeID)
+		if err != nil {
+			return err
+		}
+		result.AccessToken, result.RefreshToken = tokens.AccessToken, tokens.RefreshToken
+
+		return s.eventBus.Collect(ctx, user.PullEvents()...)
+	}); err != nil {
+		s.logger.Error("OAuth login failed", zap.Error(err), zap.String("provider", identity.Provider))
+		return nil, err
+	}
+
+	if result.MfaRequired {
+		return &result, nil
+	}
+
+	s.logger.Info("User logged in with oauth successfully",
+		zap.String("provider", identity.Provider),
+		zap.Uint64("userId", result.User.ID),
+		zap.Bool("created", result.Created))
+
+	return &result, nil
+}
+
+// findOrCreateOAuthUser 查找第三方身份关联的用户，不存在时关联或新建用户
+func (s *AuthCommandService) findOrCreateOAuthUser(ctx context.Context, identity *auth.OAuthIdentity, created *bool) (*entity.User, error) {
+	linked, err := s.userIdentityRepo.FindByProviderSubject(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		return s.userRepo.FindById(ctx, linked.UserID)
+	}
+	if !errors.Is(err, domainErrors.ErrUserIdentityNotFound) {
+		return nil, err
+	}
+
+	// 只信任身份提供方验证过的邮箱，避免通过未验证邮箱接管已有账号
+	email := identity.VerifiedEmail()
+
+	var user *entity.User
+	if email != "" {
+		user, err = s.userRepo.FindByEmail(ctx, email)
+		if err != nil && !errors.Is(err, domainErrors.ErrUserNotFound) {
+			return nil, err
+		}
+	}
+
+	if user == nil {
+		user, err = s.userDomainService.NewOAuthUser(ctx, identity.Provider, identity.PreferredUsername, email)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+
+		user.Register()
+		*created = true
+	} else {
+		s.logger.Info("Linking oauth identity to existing user by verified email",
+			zap.String("provider", identity.Provider),
+			zap.Uint64("userId", user.ID))
+	}
+
+	userIdentity, err := entity.NewUserIdentity(user.ID, identity.Provider, identity.Subject, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userIdentityRepo.Create(ctx, userIdentity); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// issueTokens 加载用户角色并创建会话，需要在数据库连接或事务中调用
+func (s *AuthCommandService) issueTokens(ctx context.Context, userID uint64, alternativeID string) (*auth.TokenPair, error) {
+	roles, err := s.roleRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sessionManager.Issue(ctx, userID, alternativeID, entity.RoleNames(roles))
+}
+
+// loadRoleNames 刷新令牌时重新加载用户角色
+func (s *AuthCommandService) loadRoleNames(ctx context.Context, userID uint64) ([]string, error) {
+	var names []string
+	err := s.dbContext.Conn(database.WithPrimary(ctx), func(ctx context.Context) error {
+		roles, err := s.roleRepo.FindByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		names = entity.RoleNames(roles)
+		return nil
+	})
+	return names, err
+}
+
+// errInvalidCredentials 用户不存在和密码错误返回同一错误，避免泄露用户名是否存在
+var errInvalidCredentials = errors.New("invalid username or password")
+
+// recordLoginFailure 记录登录失败并按失败次数延迟响应，本次失败触发锁定时返回锁定错误
+func (s *AuthCommandService) recordLoginFailure(ctx context.Context, username, clientIP string, cause error) error {
+	delay, err := s.loginGuard.RecordFailure(ctx, username, clientIP)
+	if err != nil && !errors.Is(err, domainErrors.ErrLoginLocked) {
+		s.logger.Error("Failed to record login failure", zap.Error(err), zap.String("username", username))
+		return cause
+	}
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+	return cause
+}
+
+// recordLoginSuccess 登录成功后清除失败计数，失败不影响登录结果
+func (s *AuthCommandService) recordLoginSuccess(ctx context.Context, username string) {
+	if err := s.loginGuard.RecordSuccess(ctx, username); err != nil {
+		s.logger.Warn("Failed to reset login failures", zap.Error(err), zap.String("username", username))
+	}
+}
+
+// UnlockLoginCmd 管理员解除登录锁定，Username 和 IP 至少提供一个
+type UnlockLoginCmd struct {
+	OperatorID uint64
+	Username   string
+	IP         string
+}
+
+// UnlockLogin 解除用户名和IP的登录锁定并清除失败计数
+func (s *AuthCommandService) UnlockLogin(ctx context.Context, cmd *UnlockLoginCmd) error {
+	if cmd.Username == "" && cmd.IP == "" {
+		return errors.New("username or ip is required")
+	}
+
+	if err := s.loginGuard.Unlock(ctx, cmd.Username, cmd.IP); err != nil {
+		s.logger.Error("UnlockLogin failed", zap.Error(err), zap.String("username", cmd.Username), zap.String("ip", cmd.IP))
+		return err
+	}
+
+	s.logger.Warn("Login unlocked",
+		zap.String("audit", "login_unlock"),
+		zap.Uint64("operatorId", cmd.OperatorID),
+		zap.String("username", cmd.Username),
+		zap.String("ip", cmd.IP))
+
+	return nil
 }
@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lyonnee/go-template/internal/domain/entity"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/domain/repository"
+	"github.com/lyonnee/go-template/internal/infrastructure/auth"
+	"github.com/lyonnee/go-template/internal/infrastructure/database"
+	"github.com/lyonnee/go-template/internal/infrastructure/eventbus"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// MfaCommandService 多因素认证的启用、关闭和恢复码管理
+type MfaCommandService struct {
+	logger    *log.Logger
+	dbContext *database.Database
+
+	userRepo         repository.UserRepository
+	recoveryCodeRepo repository.RecoveryCodeRepository
+
+	mfaManager *auth.MfaManager
+
+	eventBus *eventbus.EventBus
+}
+
+func init() {
+	di.AddSingleton[*MfaCommandService](NewMfaCommandService)
+}
+
+// NewMfaCommandService 创建多因素认证服务
+func NewMfaCommandService() (*MfaCommandService, error) {
+	return &MfaCommandService{
+		logger:    di.Get[*log.Logger](),
+		dbContext: di.Get[*database.Database](),
+
+		userRepo:         di.Get[repository.UserRepository](),
+		recoveryCodeRepo: di.Get[repository.RecoveryCodeRepository](),
+
+		mfaManager: di.Get[*auth.MfaManager](),
+
+		eventBus: di.Get[*eventbus.EventBus](),
+	}, nil
+}
+
+// EnrollTOTPResult TOTP 登记结果，密钥只返回这一次
+type EnrollTOTPResult struct {
+	Secret     string
+	OtpauthURI string
+}
+
+// EnrollTOTP 生成待确认的 TOTP 密钥，用户将密钥添加到认证器应用后调用 ConfirmTOTP 启用
+func (s *MfaCommandService) EnrollTOTP(ctx context.Context, userID uint64) (*EnrollTOTPResult, error) {
+	s.logger.Debug("EnrollTOTP called", zap.Uint64("userId", userID))
+
+	var result EnrollTOTPResult
+	if err := s.dbContext.Conn(database.WithPrimary(ctx), func(ctx context.Context) error {
+		user, err := s.userRepo.FindById(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		account := user.Email
+		if account == "" {
+			account = user.Username
+		}
+
+		secret, uri, encrypted, err := s.mfaManager.NewTOTPSecret(user.ID, account)
+		if err != nil {
+			return err
+		}
+
+		if err := user.EnrollMfa(encrypted); err != nil {
+			return err
+		}
+
+		if err := s.userRepo.UpdateMfa(ctx, user); err != nil {
+			return err
+		}
+
+		result.Secret, result.OtpauthURI = secret, uri
+		return nil
+	}); err != nil {
+		s.logger.Error("EnrollTOTP failed", zap.Error(err), zap.Uint64("userId", userID))
+		return nil, err
+	}
+
+	s.logger.Info("TOTP enrollment started", zap.Uint64("userId", userID))
+
+	return &result, nil
+}
+
+// MfaCodeCmd 需要验证码的多因素认证操作
+type MfaCodeCmd struct {
+	UserID uint64
+	Code   string
+}
+
+// ConfirmTOTP 使用认证器应用生成的验证码确认登记，启用多因素认证并返回恢复码
+func (s *MfaCommandService) ConfirmTOTP(ctx context.Context, cmd *MfaCodeCmd) ([]string, error) {
+	s.logger.Debug("ConfirmTOTP called", zap.Uint64("userId", cmd.UserID))
+
+	var recoveryCodes []string
+	if err := s.dbContext.Transaction(ctx, nil, func(ctx context.Context) error {
+		user, err := s.userRepo.FindById(ctx, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		if user.MfaEnabled {
+			return domainErrors.ErrMfaAlreadyEnabled
+		}
+		if user.MfaSecret == "" {
+			return domainErrors.ErrMfaNotEnrolled
+		}
+
+		if err := s.mfaManager.VerifyTOTP(ctx, user.ID, user.MfaSecret, cmd.Code); err != nil {
+			return err
+		}
+
+		if err := user.EnableMfa(); err != nil {
+			return err
+		}
+
+		if err := s.userRepo.UpdateMfa(ctx, user); err != nil {
+			return err
+		}
+
+		recoveryCodes, err = s.replaceRecoveryCodes(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		return s.eventBus.Collect(ctx, user.PullEvents()...)
+	}); err != nil {
+		s.logger.Error("ConfirmTOTP failed", zap.Error(err), zap.Uint64("userId", cmd.UserID))
+		return nil, err
+	}
+
+	s.logger.Info("MFA enabled successfully", zap.Uint64("userId", cmd.UserID))
+
+	return recoveryCodes, nil
+}
+
+// DisableMfa 使用 TOTP 验证码或恢复码关闭多因素认证，同时删除恢复码
+func (s *MfaCommandService) DisableMfa(ctx context.Context, cmd *MfaCodeCmd) error {
+	s.logger.Debug("DisableMfa called", zap.Uint64("userId", cmd.UserID))
+
+	if err := s.dbContext.Transaction(ctx, nil, func(ctx context.Context) error {
+		user, err := s.userRepo.FindById(ctx, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.verifyWithLockout(ctx, user.ID, func() error {
+			_, err := verifySecondFactor(ctx, s.mfaManager, s.recoveryCodeRepo, user, cmd.Code)
+			return err
+		}); err != nil {
+			return err
+		}
+
+		if err := user.DisableMfa(); err != nil {
+			return err
+		}
+
+		if err := s.userRepo.UpdateMfa(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.recoveryCodeRepo.DeleteByUserID(ctx, user.ID); err != nil {
+			return err
+		}
+
+		return s.eventBus.Collect(ctx, user.PullEvents()...)
+	}); err != nil {
+		s.logger.Error("DisableMfa failed", zap.Error(err), zap.Uint64("userId", cmd.UserID))
+		return err
+	}
+
+	s.logger.Info("MFA disabled successfully", zap.Uint64("userId", cmd.UserID))
+
+	return nil
+}
+
+// RegenerateRecoveryCodes 使用 TOTP 验证码重新生成恢复码，原有恢复码全部失效
+func (s *MfaCommandService) RegenerateRecoveryCodes(ctx context.Context, cmd *MfaCodeCmd) ([]string, error) {
+	s.logger.Debug("RegenerateRecoveryCodes called", zap.Uint64("userId", cmd.UserID))
+
+	var recoveryCodes []string
+	if err := s.dbContext.Transaction(ctx, nil, func(ctx context.Context) error {
+		user, err := s.userRepo.FindById(ctx, cmd.UserID)
+		if err != nil {
+			return err
+		}
+
+		if !user.MfaEnabled {
+			return domainErrors.ErrMfaNotEnabled
+		}
+
+		if err := s.verifyWithLockout(ctx, user.ID, func() error {
+			return s.mfaManager.VerifyTOTP(ctx, user.ID, user.MfaSecret, cmd.Code)
+		}); err != nil {
+			return err
+		}
+
+		recoveryCodes, err = s.replaceRecoveryCodes(ctx, user.ID)
+		return err
+	}); err != nil {
+		s.logger.Error("RegenerateRecoveryCodes failed", zap.Error(err), zap.Uint64("userId", cmd.UserID))
+		return nil, err
+	}
+
+	s.logger.Info("Recovery codes regenerated", zap.Uint64("userId", cmd.UserID))
+
+	return recoveryCodes, nil
+}
+
+// verifyWithLockout 校验账户操作的验证码，错误计入用户的失败次数，达到上限后在锁定时长内拒绝校验
+// 防止持有访问令牌的攻击者暴力猜测验证码关闭多因素认证
+func (s *MfaCommandService) verifyWithLockout(ctx context.Context, userID uint64, verify func() error) error {
+	if err := s.mfaManager.CheckCodeLock(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := verify(); err != nil {
+		if errors.Is(err, domainErrors.ErrInvalidMfaCode) {
+			return s.mfaManager.FailCode(ctx, userID)
+		}
+		return err
+	}
+
+	return s.mfaManager.ResetCodeFailures(ctx, userID)
+}
+
+func (s *MfaCommandService) replaceRecoveryCodes(ctx context.Context, userID uint64) ([]string, error) {
+	codes, hashes, err := s.mfaManager.NewRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recoveryCodeRepo.Replace(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// verifySecondFactor 校验 TOTP 验证码或恢复码，恢复码校验通过后即被消耗，返回使用的因素
+func verifySecondFactor(ctx context.Context, mfaManager *auth.MfaManager, recoveryCodeRepo repository.RecoveryCodeRepository, user *entity.User, code string) (string, error) {
+	if !user.MfaEnabled {
+		return "", domainErrors.ErrMfaNotEnabled
+	}
+
+	if auth.IsTOTPCode(code) {
+		if err := mfaManager.VerifyTOTP(ctx, user.ID, user.MfaSecret, code); err != nil {
+			return "", err
+		}
+		return auth.MfaFactorTOTP, nil
+	}
+
+	if err := recoveryCodeRepo.Consume(ctx, user.ID, auth.HashRecoveryCode(code)); err != nil {
+		return "", err
+	}
+	return auth.MfaFactorRecoveryCode, nil
+}
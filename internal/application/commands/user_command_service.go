@@ -2,12 +2,18 @@ package commands
 
 import (
 	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/lyonnee/go-template/internal/domain/entity"
 	"github.com/lyonnee/go-template/internal/domain/repository"
 	"github.com/lyonnee/go-template/internal/domain/service"
 	"github.com/lyonnee/go-template/internal/infrastructure/auth"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
 	"github.com/lyonnee/go-template/internal/infrastructure/database"
+	"github.com/lyonnee/go-template/internal/infrastructure/eventbus"
 	"github.com/lyonnee/go-template/pkg/di"
 	"github.com/lyonnee/go-template/pkg/log"
 	"go.uber.org/zap"
@@ -20,8 +26,22 @@ type UserCommandService struct {
 	userRepo repository.UserRepository
 
 	userDomainService *service.UserService
+
+	sessionManager *auth.SessionManager
+	siweVerifier   *auth.SiweVerifier
+
+	locker cache.Locker
+
+	eventBus *eventbus.EventBus
 }
 
+const (
+	// usernameLockTTL 修改用户名时持有用户锁和用户名锁的最长时间
+	usernameLockTTL = 10 * time.Second
+	// usernameLockWait 等待其他请求释放用户锁或用户名锁的最长时间
+	usernameLockWait = 3 * time.Second
+)
+
 func init() {
 	di.AddSingleton[*UserCommandService](NewUserCommandService)
 }
@@ -35,6 +55,13 @@ func NewUserCommandService() (*UserCommandService, error) {
 		userRepo: di.Get[repository.UserRepository](),
 
 		userDomainService: di.Get[*service.UserService](),
+
+		sessionManager: di.Get[*auth.SessionManager](),
+		siweVerifier:   di.Get[*auth.SiweVerifier](),
+
+		locker: di.Get[cache.Locker](),
+
+		eventBus: di.Get[*eventbus.EventBus](),
 	}, nil
 }
 
@@ -61,7 +88,8 @@ func (s *UserCommandService) SignUp(ctx context.Context, cmd *SignUpCmd) (*SignU
 
 	var user *entity.User
 	var accessToken, refreshToken string
-	if err := s.dbContext.Conn(ctx, func(ctx context.Context) error {
+	// 签发令牌失败时回滚用户，注册事件随事务写入 outbox 或在提交后分发，与用户同时生效
+	if err := s.dbContext.Transaction(ctx, nil, func(ctx context.Context) error {
 		newUser, err := s.userDomainService.NewUser(ctx, cmd.Username, cmd.Password, cmd.Email, cmd.Phone)
 		if err != nil {
 			return err
@@ -71,19 +99,18 @@ func (s *UserCommandService) SignUp(ctx context.Context, cmd *SignUpCmd) (*SignU
 			return err
 		}
 
-		jwtManager := di.Get[*auth.JWTGenerator]()
-		// 生成token
-		accessToken, err = jwtManager.GenerateAccessToken(newUser.ID, newUser.Username)
-		if err != nil {
-			s.logger.Error("Failed to generate access token for new user", zap.Error(err), zap.Uint64("userId", user.ID))
+		newUser.Register()
+		if err := s.eventBus.Collect(ctx, newUser.PullEvents()...); err != nil {
 			return err
 		}
 
-		refreshToken, err = jwtManager.GenerateRefreshToken(newUser.ID, newUser.Username)
+		// 生成token，新注册用户没有任何角色
+		tokens, err := s.sessionManager.Issue(ctx, newUser.ID, newUser.Username, nil)
 		if err != nil {
-			s.logger.Error("Failed to generate refresh token for new user", zap.Error(err), zap.Uint64("userId", user.ID))
+			s.logger.Error("Failed to issue tokens for new user", zap.Error(err), zap.Uint64("userId", newUser.ID))
 			return err
 		}
+		accessToken, refreshToken = tokens.AccessToken, tokens.RefreshToken
 
 		user = newUser
 
@@ -119,27 +146,81 @@ func (s *UserCommandService) UpdateUsername(ctx context.Context, cmd *UpdateUser
 		zap.Uint64("userId", cmd.UserID),
 		zap.String("newUsername", cmd.Username))
 
+	var user *entity.User
+	// 同一用户的修改、同一用户名的修改都串行执行：前者避免同一用户的并发修改互相覆盖并各自发布事件，
+	// 后者避免并发请求同时通过唯一性检查。总是先锁用户再锁用户名，加锁顺序一致不会死锁；
+	// 事务使用可串行化隔离级别，锁过期等情况下的并发冲突回滚后重试，每次重试重新读取用户
+	userLock := "user:" + strconv.FormatUint(cmd.UserID, 10)
+	usernameLock := "username:" + strings.ToLower(cmd.Username)
+	if err := cache.WithLock(ctx, s.locker, userLock, usernameLockTTL, usernameLockWait, func(ctx context.Context) error {
+		return cache.WithLock(ctx, s.locker, usernameLock, usernameLockTTL, usernameLockWait, func(ctx context.Context) error {
+			return s.dbContext.TransactionWithRetry(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}, func(ctx context.Context) error {
+				// 检查用户是否存在
+				var err error
+				user, err = s.userRepo.FindById(ctx, cmd.UserID)
+				if err != nil {
+					return err
+				}
+
+				if err := s.userDomainService.UpdateUsername(ctx, user, cmd.Username); err != nil {
+					return err
+				}
+
+				if err := s.userRepo.UpdateUsername(ctx, user); err != nil {
+					return err
+				}
+
+				return s.eventBus.Collect(ctx, user.PullEvents()...)
+			})
+		})
+	}); err != nil {
+		s.logger.Error("Transaction failed during username update", zap.Error(err), zap.Uint64("userId", cmd.UserID))
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// BindWalletCmd 绑定钱包命令
+type BindWalletCmd struct {
+	UserID    uint64
+	Message   string
+	Signature string
+}
+
+// BindWallet 校验 EIP-4361 消息和签名后，将钱包地址绑定到已登录用户
+func (s *UserCommandService) BindWallet(ctx context.Context, cmd *BindWalletCmd) (*entity.User, error) {
+	s.logger.Debug("BindWallet called", zap.Uint64("userId", cmd.UserID))
+
+	msg, err := s.siweVerifier.Verify(ctx, cmd.Message, cmd.Signature)
+	if err != nil {
+		s.logger.Warn("Siwe verification failed", zap.Error(err), zap.Uint64("userId", cmd.UserID))
+		return nil, err
+	}
+
 	var user *entity.User
 	if err := s.dbContext.Transaction(ctx, nil, func(ctx context.Context) error {
-		// 检查用户是否存在
-		user, err := s.userRepo.FindById(ctx, cmd.UserID)
+		var err error
+		user, err = s.userRepo.FindById(ctx, cmd.UserID)
 		if err != nil {
 			return err
 		}
 
-		if err := s.userDomainService.UpdateUsername(ctx, user, cmd.Username); err != nil {
+		if err := user.BindWallet(msg.Address); err != nil {
 			return err
 		}
 
-		if err := s.userRepo.UpdateUsername(ctx, user); err != nil {
+		if err := s.userRepo.UpdateWalletAddress(ctx, user); err != nil {
 			return err
 		}
 
-		return nil
+		return s.eventBus.Collect(ctx, user.PullEvents()...)
 	}); err != nil {
-		s.logger.Error("Transaction failed during username update", zap.Error(err), zap.Uint64("userId", cmd.UserID))
+		s.logger.Error("Transaction failed during wallet binding", zap.Error(err), zap.Uint64("userId", cmd.UserID))
 		return nil, err
 	}
 
+	s.logger.Info("Wallet bound successfully", zap.Uint64("userId", cmd.UserID), zap.String("address", msg.Address))
+
 	return user, nil
 }
@@ -0,0 +1,69 @@
+package queries
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/lyonnee/go-template/internal/domain/repository"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+)
+
+// EthQueryService 链上数据查询服务
+type EthQueryService struct {
+	logger *log.Logger
+
+	ethRepo repository.EthRepository
+}
+
+func init() {
+	di.AddSingleton[*EthQueryService](NewEthQueryService)
+}
+
+// NewEthQueryService 创建链上数据查询服务
+func NewEthQueryService() (*EthQueryService, error) {
+	return &EthQueryService{
+		logger: di.Get[*log.Logger](),
+
+		ethRepo: di.Get[repository.EthRepository](),
+	}, nil
+}
+
+// BalanceResult 余额查询结果
+type BalanceResult struct {
+	Address     string
+	Token       string
+	Balance     string
+	BlockNumber uint64
+}
+
+// GetBalance 查询地址余额，token 为空时查询原生代币余额，否则查询 ERC-20 代币余额
+func (s *EthQueryService) GetBalance(ctx context.Context, address, token string) (*BalanceResult, error) {
+	s.logger.Debug("GetBalance called", zap.String("address", address), zap.String("token", token))
+
+	var (
+		balance string
+		err     error
+	)
+	if token == "" {
+		balance, err = s.ethRepo.ETHBalance(ctx, address)
+	} else {
+		balance, err = s.ethRepo.ERC20BalanceOf(ctx, token, address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	blockNumber, err := s.ethRepo.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BalanceResult{
+		Address:     address,
+		Token:       token,
+		Balance:     balance,
+		BlockNumber: blockNumber,
+	}, nil
+}
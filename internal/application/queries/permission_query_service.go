@@ -0,0 +1,118 @@
+package queries
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/lyonnee/go-template/internal/domain/entity"
+	"github.com/lyonnee/go-template/internal/domain/repository"
+	"github.com/lyonnee/go-template/internal/infrastructure/database"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+)
+
+// rolePermissionsTTL 角色权限的本地缓存时长，角色权限变更最多延迟该时长生效
+const rolePermissionsTTL = time.Minute
+
+type cachedRole struct {
+	role      *entity.Role // 角色不存在时为 nil
+	expiresAt time.Time
+}
+
+// PermissionQueryService 权限查询服务，根据令牌中的角色判断是否拥有权限
+type PermissionQueryService struct {
+	logger    *log.Logger
+	dbContext *database.Database
+
+	roleRepo repository.RoleRepository
+
+	mu    sync.RWMutex
+	roles map[string]cachedRole
+}
+
+func init() {
+	di.AddSingleton[*PermissionQueryService](NewPermissionQueryService)
+}
+
+// NewPermissionQueryService 创建权限查询服务
+func NewPermissionQueryService() (*PermissionQueryService, error) {
+	return &PermissionQueryService{
+		logger:    di.Get[*log.Logger](),
+		dbContext: di.Get[*database.Database](),
+
+		roleRepo: di.Get[repository.RoleRepository](),
+
+		roles: make(map[string]cachedRole),
+	}, nil
+}
+
+// HasPermission 判断角色集合中是否有角色拥有指定权限
+func (s *PermissionQueryService) HasPermission(ctx context.Context, roleNames []string, permission string) (bool, error) {
+	if len(roleNames) == 0 {
+		return false, nil
+	}
+
+	roles, err := s.getRoles(ctx, roleNames)
+	if err != nil {
+		return false, err
+	}
+
+	for _, role := range roles {
+		if role.HasPermission(permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// getRoles 优先从本地缓存获取角色，未命中的角色从数据库加载
+func (s *PermissionQueryService) getRoles(ctx context.Context, roleNames []string) ([]*entity.Role, error) {
+	now := time.Now()
+	roles := make([]*entity.Role, 0, len(roleNames))
+	var missing []string
+
+	s.mu.RLock()
+	for _, name := range roleNames {
+		cached, ok := s.roles[name]
+		if !ok || now.After(cached.expiresAt) {
+			missing = append(missing, name)
+			continue
+		}
+		if cached.role != nil {
+			roles = append(roles, cached.role)
+		}
+	}
+	s.mu.RUnlock()
+
+	if len(missing) == 0 {
+		return roles, nil
+	}
+
+	var loaded []*entity.Role
+	if err := s.dbContext.Conn(ctx, func(ctx context.Context) error {
+		var err error
+		loaded, err = s.roleRepo.FindByNames(ctx, missing)
+		return err
+	}); err != nil {
+		s.logger.Error("Failed to load roles", zap.Error(err), zap.Strings("roles", missing))
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt := now.Add(rolePermissionsTTL)
+	// 不存在的角色也缓存，避免令牌中残留的已删除角色每次都查询数据库
+	for _, name := range missing {
+		s.roles[name] = cachedRole{expiresAt: expiresAt}
+	}
+	for _, role := range loaded {
+		s.roles[role.Name] = cachedRole{role: role, expiresAt: expiresAt}
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
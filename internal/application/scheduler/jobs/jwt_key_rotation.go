@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"github.com/lyonnee/go-template/internal/infrastructure/auth"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// RotateJWTKeys 签名密钥超过轮换间隔时生成新密钥，并清理已过保留期的旧密钥
+func RotateJWTKeys() {
+	keyring := di.Get[*auth.Keyring]()
+
+	rotated, err := keyring.RotateIfDue()
+	if err != nil {
+		log.Error("JWT key rotation failed", zap.Error(err))
+		return
+	}
+	if rotated {
+		log.Info("JWT signing key rotated", zap.String("algorithm", keyring.Algorithm()))
+	}
+}
+
+// ReloadJWTKeys 重新加载密钥目录，切换到其他副本轮换出的签名密钥，并丢弃已被清理的旧密钥
+// 轮换只在获取到锁的副本上执行，其余副本依赖该任务更新签名密钥，需要在每个副本上执行
+func ReloadJWTKeys() {
+	keyring := di.Get[*auth.Keyring]()
+	if keyring.IsSymmetric() {
+		return
+	}
+
+	if err := keyring.Reload(); err != nil {
+		log.Error("JWT key reload failed", zap.Error(err))
+	}
+}
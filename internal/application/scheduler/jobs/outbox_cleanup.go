@@ -0,0 +1,22 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/outbox"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// CleanupOutbox 删除投递成功超过保留时长的发件箱消息
+func CleanupOutbox() {
+	deleted, err := di.Get[*outbox.Relay]().Cleanup(context.Background())
+	if err != nil {
+		log.Error("Outbox cleanup failed", zap.Error(err), zap.Int64("deleted", deleted))
+		return
+	}
+	if deleted > 0 {
+		log.Info("Outbox cleaned up", zap.Int64("deleted", deleted))
+	}
+}
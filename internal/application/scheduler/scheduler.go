@@ -1,19 +1,50 @@
 package scheduler
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"github.com/lyonnee/go-template/internal/application/scheduler/jobs"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/pkg/di"
 	"github.com/lyonnee/go-template/pkg/log"
 	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
 )
 
 func RegisterScheduledJobs(s *cron.Cron) {
-	s.AddFunc("0 * * * *", func() {
+	s.AddFunc("0 * * * *", singleReplica("hourly", 30*time.Minute, func() {
 		jobs.TestJob()
 		// Example task: Log every hour
 		log.Info("Hourly task executed")
-	})
-	s.AddFunc("0 0 * * *", func() {
+	}))
+	s.AddFunc("0 0 * * *", singleReplica("daily", time.Hour, func() {
 		// Example task: Log every day at midnight
 		log.Info("Daily task executed")
-	})
+	}))
+	s.AddFunc("@every 1h", singleReplica("rotate_jwt_keys", 30*time.Minute, jobs.RotateJWTKeys))
+	// 轮换出的新密钥在下一次重新加载前只在执行轮换的副本上用于签名
+	s.AddFunc("@every 5m", jobs.ReloadJWTKeys)
+
+	if conf := di.Get[config.Config]().Event; conf.PublishToMQ && conf.Outbox.Enable {
+		s.AddFunc("@every 1h", singleReplica("cleanup_outbox", 30*time.Minute, jobs.CleanupOutbox))
+	}
+}
+
+// singleReplica 多副本部署时同一次调度只在获取到锁的副本上执行
+// 任务结束后不释放锁，由锁在 ttl 后过期，避免副本间的时钟偏差导致同一次调度被执行多次，ttl 应小于调度间隔
+func singleReplica(name string, ttl time.Duration, job func()) func() {
+	return func() {
+		_, err := di.Get[cache.Locker]().Acquire(context.Background(), "job:"+name, ttl)
+		if err != nil {
+			if !errors.Is(err, cache.ErrLockNotAcquired) {
+				log.Error("Failed to acquire scheduled job lock", zap.Error(err), zap.String("job", name))
+			}
+			return
+		}
+
+		job()
+	}
 }
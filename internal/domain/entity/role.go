@@ -0,0 +1,48 @@
+package entity
+
+import "strings"
+
+const (
+	// RoleAdmin 管理员角色，可以查看和管理任意用户
+	RoleAdmin = "admin"
+
+	// PermissionAll 通配权限，拥有全部权限
+	PermissionAll = "*"
+
+	PermissionUsersRead  = "users:read"
+	PermissionUsersWrite = "users:write"
+	// PermissionUsersUnlock 解除因登录失败次数过多产生的锁定
+	PermissionUsersUnlock = "users:unlock"
+)
+
+// Role 角色，权限格式为 "资源:操作"，支持 "*" 和 "资源:*" 通配
+type Role struct {
+	ID        uint64
+	CreatedAt int64
+	UpdatedAt int64
+
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// HasPermission 角色是否拥有指定权限
+func (r *Role) HasPermission(permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+
+	for _, p := range r.Permissions {
+		if p == PermissionAll || p == permission || p == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleNames 返回角色名称列表
+func RoleNames(roles []*Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
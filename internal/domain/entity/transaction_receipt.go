@@ -0,0 +1,19 @@
+package entity
+
+// TransactionReceipt 交易回执
+type TransactionReceipt struct {
+	TxHash            string
+	BlockHash         string
+	BlockNumber       uint64
+	From              string
+	To                string
+	ContractAddress   string
+	GasUsed           uint64
+	EffectiveGasPrice string
+	Status            uint64 // 1 成功，0 失败
+}
+
+// Succeeded 交易是否执行成功
+func (r *TransactionReceipt) Succeeded() bool {
+	return r.Status == 1
+}
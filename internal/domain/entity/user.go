@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/domain/event"
 	"github.com/lyonnee/go-template/pkg/util"
 )
 
@@ -20,6 +21,13 @@ type User struct {
 	Email       string
 	Phone       string
 	LastLoginAt int64
+
+	WalletAddress string // EIP-55 校验和格式的钱包地址，未绑定时为空
+
+	MfaSecret  string // 加密后的 TOTP 密钥，启用确认前为待确认的密钥
+	MfaEnabled bool   // 是否已启用多因素认证
+
+	event.Recorder
 }
 
 func NewUser(username, pwd, email, phone string) (*User, error) {
@@ -58,6 +66,54 @@ func NewUser(username, pwd, email, phone string) (*User, error) {
 	return u, nil
 }
 
+// NewWalletUser 通过钱包地址创建用户，用户名默认为小写的钱包地址，未设置密码
+func NewWalletUser(walletAddress string) (*User, error) {
+	if walletAddress == "" {
+		return nil, errors.ErrInvalidAddress
+	}
+
+	now := time.Now().Unix()
+
+	u := &User{
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		LastLoginAt: 0,
+		DeletedAt:   0,
+
+		Username:      strings.ToLower(walletAddress),
+		WalletAddress: walletAddress,
+	}
+
+	return u, nil
+}
+
+// NewOAuthUser 通过第三方身份创建用户，未设置密码，email 为空表示身份提供方未返回已验证的邮箱
+func NewOAuthUser(username, email string) (*User, error) {
+	if err := validateUsername(username); err != nil {
+		return nil, err
+	}
+	if email != "" {
+		if err := validateEmail(email); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().Unix()
+
+	u := &User{
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		LastLoginAt: 0,
+		DeletedAt:   0,
+
+		Username: username,
+		Email:    email,
+	}
+
+	return u, nil
+}
+
+// Login 密码登录，已启用多因素认证时密码校验通过后返回 errors.ErrMfaRequired，需调用 CompleteMfaLogin 完成登录
 func (u *User) Login(pwd string) error {
 	if u.DeletedAt > 0 {
 		return errors.ErrUserDeleted
@@ -68,9 +124,200 @@ func (u *User) Login(pwd string) error {
 		return errors.ErrInvalidPassword
 	}
 
+	if u.MfaEnabled {
+		return errors.ErrMfaRequired
+	}
+
+	u.markLoggedIn("password")
+	return nil
+}
+
+// CompleteMfaLogin 第二因素校验通过后完成登录，method 为第一因素的登录方式，factor 为 totp 或 recovery_code
+func (u *User) CompleteMfaLogin(method, factor string) error {
+	if u.DeletedAt > 0 {
+		return errors.ErrUserDeleted
+	}
+	if !u.MfaEnabled {
+		return errors.ErrMfaNotEnabled
+	}
+
+	u.markLoggedIn(method + "+" + factor)
+	return nil
+}
+
+func (u *User) markLoggedIn(method string) {
 	// update last login time
 	u.LastLoginAt = time.Now().Unix()
 
+	u.Record(&event.UserLoggedIn{
+		UserID:     u.ID,
+		Username:   u.Username,
+		Method:     method,
+		OccurredAt: u.LastLoginAt,
+	})
+}
+
+// EnrollMfa 保存待确认的加密 TOTP 密钥，重复调用会覆盖之前未确认的密钥
+func (u *User) EnrollMfa(encryptedSecret string) error {
+	if u.DeletedAt > 0 {
+		return errors.ErrUserDeleted
+	}
+	if u.MfaEnabled {
+		return errors.ErrMfaAlreadyEnabled
+	}
+
+	u.MfaSecret = encryptedSecret
+	u.UpdatedAt = time.Now().Unix()
+	return nil
+}
+
+// EnableMfa 用户使用待确认的密钥生成的验证码确认后启用多因素认证
+func (u *User) EnableMfa() error {
+	if u.MfaEnabled {
+		return errors.ErrMfaAlreadyEnabled
+	}
+	if u.MfaSecret == "" {
+		return errors.ErrMfaNotEnrolled
+	}
+
+	u.MfaEnabled = true
+	u.UpdatedAt = time.Now().Unix()
+
+	u.Record(&event.MfaEnabled{
+		UserID:     u.ID,
+		OccurredAt: u.UpdatedAt,
+	})
+
+	return nil
+}
+
+// DisableMfa 关闭多因素认证并清除密钥
+func (u *User) DisableMfa() error {
+	if !u.MfaEnabled {
+		return errors.ErrMfaNotEnabled
+	}
+
+	u.MfaEnabled = false
+	u.MfaSecret = ""
+	u.UpdatedAt = time.Now().Unix()
+
+	u.Record(&event.MfaDisabled{
+		UserID:     u.ID,
+		OccurredAt: u.UpdatedAt,
+	})
+
+	return nil
+}
+
+// LoginWithWallet 钱包签名校验通过后登录
+// 已启用多因素认证时返回 errors.ErrMfaRequired，需调用 CompleteMfaLogin 完成登录
+func (u *User) LoginWithWallet(walletAddress string) error {
+	if u.DeletedAt > 0 {
+		return errors.ErrUserDeleted
+	}
+
+	if u.WalletAddress == "" || !strings.EqualFold(u.WalletAddress, walletAddress) {
+		return errors.ErrInvalidSignature
+	}
+
+	if u.MfaEnabled {
+		return errors.ErrMfaRequired
+	}
+
+	u.markLoggedIn("wallet")
+	return nil
+}
+
+// LoginWithOAuth 第三方身份认证通过后登录
+// 已启用多因素认证时返回 errors.ErrMfaRequired，需调用 CompleteMfaLogin 完成登录
+func (u *User) LoginWithOAuth(provider string) error {
+	if u.DeletedAt > 0 {
+		return errors.ErrUserDeleted
+	}
+
+	if u.MfaEnabled {
+		return errors.ErrMfaRequired
+	}
+
+	u.markLoggedIn("oauth:" + provider)
+	return nil
+}
+
+// BindWallet 绑定钱包地址，已绑定其他地址时返回错误
+func (u *User) BindWallet(walletAddress string) error {
+	if u.DeletedAt > 0 {
+		return errors.ErrUserDeleted
+	}
+
+	if walletAddress == "" {
+		return errors.ErrInvalidAddress
+	}
+
+	if u.WalletAddress != "" {
+		if strings.EqualFold(u.WalletAddress, walletAddress) {
+			return nil
+		}
+		return errors.ErrWalletAlreadyBound
+	}
+
+	u.WalletAddress = walletAddress
+	u.UpdatedAt = time.Now().Unix()
+
+	u.Record(&event.WalletBound{
+		UserID:        u.ID,
+		WalletAddress: walletAddress,
+		OccurredAt:    u.UpdatedAt,
+	})
+
+	return nil
+}
+
+// Register 用户持久化并分配ID后调用，产生用户注册事件
+func (u *User) Register() {
+	u.Record(&event.UserRegistered{
+		UserID:     u.ID,
+		Username:   u.Username,
+		Email:      u.Email,
+		Phone:      u.Phone,
+		OccurredAt: u.CreatedAt,
+	})
+}
+
+func (u *User) ChangeUsername(username string) error {
+	if err := validateUsername(username); err != nil {
+		return err
+	}
+
+	if u.Username == username {
+		return nil
+	}
+
+	oldUsername := u.Username
+	u.Username = username
+	u.UpdatedAt = time.Now().Unix()
+
+	u.Record(&event.UsernameChanged{
+		UserID:      u.ID,
+		OldUsername: oldUsername,
+		NewUsername: username,
+		OccurredAt:  u.UpdatedAt,
+	})
+
+	return nil
+}
+
+func (u *User) Delete() error {
+	if u.DeletedAt > 0 {
+		return errors.ErrUserDeleted
+	}
+
+	u.DeletedAt = time.Now().Unix()
+
+	u.Record(&event.UserDeleted{
+		UserID:     u.ID,
+		OccurredAt: u.DeletedAt,
+	})
+
 	return nil
 }
 
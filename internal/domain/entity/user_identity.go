@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/domain/errors"
+)
+
+// UserIdentity 用户在第三方身份提供方的账号，一个用户可关联多个身份
+type UserIdentity struct {
+	ID        uint64
+	CreatedAt int64
+	UpdatedAt int64
+
+	UserID   uint64
+	Provider string // 身份提供方名称
+	Subject  string // 身份提供方中的用户唯一标识（ID Token 的 sub）
+	Email    string // 关联时身份提供方返回的邮箱
+}
+
+func NewUserIdentity(userID uint64, provider, subject, email string) (*UserIdentity, error) {
+	if userID == 0 || strings.TrimSpace(provider) == "" || strings.TrimSpace(subject) == "" {
+		return nil, errors.ErrInvalidUserInput
+	}
+
+	now := time.Now().Unix()
+
+	return &UserIdentity{
+		CreatedAt: now,
+		UpdatedAt: now,
+
+		UserID:   userID,
+		Provider: provider,
+		Subject:  subject,
+		Email:    email,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/domain/event"
+)
+
+const testWalletAddress = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+
+func TestUserLoginRequiresMfa(t *testing.T) {
+	logins := []struct {
+		name   string
+		login  func(u *User) error
+		method string
+	}{
+		{"wallet", func(u *User) error { return u.LoginWithWallet(testWalletAddress) }, "wallet"},
+		{"oauth", func(u *User) error { return u.LoginWithOAuth("google") }, "oauth:google"},
+	}
+
+	for _, tt := range logins {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &User{ID: 1, Username: "alice", WalletAddress: testWalletAddress}
+			if err := tt.login(u); err != nil {
+				t.Fatalf("login without mfa: %v", err)
+			}
+			assertLoggedIn(t, u, tt.method)
+
+			u.MfaEnabled = true
+			u.LastLoginAt = 0
+			if err := tt.login(u); err != errors.ErrMfaRequired {
+				t.Fatalf("login with mfa enabled = %v, want ErrMfaRequired", err)
+			}
+			if u.LastLoginAt != 0 || len(u.PullEvents()) != 0 {
+				t.Fatal("user logged in before completing mfa")
+			}
+
+			if err := u.CompleteMfaLogin(tt.method, "totp"); err != nil {
+				t.Fatalf("CompleteMfaLogin: %v", err)
+			}
+			assertLoggedIn(t, u, tt.method+"+totp")
+		})
+	}
+}
+
+func TestUserLoginWithWalletRejectsOtherAddress(t *testing.T) {
+	u := &User{ID: 1, WalletAddress: testWalletAddress, MfaEnabled: true}
+	if err := u.LoginWithWallet("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"); err != errors.ErrInvalidSignature {
+		t.Fatalf("LoginWithWallet = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func assertLoggedIn(t *testing.T, u *User, method string) {
+	t.Helper()
+
+	if u.LastLoginAt == 0 {
+		t.Fatal("LastLoginAt not updated")
+	}
+	events := u.PullEvents()
+	if len(events) != 1 {
+		t.Fatalf("recorded %d events, want 1", len(events))
+	}
+	loggedIn, ok := events[0].(*event.UserLoggedIn)
+	if !ok || loggedIn.Method != method {
+		t.Fatalf("event = %+v, want UserLoggedIn with method %s", events[0], method)
+	}
+}
@@ -0,0 +1,80 @@
+package errors
+
+var (
+	ErrInvalidSiweMessage = &DomainError{
+		Code:    3001,
+		Message: "invalid sign-in with ethereum message",
+	}
+	ErrInvalidSignature = &DomainError{
+		Code:    3002,
+		Message: "invalid signature",
+	}
+	ErrInvalidNonce = &DomainError{
+		Code:    3003,
+		Message: "nonce is invalid or expired",
+	}
+	ErrSiweMessageExpired = &DomainError{
+		Code:    3004,
+		Message: "sign-in with ethereum message expired",
+	}
+	ErrOAuthProviderNotFound = &DomainError{
+		Code:    3005,
+		Message: "oauth provider not found",
+	}
+	ErrInvalidOAuthState = &DomainError{
+		Code:    3006,
+		Message: "oauth state is invalid or expired",
+	}
+	ErrOAuthExchangeFailed = &DomainError{
+		Code:    3007,
+		Message: "oauth authorization code exchange failed",
+	}
+	ErrInvalidIDToken = &DomainError{
+		Code:    3008,
+		Message: "invalid id token",
+	}
+	ErrInvalidRefreshToken = &DomainError{
+		Code:    3009,
+		Message: "invalid refresh token",
+	}
+	ErrRefreshTokenReused = &DomainError{
+		Code:    3010,
+		Message: "refresh token reused, session revoked",
+	}
+	ErrSessionRevoked = &DomainError{
+		Code:    3011,
+		Message: "session revoked or expired",
+	}
+	ErrMfaRequired = &DomainError{
+		Code:    3012,
+		Message: "multi-factor authentication required",
+	}
+	ErrInvalidMfaCode = &DomainError{
+		Code:    3013,
+		Message: "invalid mfa code",
+	}
+	ErrInvalidMfaChallenge = &DomainError{
+		Code:    3014,
+		Message: "invalid or expired mfa challenge",
+	}
+	ErrMfaAlreadyEnabled = &DomainError{
+		Code:    3015,
+		Message: "mfa already enabled",
+	}
+	ErrMfaNotEnrolled = &DomainError{
+		Code:    3016,
+		Message: "mfa enrollment not started",
+	}
+	ErrMfaNotEnabled = &DomainError{
+		Code:    3017,
+		Message: "mfa not enabled",
+	}
+	ErrLoginLocked = &DomainError{
+		Code:    3018,
+		Message: "too many failed login attempts, try again later",
+	}
+	ErrMfaLocked = &DomainError{
+		Code:    3019,
+		Message: "too many invalid mfa codes, try again later",
+	}
+)
@@ -0,0 +1,16 @@
+package errors
+
+var (
+	ErrInvalidAddress = &DomainError{
+		Code:    2001,
+		Message: "invalid address",
+	}
+	ErrInvalidTxHash = &DomainError{
+		Code:    2002,
+		Message: "invalid transaction hash",
+	}
+	ErrReceiptNotFound = &DomainError{
+		Code:    2003,
+		Message: "transaction receipt not found",
+	}
+)
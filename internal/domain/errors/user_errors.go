@@ -82,4 +82,20 @@ var (
 		Code:    1018,
 		Message: "invalid password format",
 	}
+	ErrWalletAddressTaken = &DomainError{
+		Code:    1019,
+		Message: "wallet address already taken",
+	}
+	ErrWalletAlreadyBound = &DomainError{
+		Code:    1020,
+		Message: "wallet already bound",
+	}
+	ErrUserIdentityNotFound = &DomainError{
+		Code:    1021,
+		Message: "user identity not found",
+	}
+	ErrUserIdentityTaken = &DomainError{
+		Code:    1022,
+		Message: "user identity already linked",
+	}
 )
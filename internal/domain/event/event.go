@@ -0,0 +1,24 @@
+package event
+
+// Event 领域事件
+type Event interface {
+	// EventName 事件名称，用于订阅和消息队列 topic
+	EventName() string
+}
+
+// Recorder 记录聚合根产生的领域事件
+type Recorder struct {
+	events []Event
+}
+
+// Record 记录领域事件
+func (r *Recorder) Record(e Event) {
+	r.events = append(r.events, e)
+}
+
+// PullEvents 取出并清空已记录的领域事件
+func (r *Recorder) PullEvents() []Event {
+	events := r.events
+	r.events = nil
+	return events
+}
@@ -0,0 +1,89 @@
+package event
+
+const (
+	UserRegisteredEvent  = "user.registered"
+	UsernameChangedEvent = "user.username_changed"
+	UserLoggedInEvent    = "user.logged_in"
+	UserDeletedEvent     = "user.deleted"
+	WalletBoundEvent     = "user.wallet_bound"
+	MfaEnabledEvent      = "user.mfa_enabled"
+	MfaDisabledEvent     = "user.mfa_disabled"
+)
+
+// UserRegistered 用户注册成功
+type UserRegistered struct {
+	UserID     uint64 `json:"user_id"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	Phone      string `json:"phone"`
+	OccurredAt int64  `json:"occurred_at"`
+}
+
+func (e *UserRegistered) EventName() string {
+	return UserRegisteredEvent
+}
+
+// UsernameChanged 用户名已修改
+type UsernameChanged struct {
+	UserID      uint64 `json:"user_id"`
+	OldUsername string `json:"old_username"`
+	NewUsername string `json:"new_username"`
+	OccurredAt  int64  `json:"occurred_at"`
+}
+
+func (e *UsernameChanged) EventName() string {
+	return UsernameChangedEvent
+}
+
+// UserLoggedIn 用户登录成功
+type UserLoggedIn struct {
+	UserID     uint64 `json:"user_id"`
+	Username   string `json:"username"`
+	Method     string `json:"method"` // 登录方式：password / password+totp / password+recovery_code / wallet / oauth:<provider>
+	OccurredAt int64  `json:"occurred_at"`
+}
+
+func (e *UserLoggedIn) EventName() string {
+	return UserLoggedInEvent
+}
+
+// UserDeleted 用户已删除
+type UserDeleted struct {
+	UserID     uint64 `json:"user_id"`
+	OccurredAt int64  `json:"occurred_at"`
+}
+
+func (e *UserDeleted) EventName() string {
+	return UserDeletedEvent
+}
+
+// WalletBound 用户已绑定钱包地址
+type WalletBound struct {
+	UserID        uint64 `json:"user_id"`
+	WalletAddress string `json:"wallet_address"`
+	OccurredAt    int64  `json:"occurred_at"`
+}
+
+func (e *WalletBound) EventName() string {
+	return WalletBoundEvent
+}
+
+// MfaEnabled 用户已启用多因素认证
+type MfaEnabled struct {
+	UserID     uint64 `json:"user_id"`
+	OccurredAt int64  `json:"occurred_at"`
+}
+
+func (e *MfaEnabled) EventName() string {
+	return MfaEnabledEvent
+}
+
+// MfaDisabled 用户已关闭多因素认证
+type MfaDisabled struct {
+	UserID     uint64 `json:"user_id"`
+	OccurredAt int64  `json:"occurred_at"`
+}
+
+func (e *MfaDisabled) EventName() string {
+	return MfaDisabledEvent
+}
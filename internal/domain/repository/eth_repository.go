@@ -1,7 +1,21 @@
 package repository
 
-import "context"
+import (
+	"context"
 
+	"github.com/lyonnee/go-template/internal/domain/entity"
+)
+
+// EthRepository EVM 链数据存储库接口
+// 金额均为最小单位（wei）的十进制字符串
+// 存储库方法可能返回的错误：
+// - errors.ErrInvalidAddress：无效的地址
+// - errors.ErrInvalidTxHash：无效的交易哈希
+// - errors.ErrReceiptNotFound：交易回执不存在（未上链或待确认）
 type EthRepository interface {
 	ETHBalance(ctx context.Context, address string) (string, error)
+	Nonce(ctx context.Context, address string) (uint64, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	TransactionReceipt(ctx context.Context, txHash string) (*entity.TransactionReceipt, error)
+	ERC20BalanceOf(ctx context.Context, token, owner string) (string, error)
 }
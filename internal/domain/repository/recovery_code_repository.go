@@ -0,0 +1,15 @@
+package repository
+
+import "context"
+
+// RecoveryCodeRepository 多因素认证恢复码存储库接口，只保存恢复码的哈希
+// 存储库方法可能返回的错误：
+// - errors.ErrInvalidMfaCode：恢复码不存在或已使用
+type RecoveryCodeRepository interface {
+	// Replace 删除用户原有的恢复码并保存新的恢复码
+	Replace(ctx context.Context, userID uint64, codeHashes []string) error
+	// Consume 将恢复码标记为已使用
+	Consume(ctx context.Context, userID uint64, codeHash string) error
+	// DeleteByUserID 删除用户的全部恢复码
+	DeleteByUserID(ctx context.Context, userID uint64) error
+}
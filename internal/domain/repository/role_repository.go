@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/lyonnee/go-template/internal/domain/entity"
+)
+
+// RoleRepository 角色存储库接口，返回的角色包含其拥有的权限
+type RoleRepository interface {
+	// FindByUserID 查询用户拥有的角色
+	FindByUserID(ctx context.Context, userID uint64) ([]*entity.Role, error)
+	// FindByNames 根据角色名称批量查询，不存在的角色会被忽略
+	FindByNames(ctx context.Context, names []string) ([]*entity.Role, error)
+}
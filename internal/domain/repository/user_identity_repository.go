@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/lyonnee/go-template/internal/domain/entity"
+)
+
+// UserIdentityRepository 第三方身份存储库接口
+// 存储库方法可能返回的错误：
+// - errors.ErrUserIdentityNotFound：第三方身份不存在
+// - errors.ErrUserIdentityTaken：第三方身份已关联其他用户
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *entity.UserIdentity) error
+
+	FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error)
+}
@@ -12,6 +12,7 @@ import (
 // - errors.ErrUsernameTaken：用户名已被占用
 // - errors.ErrEmailTaken：邮箱已被占用
 // - errors.ErrPhoneTaken：手机号已被占用
+// - errors.ErrWalletAddressTaken：钱包地址已被绑定
 // - errors.ErrInvalidUserInput：无效的用户输入
 type UserRepository interface {
 	// 基本的CRUD操作
@@ -24,12 +25,15 @@ type UserRepository interface {
 	FindByUsername(ctx context.Context, username string) (*entity.User, error)
 	FindByEmail(ctx context.Context, email string) (*entity.User, error)
 	FindByPhone(ctx context.Context, phone string) (*entity.User, error)
+	FindByWalletAddress(ctx context.Context, walletAddress string) (*entity.User, error)
 
 	// 特定更新操作
 	UpdateUsername(ctx context.Context, user *entity.User) error
 	UpdatePwdSecret(ctx context.Context, user *entity.User) error
 	UpdateEmail(ctx context.Context, user *entity.User) error
 	UpdatePhone(ctx context.Context, user *entity.User) error
+	UpdateWalletAddress(ctx context.Context, user *entity.User) error
+	UpdateMfa(ctx context.Context, user *entity.User) error
 
 	CheckUserFieldsExist(ctx context.Context, username, email, phone string) (bool, error)
 }
@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"strings"
 
 	"github.com/lyonnee/go-template/internal/domain/entity"
 	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
@@ -49,6 +52,77 @@ func (s *UserService) NewUser(ctx context.Context, username, pwd, email, phone s
 	return user, nil
 }
 
+// NewWalletUser 通过钱包地址创建用户，钱包地址需已通过签名校验
+func (s *UserService) NewWalletUser(ctx context.Context, walletAddress string) (*entity.User, error) {
+	user, err := entity.NewWalletUser(walletAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// 默认用户名为钱包地址，检查是否已被其他用户使用
+	existingUser, err := s.userRepo.FindByUsername(ctx, user.Username)
+	if err != nil && !errors.Is(err, domainErrors.ErrUserNotFound) {
+		s.logger.Error("Failed to check username availability", zap.Error(err), zap.String("username", user.Username))
+		return nil, err
+	}
+	if existingUser != nil {
+		s.logger.Warn("Wallet username already taken", zap.String("username", user.Username), zap.Uint64("existingUserId", existingUser.ID))
+		return nil, domainErrors.ErrUsernameTaken
+	}
+
+	return user, nil
+}
+
+// NewOAuthUser 通过第三方身份创建用户，用户名由 preferredUsername 或邮箱前缀生成，被占用时追加随机后缀
+func (s *UserService) NewOAuthUser(ctx context.Context, provider, preferredUsername, email string) (*entity.User, error) {
+	base := sanitizeUsername(preferredUsername)
+	if len(base) < 3 {
+		local, _, _ := strings.Cut(email, "@")
+		base = sanitizeUsername(local)
+	}
+	if len(base) < 3 {
+		base = sanitizeUsername(provider) + "_user"
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	username := base
+	for i := 0; ; i++ {
+		existingUser, err := s.userRepo.FindByUsername(ctx, username)
+		if errors.Is(err, domainErrors.ErrUserNotFound) {
+			break
+		}
+		if err != nil {
+			s.logger.Error("Failed to check username availability", zap.Error(err), zap.String("username", username))
+			return nil, err
+		}
+		if i >= 5 {
+			s.logger.Warn("Failed to generate an available username", zap.String("base", base), zap.Uint64("existingUserId", existingUser.ID))
+			return nil, domainErrors.ErrUsernameTaken
+		}
+
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, err
+		}
+		username = base + "_" + hex.EncodeToString(suffix)
+	}
+
+	return entity.NewOAuthUser(username, email)
+}
+
+// sanitizeUsername 去掉用户名中不允许的字符
+func sanitizeUsername(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
 func (s *UserService) UpdateUsername(ctx context.Context, user *entity.User, newUsername string) error {
 	// 检查新用户名是否已被其他用户使用
 	existingUser, err := s.userRepo.FindByUsername(ctx, newUsername)
@@ -67,7 +141,5 @@ func (s *UserService) UpdateUsername(ctx context.Context, user *entity.User, new
 	s.logger.Debug("Username is available", zap.String("username", newUsername))
 
 	// 更新用户名
-	user.Username = newUsername
-
-	return nil
+	return user.ChangeUsername(newUsername)
 }
@@ -1,16 +1,53 @@
 package auth
 
 import (
+	"github.com/go-redis/redis/v8"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
 	"github.com/lyonnee/go-template/internal/infrastructure/config"
 	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
 )
 
 func init() {
 	conf := di.Get[config.Config]()
 
-	jwtGenerator := newJWTGenerator(conf.Auth.JWT)
+	keyring, err := NewKeyring(conf.Auth.JWT)
+	if err != nil {
+		panic("Failed to initialize JWT keyring: " + err.Error())
+	}
+	jwtGenerator := newJWTGenerator(conf.Auth.JWT, keyring)
+
+	di.AddSingleton(func() (*Keyring, error) {
+		return keyring, nil
+	})
 
 	di.AddSingleton(func() (*JWTGenerator, error) {
 		return jwtGenerator, nil
 	})
+
+	di.AddSingleton(func() (*SessionManager, error) {
+		return NewSessionManager(di.Get[redis.UniversalClient](), jwtGenerator), nil
+	})
+
+	di.AddSingleton(func() (*SiweVerifier, error) {
+		return NewSiweVerifier(di.Get[redis.UniversalClient](), conf.Auth.Siwe, conf.Blockchain.Ethereum.ChainID), nil
+	})
+
+	di.AddSingleton(func() (*OAuthManager, error) {
+		return NewOAuthManager(di.Get[redis.UniversalClient](), conf.Auth.OAuth), nil
+	})
+
+	// 启动时校验 MFA 加密密钥，未配置时拒绝启动
+	mfaCipher, err := newMfaCipher(conf.Auth.Mfa.EncryptionKey)
+	if err != nil {
+		panic("Failed to initialize MFA encryption key: " + err.Error())
+	}
+
+	di.AddSingleton(func() (*MfaManager, error) {
+		return newMfaManager(di.Get[redis.UniversalClient](), mfaCipher, conf.Auth.Mfa), nil
+	})
+
+	di.AddSingleton(func() (*LoginGuard, error) {
+		return NewLoginGuard(di.Get[cache.CacheContext](), conf.Auth.LoginGuard, di.Get[*log.Logger]()), nil
+	})
 }
@@ -0,0 +1,19 @@
+package auth
+
+import "context"
+
+const ClaimsKey = "claims"
+
+// SetClaims 将认证通过的 claims 保存到上下文
+func SetClaims(ctx context.Context, claims *Claims) context.Context {
+	if claims == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, ClaimsKey, claims)
+}
+
+// GetClaims 从上下文获取 claims
+func GetClaims(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(ClaimsKey).(*Claims)
+	return claims, ok && claims != nil
+}
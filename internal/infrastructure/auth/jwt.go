@@ -2,29 +2,38 @@ package auth
 
 import (
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/lyonnee/go-template/internal/infrastructure/config"
 )
 
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 // Claims 自定义Claims
 type Claims struct {
-	UserId        uint64 `json:"user_id"`
-	AlternativeID string `json:"alternative_id"` // 备选id， username/email/phone number/wallet address
+	UserId        uint64   `json:"user_id"`
+	AlternativeID string   `json:"alternative_id"`  // 备选id， username/email/phone number/wallet address
+	TokenType     string   `json:"typ"`             // 令牌类型：access / refresh
+	SessionID     string   `json:"sid"`             // 会话ID，同一次登录轮换出的刷新令牌属于同一会话
+	Roles         []string `json:"roles,omitempty"` // 用户角色，只写入访问令牌；刷新时重新加载
 	jwt.RegisteredClaims
 }
 
 type JWTGenerator struct {
-	secretKey          []byte
+	keyring            *Keyring
 	accessTokenExpiry  time.Duration
 	refreshTokenExpiry time.Duration
 	issuer             string
 }
 
-func newJWTGenerator(conf config.JWTConfig) *JWTGenerator {
+func newJWTGenerator(conf config.JWTConfig, keyring *Keyring) *JWTGenerator {
 	jwtManager := &JWTGenerator{
-		secretKey:          []byte(conf.SecretKey),
+		keyring:            keyring,
 		accessTokenExpiry:  conf.AccessTokenExpiry,
 		refreshTokenExpiry: conf.RefreshTokenExpiry,
 		issuer:             conf.Issuer,
@@ -33,44 +42,66 @@ func newJWTGenerator(conf config.JWTConfig) *JWTGenerator {
 	return jwtManager
 }
 
-func (m *JWTGenerator) SecretKey() jwt.Keyfunc {
-	return func(token *jwt.Token) (interface{}, error) {
-		return m.secretKey, nil
-	}
+// Keyring 签名密钥环
+func (m *JWTGenerator) Keyring() *Keyring {
+	return m.keyring
+}
+
+// RefreshTokenExpiry 刷新令牌有效期
+func (m *JWTGenerator) RefreshTokenExpiry() time.Duration {
+	return m.refreshTokenExpiry
 }
 
 // GenerateAccessToken 构建访问token
 // @dev AccessToken 用于身份验证，有效期较短（如 15 分钟）
-func (m *JWTGenerator) GenerateAccessToken(userID uint64, alternativeID string) (string, error) {
-	return m.genToken((userID), alternativeID, m.accessTokenExpiry)
+func (m *JWTGenerator) GenerateAccessToken(userID uint64, alternativeID, sessionID string, roles []string) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+	return m.genToken(userID, alternativeID, TokenTypeAccess, sessionID, jti, roles, m.accessTokenExpiry)
 }
 
-// GenerateRefreshToken 生成刷新令牌
+// GenerateRefreshToken 生成刷新令牌，jti 由会话管理器记录以检测重放
 // @dev RefreshToken 用于刷新 Access Token，有效期较长（如 7 天），通常存储于安全位置（如 HttpOnly Cookie）
-func (m *JWTGenerator) GenerateRefreshToken(userID uint64, alternativeID string) (string, error) {
-	return m.genToken((userID), alternativeID, m.refreshTokenExpiry)
+func (m *JWTGenerator) GenerateRefreshToken(userID uint64, alternativeID, sessionID, jti string) (string, error) {
+	return m.genToken(userID, alternativeID, TokenTypeRefresh, sessionID, jti, nil, m.refreshTokenExpiry)
+}
+
+// ValidateAccessToken 验证访问令牌，刷新令牌不能作为访问令牌使用
+func (m *JWTGenerator) ValidateAccessToken(tokenString string) (*Claims, error) {
+	return m.validateTokenType(tokenString, TokenTypeAccess)
+}
+
+// ValidateRefreshToken 验证刷新令牌
+func (m *JWTGenerator) ValidateRefreshToken(tokenString string) (*Claims, error) {
+	return m.validateTokenType(tokenString, TokenTypeRefresh)
+}
+
+// HasRole 是否拥有指定角色
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
 }
 
-// RefreshToken 刷新JWT（使用刷新令牌生成新的访问令牌）
-func (m *JWTGenerator) RefreshToken(refreshToken string) (string, error) {
-	// 验证刷新令牌
-	claims, err := m.ValidateToken(refreshToken)
+func (m *JWTGenerator) validateTokenType(tokenString, tokenType string) (*Claims, error) {
+	claims, err := m.ValidateToken(tokenString)
 	if err != nil {
-		return "", fmt.Errorf("refresh token is invalid or expired: %w", err)
+		return nil, err
 	}
-
-	// 使用刷新令牌中的用户名生成新的访问令牌
-	return m.GenerateAccessToken(claims.UserId, claims.AlternativeID)
+	if claims.TokenType != tokenType {
+		return nil, fmt.Errorf("unexpected token type %q", claims.TokenType)
+	}
+	if claims.ID == "" || claims.SessionID == "" {
+		return nil, fmt.Errorf("token is missing jti or sid")
+	}
+	return claims, nil
 }
 
 // ValidateToken 验证JWT令牌
 func (m *JWTGenerator) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
-		}
-		return m.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, m.keyring.Keyfunc(),
+		jwt.WithValidMethods(m.keyring.ValidMethods()),
+	)
 
 	if err != nil {
 		return nil, err
@@ -87,18 +118,28 @@ func (m *JWTGenerator) ValidateToken(tokenString string) (*Claims, error) {
 	}
 }
 
-func (m *JWTGenerator) genToken(userID uint64, alternativeID string, expiry time.Duration) (string, error) {
+func (m *JWTGenerator) genToken(userID uint64, alternativeID, tokenType, sessionID, jti string, roles []string, expiry time.Duration) (string, error) {
+	now := time.Now()
 	claims := Claims{
 		AlternativeID: alternativeID,
 		UserId:        userID,
+		TokenType:     tokenType,
+		SessionID:     sessionID,
+		Roles:         roles,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(
-				time.Now().Add(expiry),
+				now.Add(expiry),
 			),
-			Issuer: m.issuer,
+			IssuedAt: jwt.NewNumericDate(now),
+			Issuer:   m.issuer,
+			ID:       jti,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(m.secretKey)
+	return m.keyring.Sign(claims)
+}
+
+// newTokenID 生成随机的令牌ID（jti）或会话ID
+func newTokenID() (string, error) {
+	return randomToken(16)
 }
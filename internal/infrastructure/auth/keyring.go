@@ -0,0 +1,510 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+const (
+	// keyringMinReloadInterval 遇到未知 kid 时重新加载密钥目录的最小间隔，防止被恶意 token 放大磁盘读取
+	keyringMinReloadInterval = 10 * time.Second
+
+	privateKeySuffix = ".pem"
+	publicKeySuffix  = ".pub.pem"
+
+	// hmacKeyID HS256 密钥的 kid，对称密钥不会出现在 JWKS 中
+	hmacKeyID = "hs256"
+)
+
+// signingKey 密钥环中的一个密钥，privateKey 为空表示仅用于验证
+type signingKey struct {
+	kid        string
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
+	createdAt  time.Time
+}
+
+// Keyring JWT 签名密钥环
+// 非对称算法下从 key_dir 加载 PEM 密钥：最新的私钥用于签名，其余未过期的密钥只用于验证；
+// 签名时在 header 中写入 kid，验证时按 kid 选择公钥，轮换后旧令牌在过期前仍可验证
+type Keyring struct {
+	method           jwt.SigningMethod
+	secretKey        []byte
+	keyDir           string
+	rotationInterval time.Duration
+	retention        time.Duration
+
+	mu         sync.RWMutex
+	keys       map[string]*signingKey
+	signingKid string
+	loadedAt   time.Time
+}
+
+// NewKeyring 根据配置创建密钥环，非对称算法下目录中没有私钥时生成一个
+func NewKeyring(conf config.JWTConfig) (*Keyring, error) {
+	alg := conf.Algorithm
+	if alg == "" {
+		alg = jwt.SigningMethodHS256.Alg()
+	}
+
+	k := &Keyring{
+		keyDir:           conf.KeyDir,
+		rotationInterval: conf.RotationInterval,
+		// 旧密钥停止签名后，需要保留到它签发的最长令牌过期
+		retention: max(conf.AccessTokenExpiry, conf.RefreshTokenExpiry),
+		keys:      make(map[string]*signingKey),
+	}
+
+	switch alg {
+	case "HS256":
+		if conf.SecretKey == "" {
+			return nil, errors.New("jwt secret_key is required for HS256")
+		}
+		k.method = jwt.SigningMethodHS256
+		k.secretKey = []byte(conf.SecretKey)
+		k.signingKid = hmacKeyID
+		return k, nil
+	case "RS256":
+		k.method = jwt.SigningMethodRS256
+	case "ES256":
+		k.method = jwt.SigningMethodES256
+	case "EdDSA":
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", alg)
+	}
+
+	if k.keyDir == "" {
+		return nil, fmt.Errorf("jwt key_dir is required for %s", alg)
+	}
+	if err := os.MkdirAll(k.keyDir, 0o700); err != nil {
+		return nil, fmt.Errorf("create jwt key dir: %w", err)
+	}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+
+	k.mu.RLock()
+	hasSigningKey := k.signingKid != ""
+	k.mu.RUnlock()
+	if !hasSigningKey {
+		if _, err := k.Rotate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// Algorithm 签名算法
+func (k *Keyring) Algorithm() string {
+	return k.method.Alg()
+}
+
+// IsSymmetric 是否使用对称密钥（HS256），对称密钥不支持轮换和 JWKS
+func (k *Keyring) IsSymmetric() bool {
+	return k.secretKey != nil
+}
+
+// Sign 使用当前签名密钥签名，header 中携带 kid
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+
+	if k.IsSymmetric() {
+		return token.SignedString(k.secretKey)
+	}
+
+	k.mu.RLock()
+	key, ok := k.keys[k.signingKid]
+	k.mu.RUnlock()
+	if !ok || key.privateKey == nil {
+		return "", errors.New("no jwt signing key available")
+	}
+
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.privateKey)
+}
+
+// Keyfunc 按 token header 中的 kid 选择验证密钥
+func (k *Keyring) Keyfunc() jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != k.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		if k.IsSymmetric() {
+			return k.secretKey, nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return k.verificationKey(kid)
+	}
+}
+
+// ValidMethods 允许的签名算法
+func (k *Keyring) ValidMethods() []string {
+	return []string{k.method.Alg()}
+}
+
+// verificationKey 根据 kid 获取公钥，本地没有时重新加载密钥目录，以识别其他实例轮换出的密钥
+func (k *Keyring) verificationKey(kid string) (crypto.PublicKey, error) {
+	if kid == "" {
+		return nil, errors.New("token is missing kid")
+	}
+
+	k.mu.RLock()
+	key, ok := k.keys[kid]
+	loadedAt := k.loadedAt
+	k.mu.RUnlock()
+	if ok {
+		return key.publicKey, nil
+	}
+
+	if time.Since(loadedAt) < keyringMinReloadInterval {
+		return nil, fmt.Errorf("key %q not found", kid)
+	}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+
+	k.mu.RLock()
+	key, ok = k.keys[kid]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("key %q not found", kid)
+	}
+	return key.publicKey, nil
+}
+
+// Reload 重新加载密钥目录，最新创建的私钥作为签名密钥
+func (k *Keyring) Reload() error {
+	if k.IsSymmetric() {
+		return nil
+	}
+
+	entries, err := os.ReadDir(k.keyDir)
+	if err != nil {
+		return fmt.Errorf("read jwt key dir: %w", err)
+	}
+
+	keys := make(map[string]*signingKey, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, privateKeySuffix) {
+			continue
+		}
+
+		key, err := k.loadKeyFile(filepath.Join(k.keyDir, name))
+		if err != nil {
+			return fmt.Errorf("load jwt key %s: %w", name, err)
+		}
+		// 同一 kid 同时存在私钥和公钥文件时以私钥为准
+		if existing, ok := keys[key.kid]; ok && existing.privateKey != nil {
+			continue
+		}
+		keys[key.kid] = key
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys = keys
+	k.signingKid = newestSigningKid(keys)
+	k.loadedAt = time.Now()
+	return nil
+}
+
+// Rotate 生成新的签名密钥并写入密钥目录，旧密钥保留用于验证，返回新密钥的 kid
+func (k *Keyring) Rotate() (string, error) {
+	if k.IsSymmetric() {
+		return "", errors.New("symmetric jwt key cannot be rotated")
+	}
+
+	privateKey, err := k.generateKey()
+	if err != nil {
+		return "", fmt.Errorf("generate jwt key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	suffix, err := randomToken(4)
+	if err != nil {
+		return "", err
+	}
+	kid := now.Format("20060102T150405Z") + "-" + suffix
+
+	path := filepath.Join(k.keyDir, kid+privateKeySuffix)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("write jwt key: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.keys[kid] = &signingKey{
+		kid:        kid,
+		privateKey: privateKey,
+		publicKey:  privateKey.Public(),
+		createdAt:  now,
+	}
+	k.signingKid = kid
+	return kid, nil
+}
+
+// RotateIfDue 重新加载密钥目录，签名密钥超过轮换间隔时生成新密钥，并清理已不再需要的旧密钥
+// 返回是否发生了轮换
+func (k *Keyring) RotateIfDue() (bool, error) {
+	if k.IsSymmetric() || k.rotationInterval <= 0 {
+		return false, nil
+	}
+
+	if err := k.Reload(); err != nil {
+		return false, err
+	}
+
+	k.mu.RLock()
+	current, ok := k.keys[k.signingKid]
+	k.mu.RUnlock()
+
+	rotated := false
+	if !ok || time.Since(current.createdAt) >= k.rotationInterval {
+		if _, err := k.Rotate(); err != nil {
+			return false, err
+		}
+		rotated = true
+	}
+
+	return rotated, k.prune()
+}
+
+// prune 删除已退役且其签发的令牌均已过期的密钥
+// 密钥的退役时间为下一个更新密钥的创建时间
+func (k *Keyring) prune() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	keys := make([]*signingKey, 0, len(k.keys))
+	for _, key := range k.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].createdAt.Before(keys[j].createdAt)
+	})
+
+	for i := 0; i < len(keys)-1; i++ {
+		key := keys[i]
+		retiredAt := keys[i+1].createdAt
+		if key.kid == k.signingKid || time.Since(retiredAt) < k.retention {
+			continue
+		}
+
+		for _, suffix := range []string{privateKeySuffix, publicKeySuffix} {
+			err := os.Remove(filepath.Join(k.keyDir, key.kid+suffix))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("remove jwt key %s: %w", key.kid, err)
+			}
+		}
+		delete(k.keys, key.kid)
+	}
+
+	return nil
+}
+
+// JWKS 导出所有验证公钥，对称密钥返回空集合
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	if k.IsSymmetric() {
+		return set
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, key := range k.keys {
+		jwk, err := newJWK(key.kid, k.method.Alg(), key.publicKey)
+		if err != nil {
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+
+	return set
+}
+
+func (k *Keyring) generateKey() (crypto.Signer, error) {
+	switch k.method {
+	case jwt.SigningMethodRS256:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case jwt.SigningMethodES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case jwt.SigningMethodEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", k.method.Alg())
+	}
+}
+
+// loadKeyFile 加载 PEM 密钥文件，<kid>.pem 为私钥，<kid>.pub.pem 为公钥
+func (k *Keyring) loadKeyFile(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no pem block found")
+	}
+
+	name := filepath.Base(path)
+	key := &signingKey{createdAt: info.ModTime()}
+
+	if strings.HasSuffix(name, publicKeySuffix) {
+		key.kid = strings.TrimSuffix(name, publicKeySuffix)
+		key.publicKey, err = parsePublicKeyPEM(block)
+	} else {
+		key.kid = strings.TrimSuffix(name, privateKeySuffix)
+		key.privateKey, err = parsePrivateKeyPEM(block)
+		if err == nil {
+			key.publicKey = key.privateKey.Public()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !k.matchesMethod(key.publicKey) {
+		return nil, fmt.Errorf("key type %T does not match algorithm %s", key.publicKey, k.method.Alg())
+	}
+	return key, nil
+}
+
+func (k *Keyring) matchesMethod(publicKey crypto.PublicKey) bool {
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		return k.method == jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		return k.method == jwt.SigningMethodES256 && pub.Curve == elliptic.P256()
+	case ed25519.PublicKey:
+		return k.method == jwt.SigningMethodEdDSA
+	default:
+		return false
+	}
+}
+
+func parsePrivateKeyPEM(block *pem.Block) (crypto.Signer, error) {
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported pem block type %q", block.Type)
+	}
+}
+
+func parsePublicKeyPEM(block *pem.Block) (crypto.PublicKey, error) {
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported pem block type %q", block.Type)
+	}
+}
+
+// newestSigningKid 返回创建时间最新的私钥的 kid
+func newestSigningKid(keys map[string]*signingKey) string {
+	var newest *signingKey
+	for _, key := range keys {
+		if key.privateKey == nil {
+			continue
+		}
+		if newest == nil || key.createdAt.After(newest.createdAt) ||
+			(key.createdAt.Equal(newest.createdAt) && key.kid > newest.kid) {
+			newest = key
+		}
+	}
+	if newest == nil {
+		return ""
+	}
+	return newest.kid
+}
+
+// newJWK 将公钥编码为 JWK
+func newJWK(kid, alg string, publicKey crypto.PublicKey) (JWK, error) {
+	jwk := JWK{Kid: kid, Use: "sig", Alg: alg}
+
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big64(pub.E))
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return JWK{}, fmt.Errorf("unsupported ec curve %s", pub.Curve.Params().Name)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = "P-256"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return JWK{}, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+
+	return jwk, nil
+}
+
+// big64 将 RSA 公钥指数编码为无前导零的大端字节
+func big64(e int) []byte {
+	b := []byte{byte(e >> 24), byte(e >> 16), byte(e >> 8), byte(e)}
+	for len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	return b
+}
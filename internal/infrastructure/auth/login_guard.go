@@ -0,0 +1,201 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultLoginFailureWindow   = 15 * time.Minute
+	defaultLoginLockoutDuration = 15 * time.Minute
+)
+
+// LockedError 登录被锁定，RetryAfter 为剩余锁定时长
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s (retry after %s)", domainErrors.ErrLoginLocked.Message, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockedError) Unwrap() error {
+	return domainErrors.ErrLoginLocked
+}
+
+// LoginGuard 登录防暴力破解：按用户名和IP分别统计失败次数，失败后逐次增加响应延迟，达到阈值后临时锁定
+type LoginGuard struct {
+	cache  cache.CacheContext
+	conf   config.LoginGuardConfig
+	logger *log.Logger
+}
+
+func NewLoginGuard(cacheContext cache.CacheContext, conf config.LoginGuardConfig, logger *log.Logger) *LoginGuard {
+	if conf.FailureWindow <= 0 {
+		conf.FailureWindow = defaultLoginFailureWindow
+	}
+	if conf.LockoutDuration <= 0 {
+		conf.LockoutDuration = defaultLoginLockoutDuration
+	}
+
+	return &LoginGuard{
+		cache:  cacheContext,
+		conf:   conf,
+		logger: logger,
+	}
+}
+
+// loginSubject 登录失败的统计维度：用户名或IP
+type loginSubject struct {
+	scope       string
+	value       string
+	maxFailures int
+	failuresKey cache.KeyBuilder[string]
+	lockKey     cache.KeyBuilder[string]
+}
+
+// subjects 返回需要统计的维度，值为空的维度跳过
+func (g *LoginGuard) subjects(username, ip string) []loginSubject {
+	var subjects []loginSubject
+	if username = normalizeLoginUsername(username); username != "" {
+		subjects = append(subjects, loginSubject{
+			scope:       "user",
+			value:       username,
+			maxFailures: g.conf.MaxFailuresPerUsername,
+			failuresKey: cache.LoginFailuresUserKey,
+			lockKey:     cache.LoginLockUserKey,
+		})
+	}
+	if ip != "" {
+		subjects = append(subjects, loginSubject{
+			scope:       "ip",
+			value:       ip,
+			maxFailures: g.conf.MaxFailuresPerIP,
+			failuresKey: cache.LoginFailuresIPKey,
+			lockKey:     cache.LoginLockIPKey,
+		})
+	}
+	return subjects
+}
+
+// Check 登录前检查用户名和IP是否被锁定，锁定时返回 *LockedError
+func (g *LoginGuard) Check(ctx context.Context, username, ip string) error {
+	var retryAfter time.Duration
+	for _, s := range g.subjects(username, ip) {
+		key := s.lockKey.Key(s.value)
+		locked, err := g.cache.Exists(ctx, key)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			continue
+		}
+
+		ttl, err := g.cache.TTL(ctx, key)
+		if err != nil {
+			return err
+		}
+		if ttl > retryAfter {
+			retryAfter = ttl
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RecordFailure 记录一次登录失败，返回本次响应前应等待的时长
+// 本次失败使用户名或IP达到阈值时同时返回 *LockedError
+func (g *LoginGuard) RecordFailure(ctx context.Context, username, ip string) (time.Duration, error) {
+	var failures int64
+	var lockErr error
+
+	for _, s := range g.subjects(username, ip) {
+		n, err := g.cache.IncrWithTTL(ctx, s.failuresKey.Key(s.value), g.conf.FailureWindow)
+		if err != nil {
+			return 0, err
+		}
+		if n > failures {
+			failures = n
+		}
+
+		if s.maxFailures <= 0 || n < int64(s.maxFailures) {
+			continue
+		}
+
+		if err := g.lock(ctx, s); err != nil {
+			return 0, err
+		}
+		lockErr = &LockedError{RetryAfter: g.conf.LockoutDuration}
+
+		g.logger.Warn("Login locked out",
+			zap.String("audit", "login_lockout"),
+			zap.String("scope", s.scope),
+			zap.String("subject", s.value),
+			zap.Int64("failures", n),
+			zap.Duration("duration", g.conf.LockoutDuration))
+	}
+
+	return g.delay(failures), lockErr
+}
+
+// RecordSuccess 登录成功后清除用户名的失败计数，IP的失败计数保留到窗口结束
+func (g *LoginGuard) RecordSuccess(ctx context.Context, username string) error {
+	return g.cache.Delete(ctx, cache.LoginFailuresUserKey.Key(normalizeLoginUsername(username)))
+}
+
+// Unlock 解除用户名和IP的锁定并清除失败计数，参数为空时跳过该维度
+func (g *LoginGuard) Unlock(ctx context.Context, username, ip string) error {
+	for _, s := range g.subjects(username, ip) {
+		if err := g.cache.Delete(ctx, s.lockKey.Key(s.value)); err != nil {
+			return err
+		}
+		if err := g.cache.Delete(ctx, s.failuresKey.Key(s.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lock 设置锁定标记并重置失败计数，解锁后重新开始统计
+func (g *LoginGuard) lock(ctx context.Context, s loginSubject) error {
+	if err := g.cache.SetWithTTL(ctx, s.lockKey.Key(s.value), "1", g.conf.LockoutDuration); err != nil {
+		return err
+	}
+	return g.cache.Delete(ctx, s.failuresKey.Key(s.value))
+}
+
+// delay 第 n 次失败的响应延迟为 DelayBase * 2^(n-1)，不超过 MaxDelay
+func (g *LoginGuard) delay(failures int64) time.Duration {
+	if g.conf.DelayBase <= 0 || failures <= 0 {
+		return 0
+	}
+
+	d := g.conf.DelayBase
+	for i := int64(1); i < failures; i++ {
+		d *= 2
+		if g.conf.MaxDelay > 0 && d >= g.conf.MaxDelay {
+			return g.conf.MaxDelay
+		}
+	}
+
+	if g.conf.MaxDelay > 0 && d > g.conf.MaxDelay {
+		return g.conf.MaxDelay
+	}
+	return d
+}
+
+// normalizeLoginUsername 统一用户名大小写，避免通过变换大小写绕过计数
+func normalizeLoginUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
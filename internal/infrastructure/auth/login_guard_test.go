@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"go.uber.org/zap"
+)
+
+func newTestLoginGuard(conf config.LoginGuardConfig) *LoginGuard {
+	return NewLoginGuard(cache.NewMemoryCache(0), conf, zap.NewNop())
+}
+
+func TestLoginGuardDelay(t *testing.T) {
+	guard := newTestLoginGuard(config.LoginGuardConfig{
+		DelayBase: 100 * time.Millisecond,
+		MaxDelay:  time.Second,
+	})
+
+	tests := []struct {
+		failures int64
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{64, time.Second},
+	}
+	for _, tt := range tests {
+		if got := guard.delay(tt.failures); got != tt.want {
+			t.Errorf("delay(%d) = %s, want %s", tt.failures, got, tt.want)
+		}
+	}
+
+	// 未配置上限时不截断，未配置基数时不延迟
+	unbounded := newTestLoginGuard(config.LoginGuardConfig{DelayBase: time.Millisecond})
+	if got := unbounded.delay(11); got != 1024*time.Millisecond {
+		t.Errorf("unbounded delay(11) = %s, want 1.024s", got)
+	}
+	disabled := newTestLoginGuard(config.LoginGuardConfig{MaxDelay: time.Second})
+	if got := disabled.delay(5); got != 0 {
+		t.Errorf("disabled delay(5) = %s, want 0", got)
+	}
+}
+
+func TestLoginGuardLockout(t *testing.T) {
+	ctx := context.Background()
+	guard := newTestLoginGuard(config.LoginGuardConfig{
+		MaxFailuresPerUsername: 3,
+		MaxFailuresPerIP:       5,
+		LockoutDuration:        time.Minute,
+		DelayBase:              10 * time.Millisecond,
+		MaxDelay:               time.Second,
+	})
+
+	// 用户名按大小写归一化计数
+	for i, username := range []string{"alice", "Alice"} {
+		delay, err := guard.RecordFailure(ctx, username, "198.51.100.1")
+		if err != nil {
+			t.Fatalf("failure %d: %v", i+1, err)
+		}
+		if want := guard.delay(int64(i + 1)); delay != want {
+			t.Fatalf("failure %d delay = %s, want %s", i+1, delay, want)
+		}
+	}
+	if err := guard.Check(ctx, "alice", "198.51.100.1"); err != nil {
+		t.Fatalf("Check before lockout: %v", err)
+	}
+
+	_, err := guard.RecordFailure(ctx, " ALICE ", "198.51.100.1")
+	var locked *LockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("third failure err = %v, want *LockedError", err)
+	}
+	if locked.RetryAfter != time.Minute {
+		t.Fatalf("RetryAfter = %s, want 1m", locked.RetryAfter)
+	}
+	if !errors.Is(err, domainErrors.ErrLoginLocked) {
+		t.Fatalf("err does not wrap ErrLoginLocked: %v", err)
+	}
+
+	// 用户名被锁定，从其他IP登录同样被拒绝
+	err = guard.Check(ctx, "alice", "203.0.113.9")
+	if !errors.As(err, &locked) || locked.RetryAfter <= 0 || locked.RetryAfter > time.Minute {
+		t.Fatalf("Check after lockout = %v, want *LockedError within 1m", err)
+	}
+	// IP未达到阈值，其他用户名不受影响
+	if err := guard.Check(ctx, "bob", "198.51.100.1"); err != nil {
+		t.Fatalf("Check other user: %v", err)
+	}
+
+	if err := guard.Unlock(ctx, "alice", ""); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if err := guard.Check(ctx, "alice", "198.51.100.1"); err != nil {
+		t.Fatalf("Check after unlock: %v", err)
+	}
+}
+
+func TestLoginGuardIPLockout(t *testing.T) {
+	ctx := context.Background()
+	guard := newTestLoginGuard(config.LoginGuardConfig{
+		MaxFailuresPerIP: 2,
+		LockoutDuration:  time.Minute,
+	})
+
+	// 同一IP轮换用户名，仍按IP累计
+	if _, err := guard.RecordFailure(ctx, "alice", "198.51.100.1"); err != nil {
+		t.Fatalf("first failure: %v", err)
+	}
+	if _, err := guard.RecordFailure(ctx, "bob", "198.51.100.1"); !errors.Is(err, domainErrors.ErrLoginLocked) {
+		t.Fatalf("second failure err = %v, want ErrLoginLocked", err)
+	}
+
+	if err := guard.Check(ctx, "carol", "198.51.100.1"); !errors.Is(err, domainErrors.ErrLoginLocked) {
+		t.Fatalf("Check locked ip = %v, want ErrLoginLocked", err)
+	}
+	if err := guard.Check(ctx, "carol", "203.0.113.9"); err != nil {
+		t.Fatalf("Check other ip: %v", err)
+	}
+}
+
+func TestLoginGuardRecordSuccessResetsUsername(t *testing.T) {
+	ctx := context.Background()
+	guard := newTestLoginGuard(config.LoginGuardConfig{
+		MaxFailuresPerUsername: 2,
+		LockoutDuration:        time.Minute,
+	})
+
+	if _, err := guard.RecordFailure(ctx, "alice", ""); err != nil {
+		t.Fatalf("first failure: %v", err)
+	}
+	if err := guard.RecordSuccess(ctx, "Alice"); err != nil {
+		t.Fatalf("RecordSuccess: %v", err)
+	}
+	// 计数已清除，再失败一次不会锁定
+	if _, err := guard.RecordFailure(ctx, "alice", ""); err != nil {
+		t.Fatalf("failure after success: %v", err)
+	}
+}
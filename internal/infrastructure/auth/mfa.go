@@ -0,0 +1,365 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+const (
+	defaultMfaChallengeTTL   = 5 * time.Minute
+	defaultMfaMaxAttempts    = 5
+	defaultMfaLockout        = 15 * time.Minute
+	defaultRecoveryCodeCount = 10
+
+	// MfaFactorTOTP 使用认证器应用的验证码完成第二因素
+	MfaFactorTOTP = "totp"
+	// MfaFactorRecoveryCode 使用恢复码完成第二因素
+	MfaFactorRecoveryCode = "recovery_code"
+)
+
+// recoveryCodeAlphabet 恢复码字符集，去掉了容易混淆的 0/O、1/I，共 32 个字符
+const recoveryCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// failChallengeScript 记录一次验证码错误，达到最大次数后删除挑战
+// 返回剩余可尝试次数，-1 表示挑战不存在
+var failChallengeScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -1
+end
+local attempts = redis.call("HINCRBY", KEYS[1], "attempts", 1)
+local remaining = tonumber(ARGV[1]) - attempts
+if remaining <= 0 then
+	redis.call("DEL", KEYS[1])
+	return 0
+end
+return remaining
+`)
+
+// failMfaCodeScript 记录一次账户操作的验证码错误，首次错误时开始统计窗口，达到最大次数时重新计时作为锁定时长
+// 返回剩余可尝试次数
+var failMfaCodeScript = redis.NewScript(`
+local failures = redis.call("INCR", KEYS[1])
+local remaining = tonumber(ARGV[1]) - failures
+if failures == 1 or remaining <= 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return remaining
+`)
+
+// MfaManager 多因素认证：TOTP 密钥的加密存储和校验、恢复码生成，以及两步登录的挑战令牌
+type MfaManager struct {
+	client            redis.UniversalClient
+	aead              cipher.AEAD
+	issuer            string
+	challengeTTL      time.Duration
+	maxAttempts       int
+	lockout           time.Duration
+	recoveryCodeCount int
+}
+
+func NewMfaManager(client redis.UniversalClient, conf config.MfaConfig) (*MfaManager, error) {
+	aead, err := newMfaCipher(conf.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return newMfaManager(client, aead, conf), nil
+}
+
+func newMfaManager(client redis.UniversalClient, aead cipher.AEAD, conf config.MfaConfig) *MfaManager {
+	if conf.ChallengeTTL <= 0 {
+		conf.ChallengeTTL = defaultMfaChallengeTTL
+	}
+	if conf.MaxAttempts <= 0 {
+		conf.MaxAttempts = defaultMfaMaxAttempts
+	}
+	if conf.LockoutDuration <= 0 {
+		conf.LockoutDuration = defaultMfaLockout
+	}
+	if conf.RecoveryCodeCount <= 0 {
+		conf.RecoveryCodeCount = defaultRecoveryCodeCount
+	}
+
+	return &MfaManager{
+		client:            client,
+		aead:              aead,
+		issuer:            conf.Issuer,
+		challengeTTL:      conf.ChallengeTTL,
+		maxAttempts:       conf.MaxAttempts,
+		lockout:           conf.LockoutDuration,
+		recoveryCodeCount: conf.RecoveryCodeCount,
+	}
+}
+
+// newMfaCipher 使用 base64 编码的 32 字节密钥创建 AES-256-GCM，密钥未配置时返回错误
+func newMfaCipher(encryptionKey string) (cipher.AEAD, error) {
+	if encryptionKey == "" {
+		return nil, errors.New("mfa encryption_key is not set, inject it via APP_AUTH_MFA_ENCRYPTION_KEY")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mfa encryption_key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, errors.New("mfa encryption_key must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// NewTOTPSecret 为用户生成 TOTP 密钥，返回明文密钥、otpauth URI 和加密后用于存储的密钥
+// 加密后的密钥与 userID 绑定，只能由同一用户解密
+func (m *MfaManager) NewTOTPSecret(userID uint64, account string) (secret, uri, encrypted string, err error) {
+	secret, err = GenerateTOTPSecret()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	encrypted, err = m.encrypt(userID, secret)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return secret, TOTPURI(m.issuer, account, secret), encrypted, nil
+}
+
+// VerifyTOTP 使用加密存储的密钥校验验证码，同一时间步的验证码只能使用一次
+func (m *MfaManager) VerifyTOTP(ctx context.Context, userID uint64, encryptedSecret, code string) error {
+	secret, err := m.decrypt(userID, encryptedSecret)
+	if err != nil {
+		return err
+	}
+
+	step, ok, err := matchTOTP(secret, strings.TrimSpace(code), time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domainErrors.ErrInvalidMfaCode
+	}
+
+	// 时间步在前后偏差窗口内都可能被接受，标记保留三个周期
+	fresh, err := m.client.SetNX(ctx, cache.TOTPUsedKey.Key(cache.TOTPStep{UserID: userID, Step: step}), 1, 3*totpPeriod*time.Second).Result()
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return domainErrors.ErrInvalidMfaCode
+	}
+
+	return nil
+}
+
+// IsTOTPCode 判断输入是否为 TOTP 验证码格式，否则视为恢复码
+func IsTOTPCode(code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return false
+	}
+	_, err := strconv.Atoi(code)
+	return err == nil
+}
+
+// NewRecoveryCodes 生成恢复码，返回明文（只展示给用户一次）和用于存储的哈希
+func (m *MfaManager) NewRecoveryCodes() (codes, hashes []string, err error) {
+	codes = make([]string, 0, m.recoveryCodeCount)
+	hashes = make([]string, 0, m.recoveryCodeCount)
+
+	buf := make([]byte, 10)
+	for i := 0; i < m.recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		var sb strings.Builder
+		for j, b := range buf {
+			if j == 5 {
+				sb.WriteByte('-')
+			}
+			sb.WriteByte(recoveryCodeAlphabet[b&31])
+		}
+
+		code := sb.String()
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode 计算恢复码哈希，忽略大小写、空格和连字符
+// 恢复码为高熵随机值，使用 SHA-256 即可抵御离线猜测
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// MfaChallenge 两步登录的挑战
+type MfaChallenge struct {
+	UserID uint64
+	Method string // 已通过的第一因素登录方式，如 password、wallet、oauth:google
+}
+
+// IssueChallenge 第一因素校验通过后创建登录挑战，返回提交第二因素时使用的一次性令牌
+func (m *MfaManager) IssueChallenge(ctx context.Context, userID uint64, method string) (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	key := cache.MfaChallengeKey.Key(token)
+	pipe := m.client.TxPipeline()
+	pipe.HSet(ctx, key,
+		"user_id", strconv.FormatUint(userID, 10),
+		"method", method,
+		"attempts", 0,
+	)
+	pipe.Expire(ctx, key, m.challengeTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", fmt.Errorf("create mfa challenge: %w", err)
+	}
+
+	return token, nil
+}
+
+// Challenge 返回登录挑战所属的用户和第一因素登录方式
+func (m *MfaManager) Challenge(ctx context.Context, token string) (*MfaChallenge, error) {
+	if token == "" {
+		return nil, domainErrors.ErrInvalidMfaChallenge
+	}
+
+	values, err := m.client.HMGet(ctx, cache.MfaChallengeKey.Key(token), "user_id", "method").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := values[0].(string)
+	if !ok {
+		return nil, domainErrors.ErrInvalidMfaChallenge
+	}
+	userID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, domainErrors.ErrInvalidMfaChallenge
+	}
+
+	method, _ := values[1].(string)
+	if method == "" {
+		method = "password"
+	}
+	return &MfaChallenge{UserID: userID, Method: method}, nil
+}
+
+// FailChallenge 记录一次验证码错误，超过最大次数后挑战失效，需要重新完成第一因素登录
+func (m *MfaManager) FailChallenge(ctx context.Context, token string) error {
+	remaining, err := failChallengeScript.Run(ctx, m.client, []string{cache.MfaChallengeKey.Key(token)}, m.maxAttempts).Int()
+	if err != nil {
+		return fmt.Errorf("record mfa failure: %w", err)
+	}
+	if remaining <= 0 {
+		return domainErrors.ErrInvalidMfaChallenge
+	}
+	return domainErrors.ErrInvalidMfaCode
+}
+
+// CompleteChallenge 第二因素校验通过后删除挑战，并发提交时只有一个请求能完成登录
+func (m *MfaManager) CompleteChallenge(ctx context.Context, token string) error {
+	n, err := m.client.Del(ctx, cache.MfaChallengeKey.Key(token)).Result()
+	if err != nil {
+		return fmt.Errorf("complete mfa challenge: %w", err)
+	}
+	if n == 0 {
+		return domainErrors.ErrInvalidMfaChallenge
+	}
+	return nil
+}
+
+// CheckCodeLock 关闭多因素认证等账户操作校验验证码前调用，错误次数已达上限时返回 ErrMfaLocked
+func (m *MfaManager) CheckCodeLock(ctx context.Context, userID uint64) error {
+	failures, err := m.client.Get(ctx, cache.MfaFailuresKey.Key(userID)).Int()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check mfa lock: %w", err)
+	}
+	if failures >= m.maxAttempts {
+		return domainErrors.ErrMfaLocked
+	}
+	return nil
+}
+
+// FailCode 记录一次账户操作的验证码错误，返回 ErrInvalidMfaCode，达到最大次数时返回 ErrMfaLocked
+func (m *MfaManager) FailCode(ctx context.Context, userID uint64) error {
+	remaining, err := failMfaCodeScript.Run(ctx, m.client, []string{cache.MfaFailuresKey.Key(userID)}, m.maxAttempts, m.lockout.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("record mfa failure: %w", err)
+	}
+	if remaining <= 0 {
+		return domainErrors.ErrMfaLocked
+	}
+	return domainErrors.ErrInvalidMfaCode
+}
+
+// ResetCodeFailures 账户操作的验证码校验通过后清除错误次数
+func (m *MfaManager) ResetCodeFailures(ctx context.Context, userID uint64) error {
+	if err := m.client.Del(ctx, cache.MfaFailuresKey.Key(userID)).Err(); err != nil {
+		return fmt.Errorf("reset mfa failures: %w", err)
+	}
+	return nil
+}
+
+// encrypt AES-GCM 加密，输出 base64(nonce || ciphertext)
+// 用户ID作为附加数据参与认证，密文被复制到其他用户的记录上时无法解密
+func (m *MfaManager) encrypt(userID uint64, plaintext string) (string, error) {
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := m.aead.Seal(nonce, nonce, []byte(plaintext), mfaSecretAAD(userID))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (m *MfaManager) decrypt(userID uint64, encrypted string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("decode mfa secret: %w", err)
+	}
+
+	nonceSize := m.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", errors.New("mfa secret is too short")
+	}
+
+	plaintext, err := m.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], mfaSecretAAD(userID))
+	if err != nil {
+		return "", fmt.Errorf("decrypt mfa secret: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// mfaSecretAAD TOTP 密钥加密的附加数据
+func mfaSecretAAD(userID uint64) []byte {
+	return binary.BigEndian.AppendUint64([]byte("mfa_secret:"), userID)
+}
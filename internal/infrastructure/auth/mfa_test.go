@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+const testMfaEncryptionKey = "e3ilIe4DW60DSljSMCppAw0kusqr098XLo64VA+3958="
+
+func TestNewMfaManagerRequiresEncryptionKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{"empty", ""},
+		{"not base64", "not-base64!"},
+		{"wrong length", "c2hvcnQ="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewMfaManager(nil, config.MfaConfig{EncryptionKey: tt.key}); err == nil {
+				t.Fatal("NewMfaManager succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestMfaSecretBoundToUser(t *testing.T) {
+	manager, err := NewMfaManager(nil, config.MfaConfig{Issuer: "go-template", EncryptionKey: testMfaEncryptionKey})
+	if err != nil {
+		t.Fatalf("NewMfaManager: %v", err)
+	}
+
+	secret, _, encrypted, err := manager.NewTOTPSecret(1, "alice@example.com")
+	if err != nil {
+		t.Fatalf("NewTOTPSecret: %v", err)
+	}
+
+	got, err := manager.decrypt(1, encrypted)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if got != secret {
+		t.Fatalf("decrypted secret = %q, want %q", got, secret)
+	}
+
+	// 密文被复制到其他用户的记录上时无法解密
+	if _, err := manager.decrypt(2, encrypted); err == nil {
+		t.Fatal("decrypt succeeded for another user")
+	}
+}
+
+func TestMfaChallenge(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+	manager, err := NewMfaManager(client, config.MfaConfig{EncryptionKey: testMfaEncryptionKey, MaxAttempts: 2})
+	if err != nil {
+		t.Fatalf("NewMfaManager: %v", err)
+	}
+
+	token, err := manager.IssueChallenge(ctx, 42, "oauth:google")
+	if err != nil {
+		t.Fatalf("IssueChallenge: %v", err)
+	}
+
+	challenge, err := manager.Challenge(ctx, token)
+	if err != nil {
+		t.Fatalf("Challenge: %v", err)
+	}
+	if challenge.UserID != 42 || challenge.Method != "oauth:google" {
+		t.Fatalf("challenge = %+v", challenge)
+	}
+
+	if err := manager.FailChallenge(ctx, token); !errors.Is(err, domainErrors.ErrInvalidMfaCode) {
+		t.Fatalf("first failure = %v, want ErrInvalidMfaCode", err)
+	}
+	// 达到最大错误次数后挑战失效
+	if err := manager.FailChallenge(ctx, token); !errors.Is(err, domainErrors.ErrInvalidMfaChallenge) {
+		t.Fatalf("second failure = %v, want ErrInvalidMfaChallenge", err)
+	}
+	if _, err := manager.Challenge(ctx, token); !errors.Is(err, domainErrors.ErrInvalidMfaChallenge) {
+		t.Fatalf("Challenge after lockout = %v, want ErrInvalidMfaChallenge", err)
+	}
+	if _, err := manager.Challenge(ctx, ""); !errors.Is(err, domainErrors.ErrInvalidMfaChallenge) {
+		t.Fatalf("Challenge with empty token = %v, want ErrInvalidMfaChallenge", err)
+	}
+}
+
+func TestMfaCodeLockout(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newTestRedis(t)
+	manager, err := NewMfaManager(client, config.MfaConfig{
+		EncryptionKey:   testMfaEncryptionKey,
+		MaxAttempts:     3,
+		LockoutDuration: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewMfaManager: %v", err)
+	}
+
+	if err := manager.CheckCodeLock(ctx, 7); err != nil {
+		t.Fatalf("CheckCodeLock before failures: %v", err)
+	}
+	for i := 1; i < 3; i++ {
+		if err := manager.FailCode(ctx, 7); !errors.Is(err, domainErrors.ErrInvalidMfaCode) {
+			t.Fatalf("failure %d = %v, want ErrInvalidMfaCode", i, err)
+		}
+		if err := manager.CheckCodeLock(ctx, 7); err != nil {
+			t.Fatalf("CheckCodeLock after %d failures: %v", i, err)
+		}
+	}
+
+	// 第三次错误后锁定，锁定时长重新计时
+	mr.FastForward(50 * time.Second)
+	if err := manager.FailCode(ctx, 7); !errors.Is(err, domainErrors.ErrMfaLocked) {
+		t.Fatalf("third failure = %v, want ErrMfaLocked", err)
+	}
+	mr.FastForward(50 * time.Second)
+	if err := manager.CheckCodeLock(ctx, 7); !errors.Is(err, domainErrors.ErrMfaLocked) {
+		t.Fatalf("CheckCodeLock while locked = %v, want ErrMfaLocked", err)
+	}
+	// 其他用户不受影响
+	if err := manager.CheckCodeLock(ctx, 8); err != nil {
+		t.Fatalf("CheckCodeLock other user: %v", err)
+	}
+
+	mr.FastForward(11 * time.Second)
+	if err := manager.CheckCodeLock(ctx, 7); err != nil {
+		t.Fatalf("CheckCodeLock after lockout: %v", err)
+	}
+
+	// 校验通过后清除错误次数
+	if err := manager.FailCode(ctx, 7); !errors.Is(err, domainErrors.ErrInvalidMfaCode) {
+		t.Fatalf("failure after lockout = %v, want ErrInvalidMfaCode", err)
+	}
+	if err := manager.ResetCodeFailures(ctx, 7); err != nil {
+		t.Fatalf("ResetCodeFailures: %v", err)
+	}
+	if mr.Exists(cache.MfaFailuresKey.Key(7)) {
+		t.Fatal("failure counter not cleared")
+	}
+}
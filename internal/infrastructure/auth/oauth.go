@@ -1 +1,182 @@
 package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+const (
+	defaultOAuthStateTTL = 10 * time.Minute
+	defaultOAuthTimeout  = 10 * time.Second
+)
+
+// oauthState 授权请求发起时保存的状态，回调时取出并删除
+type oauthState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// OAuthIdentity 第三方身份提供方认证通过的用户身份
+type OAuthIdentity struct {
+	Provider          string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// VerifiedEmail 返回身份提供方验证过的邮箱，未验证时返回空字符串
+// 只有验证过的邮箱可以用于关联已有账号，避免通过未验证邮箱接管账号
+func (i *OAuthIdentity) VerifiedEmail() string {
+	if !i.EmailVerified {
+		return ""
+	}
+	return i.Email
+}
+
+// OAuthManager OAuth2 授权码 + PKCE 登录流程，state、nonce 和 code_verifier 保存在 Redis 中
+type OAuthManager struct {
+	client    redis.UniversalClient
+	stateTTL  time.Duration
+	providers map[string]*OIDCProvider
+}
+
+func NewOAuthManager(client redis.UniversalClient, conf config.OAuthConfig) *OAuthManager {
+	if conf.StateTTL <= 0 {
+		conf.StateTTL = defaultOAuthStateTTL
+	}
+	if conf.Timeout <= 0 {
+		conf.Timeout = defaultOAuthTimeout
+	}
+
+	httpClient := &http.Client{Timeout: conf.Timeout}
+
+	providers := make(map[string]*OIDCProvider, len(conf.Providers))
+	for name, providerConf := range conf.Providers {
+		// 未配置客户端ID的身份提供方视为未启用
+		if providerConf.ClientID == "" || providerConf.Issuer == "" {
+			continue
+		}
+		providers[name] = NewOIDCProvider(name, providerConf, conf.ClockSkew, httpClient)
+	}
+
+	return &OAuthManager{
+		client:    client,
+		stateTTL:  conf.StateTTL,
+		providers: providers,
+	}
+}
+
+// AuthCodeURL 生成 state、nonce 和 PKCE code_verifier，返回身份提供方的授权地址
+func (m *OAuthManager) AuthCodeURL(ctx context.Context, providerName string) (string, error) {
+	provider, ok := m.providers[providerName]
+	if !ok {
+		return "", domainErrors.ErrOAuthProviderNotFound
+	}
+
+	state, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	codeVerifier, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeChallengeS256(codeVerifier))
+	if err != nil {
+		return "", err
+	}
+
+	value, err := json.Marshal(oauthState{
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := m.client.Set(ctx, cache.OAuthStateKey.Key(state), value, m.stateTTL).Err(); err != nil {
+		return "", err
+	}
+
+	return authURL, nil
+}
+
+// Exchange 校验 state 后使用授权码换取并校验 ID Token，state 只能使用一次
+func (m *OAuthManager) Exchange(ctx context.Context, providerName, code, state string) (*OAuthIdentity, error) {
+	provider, ok := m.providers[providerName]
+	if !ok {
+		return nil, domainErrors.ErrOAuthProviderNotFound
+	}
+	if state == "" || code == "" {
+		return nil, domainErrors.ErrInvalidOAuthState
+	}
+
+	value, err := m.client.GetDel(ctx, cache.OAuthStateKey.Key(state)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domainErrors.ErrInvalidOAuthState
+		}
+		return nil, err
+	}
+
+	var saved oauthState
+	if err := json.Unmarshal(value, &saved); err != nil {
+		return nil, fmt.Errorf("decode oauth state: %w", err)
+	}
+	// state 必须由同一身份提供方发起，防止跨提供方的授权码注入
+	if saved.Provider != providerName {
+		return nil, domainErrors.ErrInvalidOAuthState
+	}
+
+	claims, err := provider.Exchange(ctx, code, saved.CodeVerifier, saved.Nonce)
+	if err != nil {
+		if errors.Is(err, domainErrors.ErrInvalidIDToken) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", domainErrors.ErrOAuthExchangeFailed, err)
+	}
+
+	return &OAuthIdentity{
+		Provider:          providerName,
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     claims.IsEmailVerified(),
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+// randomToken 生成 base64url 编码的随机字符串
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// codeChallengeS256 计算 PKCE S256 code_challenge
+func codeChallengeS256(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
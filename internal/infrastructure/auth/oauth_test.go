@@ -0,0 +1,663 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+const (
+	testClientID     = "test-client"
+	testClientSecret = "test-secret"
+	testRedirectURL  = "http://localhost:8080/api/auth/oauth/fake/callback"
+)
+
+// fakeAuthorization 用户在身份提供方同意授权后，授权码对应的请求参数和身份
+type fakeAuthorization struct {
+	nonce         string
+	codeChallenge string
+	claims        jwt.MapClaims
+}
+
+// fakeOIDCProvider httptest 实现的 OIDC 身份提供方，提供发现文档、JWKS 和令牌端点
+type fakeOIDCProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	discoveryHits atomic.Int32
+	jwksHits      atomic.Int32
+
+	mu    sync.Mutex
+	codes map[string]fakeAuthorization
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+
+	p := &fakeOIDCProvider{
+		t:     t,
+		key:   newRSAKey(t),
+		kid:   "fake-key-1",
+		codes: make(map[string]fakeAuthorization),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		p.discoveryHits.Add(1)
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                                p.issuer(),
+			"authorization_endpoint":                p.issuer() + "/authorize",
+			"token_endpoint":                        p.issuer() + "/token",
+			"jwks_uri":                              p.issuer() + "/jwks",
+			"code_challenge_methods_supported":      []string{"S256"},
+			"token_endpoint_auth_methods_supported": []string{"client_secret_basic"},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.jwksHits.Add(1)
+		writeJSON(w, http.StatusOK, JWKSet{Keys: []JWK{rsaJWK(p.kid, &p.key.PublicKey)}})
+	})
+	mux.HandleFunc("/token", p.handleToken)
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *fakeOIDCProvider) issuer() string {
+	return p.server.URL
+}
+
+func (p *fakeOIDCProvider) providerConfig() config.OAuthProviderConfig {
+	return config.OAuthProviderConfig{
+		Issuer:       p.issuer(),
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"email", "profile"},
+	}
+}
+
+func (p *fakeOIDCProvider) newProvider() *OIDCProvider {
+	return NewOIDCProvider("fake", p.providerConfig(), 0, p.server.Client())
+}
+
+// authorize 模拟用户在授权页面同意授权，校验授权请求并返回回调中的授权码
+func (p *fakeOIDCProvider) authorize(authURL string, claims jwt.MapClaims) (code, state string) {
+	p.t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil {
+		p.t.Fatalf("parse auth url: %v", err)
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != p.issuer()+"/authorize" {
+		p.t.Fatalf("auth url endpoint = %s", got)
+	}
+
+	q := u.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             testClientID,
+		"redirect_uri":          testRedirectURL,
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			p.t.Fatalf("auth url %s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+	if scopes := strings.Fields(q.Get("scope")); len(scopes) == 0 || scopes[0] != "openid" {
+		p.t.Fatalf("auth url scope = %q, want openid first", q.Get("scope"))
+	}
+	for _, k := range []string{"state", "nonce", "code_challenge"} {
+		if q.Get(k) == "" {
+			p.t.Fatalf("auth url is missing %s", k)
+		}
+	}
+
+	code = randomString(p.t)
+	p.mu.Lock()
+	p.codes[code] = fakeAuthorization{
+		nonce:         q.Get("nonce"),
+		codeChallenge: q.Get("code_challenge"),
+		claims:        claims,
+	}
+	p.mu.Unlock()
+
+	return code, q.Get("state")
+}
+
+// handleToken 令牌端点，授权码只能使用一次，code_verifier 必须与授权请求的 code_challenge 匹配
+func (p *fakeOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, secret, ok := r.BasicAuth()
+	if !ok || clientID != testClientID || secret != testClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("redirect_uri") != testRedirectURL {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	p.mu.Lock()
+	authz, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "unknown code"})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != authz.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "code_verifier mismatch"})
+		return
+	}
+
+	claims := p.defaultClaims(authz.nonce)
+	for k, v := range authz.claims {
+		claims[k] = v
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     p.sign(claims),
+	})
+}
+
+func (p *fakeOIDCProvider) defaultClaims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":            p.issuer(),
+		"aud":            testClientID,
+		"sub":            "subject-1",
+		"nonce":          nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"name":           "Alice",
+	}
+}
+
+func (p *fakeOIDCProvider) sign(claims jwt.MapClaims) string {
+	p.t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.kid
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		p.t.Fatalf("sign id token: %v", err)
+	}
+	return signed
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	return key
+}
+
+func rsaJWK(kid string, key *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func randomString(t *testing.T) string {
+	t.Helper()
+
+	s, err := randomToken(16)
+	if err != nil {
+		t.Fatalf("random token: %v", err)
+	}
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, client
+}
+
+func newTestOAuthManager(t *testing.T, providers map[string]config.OAuthProviderConfig) (*OAuthManager, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr, client := newTestRedis(t)
+	return NewOAuthManager(client, config.OAuthConfig{
+		StateTTL:  time.Minute,
+		Providers: providers,
+	}), mr
+}
+
+func TestOIDCDiscovery(t *testing.T) {
+	fake := newFakeOIDCProvider(t)
+	provider := fake.newProvider()
+
+	discovery, err := provider.Discover(context.Background())
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if discovery.TokenEndpoint != fake.issuer()+"/token" || discovery.JWKSURI != fake.issuer()+"/jwks" {
+		t.Fatalf("unexpected discovery document: %+v", discovery)
+	}
+
+	if _, err := provider.Discover(context.Background()); err != nil {
+		t.Fatalf("second Discover: %v", err)
+	}
+	if n := fake.discoveryHits.Load(); n != 1 {
+		t.Fatalf("discovery fetched %d times, want 1", n)
+	}
+}
+
+func TestOIDCDiscoveryRejectsInvalidDocument(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  func(issuer string) map[string]any
+	}{
+		{"issuer mismatch", func(issuer string) map[string]any {
+			return map[string]any{
+				"issuer":                 "https://evil.example.com",
+				"authorization_endpoint": issuer + "/authorize",
+				"token_endpoint":         issuer + "/token",
+				"jwks_uri":               issuer + "/jwks",
+			}
+		}},
+		{"missing token endpoint", func(issuer string) map[string]any {
+			return map[string]any{
+				"issuer":                 issuer,
+				"authorization_endpoint": issuer + "/authorize",
+				"jwks_uri":               issuer + "/jwks",
+			}
+		}},
+		{"no pkce s256", func(issuer string) map[string]any {
+			return map[string]any{
+				"issuer":                           issuer,
+				"authorization_endpoint":           issuer + "/authorize",
+				"token_endpoint":                   issuer + "/token",
+				"jwks_uri":                         issuer + "/jwks",
+				"code_challenge_methods_supported": []string{"plain"},
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusOK, tt.doc(server.URL))
+			}))
+			defer server.Close()
+
+			provider := NewOIDCProvider("fake", config.OAuthProviderConfig{Issuer: server.URL, ClientID: testClientID}, 0, server.Client())
+			if _, err := provider.Discover(context.Background()); err == nil {
+				t.Fatal("Discover succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestOIDCDiscoveryFailureIsNotCached(t *testing.T) {
+	fake := newFakeOIDCProvider(t)
+
+	var fail atomic.Bool
+	fail.Store(true)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fake.server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	// 发现文档中的 issuer 为 fake 的地址，通过 proxy 访问时 issuer 不一致，这里只校验失败后会重新请求
+	provider := NewOIDCProvider("fake", config.OAuthProviderConfig{Issuer: fake.issuer(), ClientID: testClientID}, 0, &http.Client{
+		Transport: rewriteHost(proxy.URL),
+	})
+	if _, err := provider.Discover(context.Background()); err == nil {
+		t.Fatal("Discover succeeded while provider is unavailable")
+	}
+
+	fail.Store(false)
+	if _, err := provider.Discover(context.Background()); err != nil {
+		t.Fatalf("Discover after recovery: %v", err)
+	}
+}
+
+// rewriteHost 将请求转发到 target，用于在身份提供方前插入故障
+type rewriteHost string
+
+func (target rewriteHost) RoundTrip(r *http.Request) (*http.Response, error) {
+	u, _ := url.Parse(string(target))
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host, r.Host = u.Scheme, u.Host, u.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestOAuthManagerLogin(t *testing.T) {
+	fake := newFakeOIDCProvider(t)
+	manager, _ := newTestOAuthManager(t, map[string]config.OAuthProviderConfig{"fake": fake.providerConfig()})
+	ctx := context.Background()
+
+	authURL, err := manager.AuthCodeURL(ctx, "fake")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state := fake.authorize(authURL, nil)
+
+	identity, err := manager.Exchange(ctx, "fake", code, state)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if identity.Provider != "fake" || identity.Subject != "subject-1" || identity.Name != "Alice" {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+	if identity.VerifiedEmail() != "alice@example.com" {
+		t.Fatalf("VerifiedEmail = %q, want alice@example.com", identity.VerifiedEmail())
+	}
+}
+
+func TestOAuthManagerAuthCodeURLIsUnique(t *testing.T) {
+	fake := newFakeOIDCProvider(t)
+	manager, _ := newTestOAuthManager(t, map[string]config.OAuthProviderConfig{"fake": fake.providerConfig()})
+
+	first, err := manager.AuthCodeURL(context.Background(), "fake")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	second, err := manager.AuthCodeURL(context.Background(), "fake")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	q1, _ := url.Parse(first)
+	q2, _ := url.Parse(second)
+	for _, k := range []string{"state", "nonce", "code_challenge"} {
+		if q1.Query().Get(k) == q2.Query().Get(k) {
+			t.Errorf("%s reused across authorization requests", k)
+		}
+	}
+}
+
+func TestOAuthManagerStateReplay(t *testing.T) {
+	fake := newFakeOIDCProvider(t)
+	manager, _ := newTestOAuthManager(t, map[string]config.OAuthProviderConfig{"fake": fake.providerConfig()})
+	ctx := context.Background()
+
+	authURL, err := manager.AuthCodeURL(ctx, "fake")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state := fake.authorize(authURL, nil)
+
+	if _, err := manager.Exchange(ctx, "fake", code, state); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	// 攻击者截获回调后重放同一个 state，即使授权码仍有效也必须拒绝
+	code2, _ := fake.authorize(authURL, nil)
+	if _, err := manager.Exchange(ctx, "fake", code2, state); !errors.Is(err, domainErrors.ErrInvalidOAuthState) {
+		t.Fatalf("replayed state err = %v, want ErrInvalidOAuthState", err)
+	}
+}
+
+func TestOAuthManagerStateMismatch(t *testing.T) {
+	fake := newFakeOIDCProvider(t)
+	other := newFakeOIDCProvider(t)
+	manager, mr := newTestOAuthManager(t, map[string]config.OAuthProviderConfig{
+		"fake":  fake.providerConfig(),
+		"other": other.providerConfig(),
+	})
+	ctx := context.Background()
+
+	authURL, err := manager.AuthCodeURL(ctx, "fake")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state := fake.authorize(authURL, nil)
+
+	tests := []struct {
+		name     string
+		provider string
+		code     string
+		state    string
+	}{
+		{"unknown state", "fake", code, "forged-state"},
+		{"empty state", "fake", code, ""},
+		{"empty code", "fake", "", state},
+		{"state issued for another provider", "other", code, state},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := manager.Exchange(ctx, tt.provider, tt.code, tt.state); !errors.Is(err, domainErrors.ErrInvalidOAuthState) {
+				t.Fatalf("err = %v, want ErrInvalidOAuthState", err)
+			}
+		})
+	}
+
+	// 过期的 state 同样无效
+	authURL, err = manager.AuthCodeURL(ctx, "fake")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, state = fake.authorize(authURL, nil)
+	mr.FastForward(2 * time.Minute)
+	if _, err := manager.Exchange(ctx, "fake", code, state); !errors.Is(err, domainErrors.ErrInvalidOAuthState) {
+		t.Fatalf("expired state err = %v, want ErrInvalidOAuthState", err)
+	}
+}
+
+func TestOAuthManagerUnknownProvider(t *testing.T) {
+	manager, _ := newTestOAuthManager(t, map[string]config.OAuthProviderConfig{
+		// 未配置 client_id 的身份提供方视为未启用
+		"disabled": {Issuer: "https://accounts.example.com"},
+	})
+
+	if _, err := manager.AuthCodeURL(context.Background(), "disabled"); !errors.Is(err, domainErrors.ErrOAuthProviderNotFound) {
+		t.Fatalf("AuthCodeURL err = %v, want ErrOAuthProviderNotFound", err)
+	}
+	if _, err := manager.Exchange(context.Background(), "missing", "code", "state"); !errors.Is(err, domainErrors.ErrOAuthProviderNotFound) {
+		t.Fatalf("Exchange err = %v, want ErrOAuthProviderNotFound", err)
+	}
+}
+
+func TestOIDCProviderPKCEVerifier(t *testing.T) {
+	fake := newFakeOIDCProvider(t)
+	provider := fake.newProvider()
+	ctx := context.Background()
+
+	verifier := randomString(t)
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", codeChallengeS256(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	// 授权码被截获后，没有 code_verifier 无法换取令牌
+	code, _ := fake.authorize(authURL, nil)
+	if _, err := provider.Exchange(ctx, code, randomString(t), "nonce"); err == nil {
+		t.Fatal("Exchange succeeded with a wrong code_verifier")
+	}
+
+	code, _ = fake.authorize(authURL, nil)
+	claims, err := provider.Exchange(ctx, code, verifier, "nonce")
+	if err != nil {
+		t.Fatalf("Exchange with the right code_verifier: %v", err)
+	}
+	if claims.Subject != "subject-1" {
+		t.Fatalf("subject = %s", claims.Subject)
+	}
+}
+
+func TestCodeChallengeS256(t *testing.T) {
+	// RFC 7636 附录 B 的示例
+	got := codeChallengeS256("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Fatalf("code_challenge = %s, want %s", got, want)
+	}
+}
+
+func TestOIDCProviderVerifyIDToken(t *testing.T) {
+	fake := newFakeOIDCProvider(t)
+	provider := fake.newProvider()
+	ctx := context.Background()
+	const nonce = "expected-nonce"
+
+	valid := func(overrides jwt.MapClaims) jwt.MapClaims {
+		claims := fake.defaultClaims(nonce)
+		for k, v := range overrides {
+			if v == nil {
+				delete(claims, k)
+				continue
+			}
+			claims[k] = v
+		}
+		return claims
+	}
+
+	if _, err := provider.VerifyIDToken(ctx, fake.sign(valid(nil)), nonce); err != nil {
+		t.Fatalf("valid id token rejected: %v", err)
+	}
+
+	otherKey := newRSAKey(t)
+	signWith := func(key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return signed
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid(nil)).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("sign none: %v", err)
+	}
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, valid(nil)).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign hs256: %v", err)
+	}
+	tampered := fake.sign(valid(nil))
+	parts := strings.Split(tampered, ".")
+	payload, _ := json.Marshal(valid(jwt.MapClaims{"sub": "admin"}))
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+	tampered = strings.Join(parts, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+	}{
+		{"signed by another key", signWith(otherKey, fake.kid, valid(nil)), nonce},
+		{"unknown kid", signWith(otherKey, "unknown-kid", valid(nil)), nonce},
+		{"tampered payload", tampered, nonce},
+		{"alg none", unsigned, nonce},
+		{"alg hs256", hmac, nonce},
+		{"wrong issuer", fake.sign(valid(jwt.MapClaims{"iss": "https://evil.example.com"})), nonce},
+		{"wrong audience", fake.sign(valid(jwt.MapClaims{"aud": "another-client"})), nonce},
+		{"multiple audiences without azp", fake.sign(valid(jwt.MapClaims{"aud": []string{testClientID, "another-client"}})), nonce},
+		{"expired", fake.sign(valid(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})), nonce},
+		{"missing exp", fake.sign(valid(jwt.MapClaims{"exp": nil})), nonce},
+		{"issued in the future", fake.sign(valid(jwt.MapClaims{"iat": time.Now().Add(time.Hour).Unix()})), nonce},
+		{"missing sub", fake.sign(valid(jwt.MapClaims{"sub": nil})), nonce},
+		{"nonce mismatch", fake.sign(valid(nil)), "another-nonce"},
+		{"missing nonce", fake.sign(valid(jwt.MapClaims{"nonce": nil})), nonce},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := provider.VerifyIDToken(ctx, tt.token, tt.nonce); !errors.Is(err, domainErrors.ErrInvalidIDToken) {
+				t.Fatalf("err = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+
+	multiAud := valid(jwt.MapClaims{"aud": []string{testClientID, "another-client"}, "azp": testClientID})
+	if _, err := provider.VerifyIDToken(ctx, fake.sign(multiAud), nonce); err != nil {
+		t.Fatalf("multiple audiences with azp rejected: %v", err)
+	}
+}
+
+func TestOAuthIdentityVerifiedEmail(t *testing.T) {
+	fake := newFakeOIDCProvider(t)
+	manager, _ := newTestOAuthManager(t, map[string]config.OAuthProviderConfig{"fake": fake.providerConfig()})
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		emailVerified any
+		want          string
+	}{
+		{"verified", true, "alice@example.com"},
+		{"verified as string", "true", "alice@example.com"},
+		{"unverified", false, ""},
+		{"unverified as string", "false", ""},
+		{"missing", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authURL, err := manager.AuthCodeURL(ctx, "fake")
+			if err != nil {
+				t.Fatalf("AuthCodeURL: %v", err)
+			}
+			claims := jwt.MapClaims{"email_verified": tt.emailVerified}
+			code, state := fake.authorize(authURL, claims)
+
+			identity, err := manager.Exchange(ctx, "fake", code, state)
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if identity.Email != "alice@example.com" {
+				t.Fatalf("Email = %q", identity.Email)
+			}
+			// 只有验证过的邮箱才会用于关联已有账号
+			if got := identity.VerifiedEmail(); got != tt.want {
+				t.Fatalf("VerifiedEmail = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
+)
+
+// rotateScript 原子地轮换会话的刷新令牌
+// 返回 1 表示轮换成功，0 表示提交的刷新令牌已被轮换过（重放），-1 表示会话不存在或已撤销
+var rotateScript = redis.NewScript(`
+local current = redis.call("HGET", KEYS[1], "jti")
+if not current then
+	return -1
+end
+if current ~= ARGV[1] then
+	return 0
+end
+redis.call("HSET", KEYS[1], "jti", ARGV[2])
+redis.call("PEXPIRE", KEYS[1], ARGV[3])
+return 1
+`)
+
+// TokenPair 访问令牌和刷新令牌
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	SessionID    string
+}
+
+// RoleLoader 加载用户当前的角色，刷新令牌时调用，使角色变更在下一次刷新后生效
+type RoleLoader func(ctx context.Context, userID uint64) ([]string, error)
+
+// SessionManager 管理登录会话（刷新令牌族）
+// 每次登录创建一个会话，刷新时轮换刷新令牌；已轮换的刷新令牌再次使用时视为泄露，撤销整个会话
+type SessionManager struct {
+	client       redis.UniversalClient
+	jwtGenerator *JWTGenerator
+}
+
+func NewSessionManager(client redis.UniversalClient, jwtGenerator *JWTGenerator) *SessionManager {
+	return &SessionManager{
+		client:       client,
+		jwtGenerator: jwtGenerator,
+	}
+}
+
+// Issue 创建新会话并签发令牌，roles 写入访问令牌
+func (m *SessionManager) Issue(ctx context.Context, userID uint64, alternativeID string, roles []string) (*TokenPair, error) {
+	sessionID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+	jti, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	key := cache.SessionKey.Key(sessionID)
+	pipe := m.client.TxPipeline()
+	pipe.HSet(ctx, key,
+		"user_id", strconv.FormatUint(userID, 10),
+		"jti", jti,
+	)
+	pipe.PExpire(ctx, key, m.jwtGenerator.RefreshTokenExpiry())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("create session: %w", err)
+	}
+
+	return m.sign(userID, alternativeID, sessionID, jti, roles)
+}
+
+// Rotate 校验刷新令牌并轮换，返回新的令牌，访问令牌中的角色由 loadRoles 重新加载
+func (m *SessionManager) Rotate(ctx context.Context, refreshToken string, loadRoles RoleLoader) (*TokenPair, error) {
+	claims, err := m.jwtGenerator.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domainErrors.ErrInvalidRefreshToken, err)
+	}
+
+	// 在轮换之前加载角色，避免轮换成功后因加载失败导致客户端拿不到新令牌
+	roles, err := loadRoles(ctx, claims.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("load roles: %w", err)
+	}
+
+	newJTI, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
+
+	key := cache.SessionKey.Key(claims.SessionID)
+	ttl := m.jwtGenerator.RefreshTokenExpiry().Milliseconds()
+	result, err := rotateScript.Run(ctx, m.client, []string{key}, claims.ID, newJTI, ttl).Int()
+	if err != nil {
+		return nil, fmt.Errorf("rotate session: %w", err)
+	}
+
+	switch result {
+	case 1:
+		return m.sign(claims.UserId, claims.AlternativeID, claims.SessionID, newJTI, roles)
+	case 0:
+		// 旧的刷新令牌被再次使用，说明令牌可能已泄露，撤销整个会话
+		if err := m.Revoke(ctx, claims.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, domainErrors.ErrRefreshTokenReused
+	default:
+		return nil, domainErrors.ErrSessionRevoked
+	}
+}
+
+// Revoke 撤销会话，会话中的访问令牌和刷新令牌立即失效
+func (m *SessionManager) Revoke(ctx context.Context, sessionID string) error {
+	if err := m.client.Del(ctx, cache.SessionKey.Key(sessionID)).Err(); err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	return nil
+}
+
+// Authenticate 校验访问令牌，并确认所属会话未被撤销
+func (m *SessionManager) Authenticate(ctx context.Context, accessToken string) (*Claims, error) {
+	claims, err := m.jwtGenerator.ValidateAccessToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := m.client.Exists(ctx, cache.SessionKey.Key(claims.SessionID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("check session: %w", err)
+	}
+	if n == 0 {
+		return nil, domainErrors.ErrSessionRevoked
+	}
+
+	return claims, nil
+}
+
+func (m *SessionManager) sign(userID uint64, alternativeID, sessionID, jti string, roles []string) (*TokenPair, error) {
+	accessToken, err := m.jwtGenerator.GenerateAccessToken(userID, alternativeID, sessionID, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := m.jwtGenerator.GenerateRefreshToken(userID, alternativeID, sessionID, jti)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		SessionID:    sessionID,
+	}, nil
+}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/infrastructure/blockchain"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+const siweHeaderSuffix = " wants you to sign in with your Ethereum account:"
+
+var siweNonceRegex = regexp.MustCompile(`^[a-zA-Z0-9]{8,}$`)
+
+// SiweMessage EIP-4361 登录消息
+type SiweMessage struct {
+	Domain         string
+	Address        string
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// ParseSiweMessage 按 EIP-4361 格式解析登录消息
+func ParseSiweMessage(message string) (*SiweMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	if len(lines) < 2 || !strings.HasSuffix(lines[0], siweHeaderSuffix) {
+		return nil, errors.New("missing siwe header")
+	}
+
+	msg := &SiweMessage{
+		Domain:  strings.TrimSuffix(lines[0], siweHeaderSuffix),
+		Address: lines[1],
+	}
+	if i := strings.Index(msg.Domain, "://"); i >= 0 {
+		msg.Domain = msg.Domain[i+3:]
+	}
+	if msg.Domain == "" {
+		return nil, errors.New("missing domain")
+	}
+	if !blockchain.IsChecksumAddress(msg.Address) {
+		return nil, errors.New("address is not an EIP-55 checksum address")
+	}
+
+	// 地址之后为空行，可选的 statement 以及空行
+	i := 2
+	for i < len(lines) && lines[i] == "" {
+		i++
+	}
+	if i < len(lines) && !strings.HasPrefix(lines[i], "URI: ") {
+		msg.Statement = lines[i]
+		i++
+		for i < len(lines) && lines[i] == "" {
+			i++
+		}
+	}
+
+	fields := make(map[string]string)
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" {
+			continue
+		}
+		if line == "Resources:" {
+			for i++; i < len(lines) && strings.HasPrefix(lines[i], "- "); i++ {
+				msg.Resources = append(msg.Resources, strings.TrimPrefix(lines[i], "- "))
+			}
+			i--
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			return nil, fmt.Errorf("malformed line %q", line)
+		}
+		if _, dup := fields[key]; dup {
+			return nil, fmt.Errorf("duplicate field %q", key)
+		}
+		fields[key] = value
+	}
+
+	var err error
+	for key, value := range fields {
+		switch key {
+		case "URI":
+			msg.URI = value
+		case "Version":
+			msg.Version = value
+		case "Chain ID":
+			msg.ChainID, err = strconv.ParseInt(value, 10, 64)
+		case "Nonce":
+			msg.Nonce = value
+		case "Issued At":
+			msg.IssuedAt, err = time.Parse(time.RFC3339, value)
+		case "Expiration Time":
+			msg.ExpirationTime, err = parseOptionalTime(value)
+		case "Not Before":
+			msg.NotBefore, err = parseOptionalTime(value)
+		case "Request ID":
+			msg.RequestID = value
+		default:
+			return nil, fmt.Errorf("unknown field %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid field %q: %w", key, err)
+		}
+	}
+
+	switch {
+	case msg.URI == "":
+		return nil, errors.New("missing URI")
+	case msg.Version != "1":
+		return nil, fmt.Errorf("unsupported version %q", msg.Version)
+	case msg.ChainID == 0:
+		return nil, errors.New("missing chain id")
+	case !siweNonceRegex.MatchString(msg.Nonce):
+		return nil, errors.New("invalid nonce")
+	case msg.IssuedAt.IsZero():
+		return nil, errors.New("missing issued at")
+	}
+
+	return msg, nil
+}
+
+func parseOptionalTime(value string) (*time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SiweVerifier 签发一次性 nonce 并校验 EIP-4361 登录消息与 personal_sign 签名
+type SiweVerifier struct {
+	client  redis.UniversalClient
+	conf    config.SiweConfig
+	chainID int64
+}
+
+func NewSiweVerifier(client redis.UniversalClient, conf config.SiweConfig, chainID int64) *SiweVerifier {
+	return &SiweVerifier{
+		client:  client,
+		conf:    conf,
+		chainID: chainID,
+	}
+}
+
+// IssueNonce 生成 nonce 并保存到 Redis，nonce 只能使用一次
+func (v *SiweVerifier) IssueNonce(ctx context.Context) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	nonce := hex.EncodeToString(b)
+
+	if err := v.client.Set(ctx, cache.SiweNonceKey.Key(nonce), "1", v.conf.NonceTTL).Err(); err != nil {
+		return "", err
+	}
+
+	return nonce, nil
+}
+
+// Verify 校验登录消息与签名，成功后消费 nonce 并返回解析后的消息
+// 返回的错误为 domainErrors 中的 SIWE 相关错误或 Redis 错误
+func (v *SiweVerifier) Verify(ctx context.Context, message, signature string) (*SiweMessage, error) {
+	msg, err := ParseSiweMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domainErrors.ErrInvalidSiweMessage, err)
+	}
+
+	if msg.Domain != v.conf.Domain {
+		return nil, fmt.Errorf("%w: unexpected domain %q", domainErrors.ErrInvalidSiweMessage, msg.Domain)
+	}
+	if v.conf.URI != "" && !strings.HasPrefix(msg.URI, v.conf.URI) {
+		return nil, fmt.Errorf("%w: unexpected uri %q", domainErrors.ErrInvalidSiweMessage, msg.URI)
+	}
+	if msg.ChainID != v.chainID {
+		return nil, fmt.Errorf("%w: unexpected chain id %d", domainErrors.ErrInvalidSiweMessage, msg.ChainID)
+	}
+
+	now := time.Now()
+	if msg.IssuedAt.After(now.Add(v.conf.ClockSkew)) {
+		return nil, fmt.Errorf("%w: issued in the future", domainErrors.ErrInvalidSiweMessage)
+	}
+	if v.conf.MaxAge > 0 && now.Sub(msg.IssuedAt) > v.conf.MaxAge+v.conf.ClockSkew {
+		return nil, domainErrors.ErrSiweMessageExpired
+	}
+	if msg.ExpirationTime != nil && now.After(msg.ExpirationTime.Add(v.conf.ClockSkew)) {
+		return nil, domainErrors.ErrSiweMessageExpired
+	}
+	if msg.NotBefore != nil && now.Add(v.conf.ClockSkew).Before(*msg.NotBefore) {
+		return nil, fmt.Errorf("%w: not yet valid", domainErrors.ErrInvalidSiweMessage)
+	}
+
+	signer, err := blockchain.RecoverPersonalSign([]byte(message), signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domainErrors.ErrInvalidSignature, err)
+	}
+	if signer != msg.Address {
+		return nil, domainErrors.ErrInvalidSignature
+	}
+
+	// 签名校验通过后再原子地消费 nonce，防止重放
+	if err := v.client.GetDel(ctx, cache.SiweNonceKey.Key(msg.Nonce)).Err(); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, domainErrors.ErrInvalidNonce
+		}
+		return nil, err
+	}
+
+	return msg, nil
+}
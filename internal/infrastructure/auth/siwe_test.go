@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/infrastructure/blockchain"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+const (
+	testSiweKey     = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testSiweAddress = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	testSiweDomain  = "example.com"
+	testSiweChainID = 1
+)
+
+// siweMessage 按 EIP-4361 格式构造的测试消息
+type siweMessage struct {
+	domain         string
+	address        string
+	statement      string
+	uri            string
+	chainID        string
+	nonce          string
+	issuedAt       time.Time
+	expirationTime *time.Time
+	notBefore      *time.Time
+	resources      []string
+}
+
+func newSiweMessage(nonce string) siweMessage {
+	return siweMessage{
+		domain:    testSiweDomain,
+		address:   testSiweAddress,
+		statement: "Sign in to Example",
+		uri:       "https://example.com/login",
+		chainID:   "1",
+		nonce:     nonce,
+		issuedAt:  time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func (m siweMessage) String() string {
+	var b strings.Builder
+	b.WriteString(m.domain + siweHeaderSuffix + "\n")
+	b.WriteString(m.address + "\n\n")
+	if m.statement != "" {
+		b.WriteString(m.statement + "\n\n")
+	}
+	b.WriteString("URI: " + m.uri + "\n")
+	b.WriteString("Version: 1\n")
+	b.WriteString("Chain ID: " + m.chainID + "\n")
+	b.WriteString("Nonce: " + m.nonce + "\n")
+	b.WriteString("Issued At: " + m.issuedAt.Format(time.RFC3339))
+	if m.expirationTime != nil {
+		b.WriteString("\nExpiration Time: " + m.expirationTime.Format(time.RFC3339))
+	}
+	if m.notBefore != nil {
+		b.WriteString("\nNot Before: " + m.notBefore.Format(time.RFC3339))
+	}
+	if len(m.resources) > 0 {
+		b.WriteString("\nResources:")
+		for _, r := range m.resources {
+			b.WriteString("\n- " + r)
+		}
+	}
+	return b.String()
+}
+
+// signSiwe 使用测试私钥生成 personal_sign 签名 r||s||v
+func signSiwe(t *testing.T, message string) string {
+	t.Helper()
+
+	key, err := hex.DecodeString(testSiweKey)
+	if err != nil {
+		t.Fatalf("decode key: %v", err)
+	}
+	compact := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(key), blockchain.PersonalMessageHash([]byte(message)), false)
+
+	sig := make([]byte, 65)
+	copy(sig, compact[1:])
+	sig[64] = compact[0]
+	return "0x" + hex.EncodeToString(sig)
+}
+
+func TestParseSiweMessage(t *testing.T) {
+	issuedAt := time.Date(2021, 9, 30, 16, 25, 24, 0, time.UTC)
+	expiration := issuedAt.Add(time.Hour)
+
+	full := siweMessage{
+		domain:         "service.org",
+		address:        testSiweAddress,
+		statement:      "I accept the ServiceOrg Terms of Service: https://service.org/tos",
+		uri:            "https://service.org/login",
+		chainID:        "1",
+		nonce:          "32891757",
+		issuedAt:       issuedAt,
+		expirationTime: &expiration,
+		resources:      []string{"ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/", "https://example.com/my-web2-claim.json"},
+	}
+	msg, err := ParseSiweMessage(full.String())
+	if err != nil {
+		t.Fatalf("ParseSiweMessage: %v", err)
+	}
+	if msg.Domain != "service.org" || msg.Address != testSiweAddress || msg.Statement != full.statement ||
+		msg.URI != full.uri || msg.Version != "1" || msg.ChainID != 1 || msg.Nonce != "32891757" ||
+		!msg.IssuedAt.Equal(issuedAt) || msg.ExpirationTime == nil || !msg.ExpirationTime.Equal(expiration) ||
+		len(msg.Resources) != 2 || msg.Resources[1] != full.resources[1] {
+		t.Fatalf("parsed message = %+v", msg)
+	}
+
+	// 没有 statement，使用 CRLF 换行，domain 带协议前缀
+	noStatement := full
+	noStatement.statement = ""
+	noStatement.domain = "https://service.org"
+	msg, err = ParseSiweMessage(strings.ReplaceAll(noStatement.String(), "\n", "\r\n"))
+	if err != nil {
+		t.Fatalf("ParseSiweMessage without statement: %v", err)
+	}
+	if msg.Statement != "" || msg.Domain != "service.org" || msg.URI != full.uri {
+		t.Fatalf("parsed message without statement = %+v", msg)
+	}
+
+	invalid := []struct {
+		name    string
+		message string
+	}{
+		{"empty", ""},
+		{"missing header", strings.Replace(full.String(), siweHeaderSuffix, " wants you to sign in:", 1)},
+		{"lowercase address", strings.Replace(full.String(), testSiweAddress, strings.ToLower(testSiweAddress), 1)},
+		{"unknown field", full.String() + "\nFoo: bar"},
+		{"duplicate field", full.String() + "\nNonce: 12345678"},
+		{"malformed line", full.String() + "\nmalformed"},
+		{"unsupported version", strings.Replace(full.String(), "Version: 1", "Version: 2", 1)},
+		{"invalid chain id", strings.Replace(full.String(), "Chain ID: 1", "Chain ID: one", 1)},
+		{"short nonce", strings.Replace(full.String(), "Nonce: 32891757", "Nonce: 1234", 1)},
+		{"invalid issued at", strings.Replace(full.String(), "Issued At: 2021-09-30T16:25:24Z", "Issued At: yesterday", 1)},
+		{"missing uri", strings.Replace(full.String(), "URI: https://service.org/login\n", "", 1)},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSiweMessage(tt.message); err == nil {
+				t.Fatal("ParseSiweMessage succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestSiweVerifierVerify(t *testing.T) {
+	ctx := context.Background()
+	past := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	future := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name    string
+		modify  func(m *siweMessage)
+		sign    func(t *testing.T, message string) string
+		issue   bool
+		wantErr error
+	}{
+		{name: "valid", issue: true},
+		{name: "expired", issue: true, modify: func(m *siweMessage) { m.expirationTime = &past }, wantErr: domainErrors.ErrSiweMessageExpired},
+		{name: "older than max age", issue: true, modify: func(m *siweMessage) { m.issuedAt = m.issuedAt.Add(-time.Hour) }, wantErr: domainErrors.ErrSiweMessageExpired},
+		{name: "issued in the future", issue: true, modify: func(m *siweMessage) { m.issuedAt = future }, wantErr: domainErrors.ErrInvalidSiweMessage},
+		{name: "not yet valid", issue: true, modify: func(m *siweMessage) { m.notBefore = &future }, wantErr: domainErrors.ErrInvalidSiweMessage},
+		{name: "domain mismatch", issue: true, modify: func(m *siweMessage) { m.domain = "evil.example" }, wantErr: domainErrors.ErrInvalidSiweMessage},
+		{name: "uri mismatch", issue: true, modify: func(m *siweMessage) { m.uri = "https://evil.example/login" }, wantErr: domainErrors.ErrInvalidSiweMessage},
+		{name: "chain id mismatch", issue: true, modify: func(m *siweMessage) { m.chainID = "5" }, wantErr: domainErrors.ErrInvalidSiweMessage},
+		{name: "nonce not issued", wantErr: domainErrors.ErrInvalidNonce},
+		{
+			name:  "signed by another account",
+			issue: true,
+			sign: func(t *testing.T, message string) string {
+				return signSiwe(t, message+"\n")
+			},
+			wantErr: domainErrors.ErrInvalidSignature,
+		},
+		{
+			name:  "malformed signature",
+			issue: true,
+			sign: func(t *testing.T, message string) string {
+				return "0x1234"
+			},
+			wantErr: domainErrors.ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := newTestRedis(t)
+			verifier := NewSiweVerifier(client, config.SiweConfig{
+				Domain:    testSiweDomain,
+				URI:       "https://example.com",
+				NonceTTL:  time.Minute,
+				MaxAge:    10 * time.Minute,
+				ClockSkew: 5 * time.Second,
+			}, testSiweChainID)
+
+			nonce := "abcdef0123456789"
+			if tt.issue {
+				var err error
+				if nonce, err = verifier.IssueNonce(ctx); err != nil {
+					t.Fatalf("IssueNonce: %v", err)
+				}
+			}
+
+			m := newSiweMessage(nonce)
+			if tt.modify != nil {
+				tt.modify(&m)
+			}
+			message := m.String()
+			sign := signSiwe
+			if tt.sign != nil {
+				sign = tt.sign
+			}
+
+			msg, err := verifier.Verify(ctx, message, sign(t, message))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Verify err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if msg.Address != testSiweAddress {
+				t.Fatalf("address = %s, want %s", msg.Address, testSiweAddress)
+			}
+
+			// nonce 只能使用一次
+			if _, err := verifier.Verify(ctx, message, sign(t, message)); !errors.Is(err, domainErrors.ErrInvalidNonce) {
+				t.Fatalf("replayed Verify err = %v, want ErrInvalidNonce", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录 B 的 SHA-1 测试密钥 "12345678901234567890"
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// 附录 B 给出的是 8 位验证码，6 位验证码为其后 6 位
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},          // 94287082
+		{1111111109, "081804"},  // 07081804
+		{1111111111, "050471"},  // 14050471
+		{1234567890, "005924"},  // 89005924
+		{2000000000, "279037"},  // 69279037
+		{20000000000, "353130"}, // 65353130
+	}
+
+	for _, tt := range tests {
+		step := totpTimeStep(time.Unix(tt.unix, 0))
+		got, err := totpCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatalf("totpCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("totpCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+
+	// 密钥不区分大小写，允许 base32 填充
+	if got, _ := totpCode("gezdgnbvgy3tqojqgezdgnbvgy3tqojq====", 1); got != "287082" {
+		t.Errorf("lowercase padded secret = %s, want 287082", got)
+	}
+	if _, err := totpCode("not base32!", 1); err == nil {
+		t.Error("totpCode with invalid secret succeeded")
+	}
+}
+
+func TestMatchTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := totpTimeStep(now)
+
+	tests := []struct {
+		name   string
+		step   int64
+		wantOK bool
+	}{
+		{"current step", current, true},
+		{"previous step", current - 1, true},
+		{"next step", current + 1, true},
+		{"two steps ago", current - 2, false},
+		{"two steps ahead", current + 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := totpCode(rfc6238Secret, tt.step)
+			if err != nil {
+				t.Fatalf("totpCode: %v", err)
+			}
+			step, ok, err := matchTOTP(rfc6238Secret, code, now)
+			if err != nil {
+				t.Fatalf("matchTOTP: %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("matchTOTP ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && step != tt.step {
+				t.Fatalf("matched step = %d, want %d", step, tt.step)
+			}
+		})
+	}
+
+	if _, ok, _ := matchTOTP(rfc6238Secret, "12345", now); ok {
+		t.Fatal("matchTOTP accepted a 5 digit code")
+	}
+}
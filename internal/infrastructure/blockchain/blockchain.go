@@ -1,7 +1,16 @@
 package blockchain
 
-func Initialize() error {
-	// Initialize message queue-related services or configurations here
-	// For example, setting up RabbitMQ, Kafka, etc.
-	return nil
+import (
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/pkg/di"
+)
+
+func init() {
+	conf := di.Get[config.Config]()
+
+	client := NewClient(conf.Blockchain.Ethereum)
+
+	di.AddSingleton[*Client](func() (*Client, error) {
+		return client, nil
+	})
 }
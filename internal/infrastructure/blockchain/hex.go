@@ -0,0 +1,53 @@
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	addressRegex = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	hashRegex    = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+)
+
+// IsAddress 是否为合法的 EVM 地址
+func IsAddress(s string) bool {
+	return addressRegex.MatchString(s)
+}
+
+// IsHash 是否为合法的 32 字节哈希
+func IsHash(s string) bool {
+	return hashRegex.MatchString(s)
+}
+
+// ParseQuantity 解析 0x 前缀的十六进制数量
+func ParseQuantity(s string) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return 0, fmt.Errorf("invalid hex quantity %q", s)
+	}
+	return strconv.ParseUint(s[2:], 16, 64)
+}
+
+// ParseBigQuantity 解析 0x 前缀的十六进制大整数，空值 "0x" 视为 0
+func ParseBigQuantity(s string) (*big.Int, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return nil, fmt.Errorf("invalid hex quantity %q", s)
+	}
+	if s == "0x" {
+		return new(big.Int), nil
+	}
+
+	n, ok := new(big.Int).SetString(s[2:], 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid hex quantity %q", s)
+	}
+	return n, nil
+}
+
+// EncodeAddressArg 将地址编码为 32 字节 ABI 参数
+func EncodeAddressArg(address string) string {
+	return strings.Repeat("0", 24) + strings.ToLower(strings.TrimPrefix(address, "0x"))
+}
@@ -0,0 +1,220 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultRetryBackoff = 500 * time.Millisecond
+)
+
+var (
+	// ErrChainIDMismatch 节点的链ID与配置不一致
+	ErrChainIDMismatch = errors.New("chain id mismatch")
+	// ErrChainIDNotVerified 尚未成功校验节点的链ID
+	ErrChainIDNotVerified = errors.New("chain id not verified")
+)
+
+// RPCError JSON-RPC 节点返回的错误
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("json-rpc error %d: %s", e.Code, e.Message)
+}
+
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *RPCError       `json:"error"`
+}
+
+// retryableError 网络错误、429 和 5xx 响应可重试
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// Client EVM 节点 JSON-RPC 客户端
+type Client struct {
+	url          string
+	chainID      int64
+	maxRetries   int
+	retryBackoff time.Duration
+	httpClient   *http.Client
+
+	nextID atomic.Uint64
+
+	mu         sync.RWMutex
+	chainIDErr error // 最近一次链ID校验的结果
+}
+
+func NewClient(conf config.EthereumConfig) *Client {
+	if conf.Timeout <= 0 {
+		conf.Timeout = defaultTimeout
+	}
+	if conf.RetryBackoff <= 0 {
+		conf.RetryBackoff = defaultRetryBackoff
+	}
+
+	return &Client{
+		url:          conf.RPCURL,
+		chainID:      conf.ChainID,
+		maxRetries:   conf.MaxRetries,
+		retryBackoff: conf.RetryBackoff,
+		httpClient:   &http.Client{Timeout: conf.Timeout},
+		chainIDErr:   ErrChainIDNotVerified,
+	}
+}
+
+// ChainID 配置的链ID
+func (c *Client) ChainID() int64 {
+	return c.chainID
+}
+
+// VerifyChainID 校验节点的链ID与配置一致，防止连接到错误的网络，结果通过 ChainIDStatus 查询
+func (c *Client) VerifyChainID(ctx context.Context) error {
+	err := c.verifyChainID(ctx)
+
+	c.mu.Lock()
+	c.chainIDErr = err
+	c.mu.Unlock()
+
+	return err
+}
+
+// ChainIDStatus 返回最近一次链ID校验的结果，尚未校验时返回 ErrChainIDNotVerified
+func (c *Client) ChainIDStatus() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.chainIDErr
+}
+
+func (c *Client) verifyChainID(ctx context.Context) error {
+	var result string
+	if err := c.Call(ctx, &result, "eth_chainId"); err != nil {
+		return err
+	}
+
+	chainID, err := ParseQuantity(result)
+	if err != nil {
+		return err
+	}
+	if int64(chainID) != c.chainID {
+		return fmt.Errorf("%w: node %d, configured %d", ErrChainIDMismatch, chainID, c.chainID)
+	}
+
+	return nil
+}
+
+// Call 调用 JSON-RPC 方法并将结果解码到 result，可重试的错误按配置重试
+func (c *Client) Call(ctx context.Context, result any, method string, params ...any) error {
+	if params == nil {
+		params = []any{}
+	}
+
+	var err error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(c.retryBackoff * time.Duration(1<<(attempt-1)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		err = c.call(ctx, result, method, params)
+
+		var retryable *retryableError
+		if err == nil || !errors.As(err, &retryable) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (c *Client) call(ctx context.Context, result any, method string, params []any) error {
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return &retryableError{err: fmt.Errorf("%s: %w", method, err)}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &retryableError{err: fmt.Errorf("%s: read response: %w", method, err)}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return &retryableError{err: fmt.Errorf("%s: unexpected status %d", method, resp.StatusCode)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %d", method, resp.StatusCode)
+	}
+
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return fmt.Errorf("%s: decode response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(rpcResp.Result, result)
+}
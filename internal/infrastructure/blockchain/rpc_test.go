@@ -0,0 +1,403 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+const (
+	testAddress = "0x742d35Cc6634C0532925a3b844Bc454e4438f44e"
+	testToken   = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	testTxHash  = "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+)
+
+// rpcHandler 处理一次 JSON-RPC 调用，status 非 0 时直接返回该 HTTP 状态码
+type rpcHandler func(params []json.RawMessage) (result any, rpcErr *RPCError, status int)
+
+// rpcStub httptest 实现的 JSON-RPC 节点，按方法名分发并记录调用
+type rpcStub struct {
+	t        *testing.T
+	mu       sync.Mutex
+	handlers map[string]rpcHandler
+	calls    map[string]int
+	params   map[string][][]json.RawMessage
+}
+
+func newRPCStub(t *testing.T) (*rpcStub, *httptest.Server) {
+	t.Helper()
+
+	stub := &rpcStub{
+		t:        t,
+		handlers: make(map[string]rpcHandler),
+		calls:    make(map[string]int),
+		params:   make(map[string][][]json.RawMessage),
+	}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	return stub, server
+}
+
+func (s *rpcStub) handle(method string, h rpcHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = h
+}
+
+func (s *rpcStub) callCount(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+func (s *rpcStub) lastParams(method string) []json.RawMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	params := s.params[method]
+	if len(params) == 0 {
+		return nil
+	}
+	return params[len(params)-1]
+}
+
+func (s *rpcStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		JSONRPC string            `json:"jsonrpc"`
+		ID      uint64            `json:"id"`
+		Method  string            `json:"method"`
+		Params  []json.RawMessage `json:"params"`
+	}
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+		s.t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.JSONRPC != "2.0" {
+		s.t.Errorf("invalid JSON-RPC request: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.calls[req.Method]++
+	s.params[req.Method] = append(s.params[req.Method], req.Params)
+	h, ok := s.handlers[req.Method]
+	s.mu.Unlock()
+
+	resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+	if !ok {
+		resp["error"] = &RPCError{Code: -32601, Message: "the method " + req.Method + " does not exist"}
+	} else {
+		result, rpcErr, status := h(req.Params)
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		if rpcErr != nil {
+			resp["error"] = rpcErr
+		} else {
+			resp["result"] = result
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func newTestClient(url string, maxRetries int) *Client {
+	return NewClient(config.EthereumConfig{
+		RPCURL:       url,
+		ChainID:      1,
+		Timeout:      time.Second,
+		MaxRetries:   maxRetries,
+		RetryBackoff: time.Millisecond,
+	})
+}
+
+func result(v any) rpcHandler {
+	return func([]json.RawMessage) (any, *RPCError, int) {
+		return v, nil, 0
+	}
+}
+
+func decodeParam(t *testing.T, raw json.RawMessage, v any) {
+	t.Helper()
+	if err := json.Unmarshal(raw, v); err != nil {
+		t.Fatalf("decode param %s: %v", raw, err)
+	}
+}
+
+func TestClientGetBalance(t *testing.T) {
+	stub, server := newRPCStub(t)
+	stub.handle("eth_getBalance", result("0xde0b6b3a7640000"))
+	client := newTestClient(server.URL, 0)
+
+	var hex string
+	if err := client.Call(context.Background(), &hex, "eth_getBalance", testAddress, "latest"); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	balance, err := ParseBigQuantity(hex)
+	if err != nil {
+		t.Fatalf("ParseBigQuantity: %v", err)
+	}
+	if balance.String() != "1000000000000000000" {
+		t.Fatalf("balance = %s, want 1000000000000000000", balance)
+	}
+
+	params := stub.lastParams("eth_getBalance")
+	if len(params) != 2 {
+		t.Fatalf("params = %s, want [address, block]", params)
+	}
+	var address, block string
+	decodeParam(t, params[0], &address)
+	decodeParam(t, params[1], &block)
+	if address != testAddress || block != "latest" {
+		t.Fatalf("params = %q, %q", address, block)
+	}
+}
+
+func TestClientEthCall(t *testing.T) {
+	stub, server := newRPCStub(t)
+	// 32 字节的 uint256，值为 1234567
+	stub.handle("eth_call", result("0x000000000000000000000000000000000000000000000000000000000012d687"))
+	client := newTestClient(server.URL, 0)
+
+	call := map[string]string{
+		"to":   testToken,
+		"data": "0x70a08231" + EncodeAddressArg(testAddress),
+	}
+	var hex string
+	if err := client.Call(context.Background(), &hex, "eth_call", call, "latest"); err != nil {
+		t.Fatalf("Call: %v", err)
+	}
+	balance, err := ParseBigQuantity(hex)
+	if err != nil {
+		t.Fatalf("ParseBigQuantity: %v", err)
+	}
+	if balance.Int64() != 1234567 {
+		t.Fatalf("balance = %s, want 1234567", balance)
+	}
+
+	var sent map[string]string
+	decodeParam(t, stub.lastParams("eth_call")[0], &sent)
+	wantData := "0x70a08231000000000000000000000000742d35cc6634c0532925a3b844bc454e4438f44e"
+	if sent["to"] != testToken || sent["data"] != wantData {
+		t.Fatalf("call = %v, want data %s", sent, wantData)
+	}
+}
+
+func TestClientReceiptPolling(t *testing.T) {
+	stub, server := newRPCStub(t)
+	// 前两次查询交易仍在待打包，返回 null
+	stub.handle("eth_getTransactionReceipt", func(params []json.RawMessage) (any, *RPCError, int) {
+		var hash string
+		decodeParam(t, params[0], &hash)
+		if hash != testTxHash {
+			t.Errorf("tx hash = %s", hash)
+		}
+		if stub.callCount("eth_getTransactionReceipt") <= 2 {
+			return nil, nil, 0
+		}
+		return map[string]any{
+			"transactionHash": testTxHash,
+			"blockNumber":     "0x10",
+			"gasUsed":         "0x5208",
+			"status":          "0x1",
+		}, nil, 0
+	})
+	client := newTestClient(server.URL, 0)
+
+	type receipt struct {
+		TransactionHash string `json:"transactionHash"`
+		BlockNumber     string `json:"blockNumber"`
+		GasUsed         string `json:"gasUsed"`
+		Status          string `json:"status"`
+	}
+
+	var got *receipt
+	for polls := 1; got == nil; polls++ {
+		if polls > 5 {
+			t.Fatal("receipt not available after 5 polls")
+		}
+		if err := client.Call(context.Background(), &got, "eth_getTransactionReceipt", testTxHash); err != nil {
+			t.Fatalf("Call: %v", err)
+		}
+	}
+
+	if n := stub.callCount("eth_getTransactionReceipt"); n != 3 {
+		t.Fatalf("polled %d times, want 3", n)
+	}
+	if got.TransactionHash != testTxHash {
+		t.Fatalf("tx hash = %s", got.TransactionHash)
+	}
+	if block, _ := ParseQuantity(got.BlockNumber); block != 16 {
+		t.Fatalf("block = %d, want 16", block)
+	}
+	if status, _ := ParseQuantity(got.Status); status != 1 {
+		t.Fatalf("status = %d, want 1", status)
+	}
+}
+
+func TestClientRetriesRetryableStatus(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable} {
+		stub, server := newRPCStub(t)
+		stub.handle("eth_blockNumber", func([]json.RawMessage) (any, *RPCError, int) {
+			if stub.callCount("eth_blockNumber") <= 2 {
+				return nil, nil, status
+			}
+			return "0x1b4", nil, 0
+		})
+		client := newTestClient(server.URL, 3)
+
+		var hex string
+		if err := client.Call(context.Background(), &hex, "eth_blockNumber"); err != nil {
+			t.Fatalf("status %d: Call: %v", status, err)
+		}
+		if hex != "0x1b4" {
+			t.Fatalf("status %d: result = %s", status, hex)
+		}
+		if n := stub.callCount("eth_blockNumber"); n != 3 {
+			t.Fatalf("status %d: attempts = %d, want 3", status, n)
+		}
+	}
+}
+
+func TestClientRetryExhausted(t *testing.T) {
+	stub, server := newRPCStub(t)
+	stub.handle("eth_blockNumber", func([]json.RawMessage) (any, *RPCError, int) {
+		return nil, nil, http.StatusBadGateway
+	})
+	client := newTestClient(server.URL, 2)
+
+	err := client.Call(context.Background(), nil, "eth_blockNumber")
+	if err == nil {
+		t.Fatal("Call succeeded, want error")
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		t.Fatalf("err = %v, want transport error", err)
+	}
+	if n := stub.callCount("eth_blockNumber"); n != 3 {
+		t.Fatalf("attempts = %d, want 3 (1 + 2 retries)", n)
+	}
+}
+
+func TestClientRPCErrorNotRetried(t *testing.T) {
+	stub, server := newRPCStub(t)
+	stub.handle("eth_call", func([]json.RawMessage) (any, *RPCError, int) {
+		return nil, &RPCError{Code: 3, Message: "execution reverted"}, 0
+	})
+	client := newTestClient(server.URL, 3)
+
+	err := client.Call(context.Background(), nil, "eth_call", map[string]string{"to": testToken}, "latest")
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("err = %v, want *RPCError", err)
+	}
+	if rpcErr.Code != 3 || rpcErr.Message != "execution reverted" {
+		t.Fatalf("rpc error = %+v", rpcErr)
+	}
+	if n := stub.callCount("eth_call"); n != 1 {
+		t.Fatalf("attempts = %d, want 1", n)
+	}
+}
+
+func TestClientUnknownMethod(t *testing.T) {
+	_, server := newRPCStub(t)
+	client := newTestClient(server.URL, 3)
+
+	err := client.Call(context.Background(), nil, "eth_unknown")
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != -32601 {
+		t.Fatalf("err = %v, want method not found", err)
+	}
+}
+
+func TestClientClientErrorNotRetried(t *testing.T) {
+	stub, server := newRPCStub(t)
+	stub.handle("eth_blockNumber", func([]json.RawMessage) (any, *RPCError, int) {
+		return nil, nil, http.StatusUnauthorized
+	})
+	client := newTestClient(server.URL, 3)
+
+	if err := client.Call(context.Background(), nil, "eth_blockNumber"); err == nil {
+		t.Fatal("Call succeeded, want error")
+	}
+	if n := stub.callCount("eth_blockNumber"); n != 1 {
+		t.Fatalf("attempts = %d, want 1", n)
+	}
+}
+
+func TestClientContextCanceledDuringBackoff(t *testing.T) {
+	stub, server := newRPCStub(t)
+	stub.handle("eth_blockNumber", func([]json.RawMessage) (any, *RPCError, int) {
+		return nil, nil, http.StatusServiceUnavailable
+	})
+	client := NewClient(config.EthereumConfig{
+		RPCURL:       server.URL,
+		ChainID:      1,
+		MaxRetries:   3,
+		RetryBackoff: time.Minute,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := client.Call(ctx, nil, "eth_blockNumber"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if n := stub.callCount("eth_blockNumber"); n != 1 {
+		t.Fatalf("attempts = %d, want 1", n)
+	}
+}
+
+func TestClientVerifyChainID(t *testing.T) {
+	stub, server := newRPCStub(t)
+	stub.handle("eth_chainId", result("0x1"))
+
+	if err := newTestClient(server.URL, 0).VerifyChainID(context.Background()); err != nil {
+		t.Fatalf("VerifyChainID: %v", err)
+	}
+
+	stub.handle("eth_chainId", result("0xaa36a7"))
+	client := newTestClient(server.URL, 0)
+	if err := client.VerifyChainID(context.Background()); !errors.Is(err, ErrChainIDMismatch) {
+		t.Fatalf("VerifyChainID on a Sepolia node with chain_id 1 = %v, want ErrChainIDMismatch", err)
+	}
+	if err := client.ChainIDStatus(); !errors.Is(err, ErrChainIDMismatch) {
+		t.Fatalf("ChainIDStatus = %v, want ErrChainIDMismatch", err)
+	}
+}
+
+func TestClientChainIDStatus(t *testing.T) {
+	stub, server := newRPCStub(t)
+	client := newTestClient(server.URL, 0)
+
+	if err := client.ChainIDStatus(); !errors.Is(err, ErrChainIDNotVerified) {
+		t.Fatalf("ChainIDStatus before verification = %v, want ErrChainIDNotVerified", err)
+	}
+
+	// 节点不可用时记录失败原因，恢复后重新校验成功
+	stub.handle("eth_chainId", func([]json.RawMessage) (any, *RPCError, int) {
+		return nil, nil, http.StatusBadGateway
+	})
+	if err := client.VerifyChainID(context.Background()); err == nil {
+		t.Fatal("VerifyChainID succeeded while the node is unavailable")
+	}
+	if err := client.ChainIDStatus(); err == nil || errors.Is(err, ErrChainIDMismatch) {
+		t.Fatalf("ChainIDStatus while unavailable = %v", err)
+	}
+
+	stub.handle("eth_chainId", result("0x1"))
+	if err := client.VerifyChainID(context.Background()); err != nil {
+		t.Fatalf("VerifyChainID after recovery: %v", err)
+	}
+	if err := client.ChainIDStatus(); err != nil {
+		t.Fatalf("ChainIDStatus after recovery = %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"golang.org/x/crypto/sha3"
+)
+
+// ErrInvalidSignature 签名格式错误或无法恢复公钥
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Keccak256 计算 Keccak-256 哈希（以太坊使用的哈希算法，非标准 SHA3-256）
+func Keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// PersonalMessageHash 计算 EIP-191 personal_sign 的消息哈希
+// keccak256("\x19Ethereum Signed Message:\n" + len(message) + message)
+func PersonalMessageHash(message []byte) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))
+	return Keccak256([]byte(prefix), message)
+}
+
+// RecoverPersonalSign 从 personal_sign 签名中恢复签名者地址（EIP-55 校验和格式）
+// signature 为 0x 前缀的 65 字节 r||s||v，v 取值 27/28 或 0/1
+func RecoverPersonalSign(message []byte, signature string) (string, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil || len(sig) != 65 {
+		return "", ErrInvalidSignature
+	}
+
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return "", ErrInvalidSignature
+	}
+
+	// 转换为 secp256k1 紧凑签名格式 <27+v><r><s>
+	compact := make([]byte, 65)
+	compact[0] = 27 + v
+	copy(compact[1:], sig[:64])
+
+	pubKey, _, err := ecdsa.RecoverCompact(compact, PersonalMessageHash(message))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	// 地址为未压缩公钥（去掉 0x04 前缀）哈希的后 20 字节
+	address := Keccak256(pubKey.SerializeUncompressed()[1:])[12:]
+	return ToChecksumAddress("0x" + hex.EncodeToString(address)), nil
+}
+
+// ToChecksumAddress 将地址转换为 EIP-55 校验和格式，调用方需保证地址合法
+func ToChecksumAddress(address string) string {
+	lower := strings.ToLower(strings.TrimPrefix(address, "0x"))
+	hash := hex.EncodeToString(Keccak256([]byte(lower)))
+
+	result := make([]byte, len(lower))
+	for i := 0; i < len(lower); i++ {
+		c := lower[i]
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			c -= 'a' - 'A'
+		}
+		result[i] = c
+	}
+	return "0x" + string(result)
+}
+
+// IsChecksumAddress 是否为合法的 EIP-55 校验和地址
+func IsChecksumAddress(address string) bool {
+	return IsAddress(address) && ToChecksumAddress(address) == address
+}
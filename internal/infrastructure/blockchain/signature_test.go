@@ -0,0 +1,147 @@
+package blockchain
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// web3.js 文档中 accounts.sign 的示例账户和签名
+const (
+	testSignerKey     = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testSignerAddress = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+	testSignedMessage = "Some data"
+	testSignature     = "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c"
+)
+
+// signPersonal 使用私钥生成 personal_sign 签名 r||s||v，v 为 27/28
+func signPersonal(t *testing.T, keyHex string, message []byte) string {
+	t.Helper()
+
+	key, err := hex.DecodeString(keyHex)
+	if err != nil {
+		t.Fatalf("decode key: %v", err)
+	}
+	compact := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(key), PersonalMessageHash(message), false)
+
+	sig := make([]byte, 65)
+	copy(sig, compact[1:])
+	sig[64] = compact[0]
+	return "0x" + hex.EncodeToString(sig)
+}
+
+// withV 替换签名的 v 字节
+func withV(signature string, v byte) string {
+	sig, _ := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	sig[64] = v
+	return "0x" + hex.EncodeToString(sig)
+}
+
+func TestPersonalMessageHash(t *testing.T) {
+	got := hex.EncodeToString(PersonalMessageHash([]byte(testSignedMessage)))
+	if want := "1da44b586eb0729ff70a73c326926f6ed5a25f5b056e7f47fbc6e58d86871655"; got != want {
+		t.Fatalf("PersonalMessageHash = %s, want %s", got, want)
+	}
+}
+
+func TestRecoverPersonalSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		signature string
+		want      string
+		wantErr   bool
+	}{
+		{"known signature v=28", testSignedMessage, testSignature, testSignerAddress, false},
+		{"known signature v=1", testSignedMessage, withV(testSignature, 1), testSignerAddress, false},
+		{"without 0x prefix", testSignedMessage, strings.TrimPrefix(testSignature, "0x"), testSignerAddress, false},
+		{"other message recovers other signer", "Other data", testSignature, "", false},
+		{"v=29", testSignedMessage, withV(testSignature, 29), "", true},
+		{"v=2", testSignedMessage, withV(testSignature, 2), "", true},
+		{"too short", testSignedMessage, testSignature[:len(testSignature)-2], "", true},
+		{"not hex", testSignedMessage, "0x" + strings.Repeat("zz", 65), "", true},
+		{"zero r and s", testSignedMessage, "0x" + strings.Repeat("00", 64) + "1b", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RecoverPersonalSign([]byte(tt.message), tt.signature)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSignature) {
+					t.Fatalf("err = %v, want ErrInvalidSignature", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RecoverPersonalSign: %v", err)
+			}
+			if tt.want == "" {
+				if got == testSignerAddress {
+					t.Fatal("signature recovered the signer for a different message")
+				}
+				return
+			}
+			if got != tt.want {
+				t.Fatalf("signer = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecoverPersonalSignRecoveryIDs(t *testing.T) {
+	// 不同消息的签名恢复ID不同，覆盖 v=27/28 及对应的 v=0/1
+	seen := make(map[byte]bool)
+	for i := 0; len(seen) < 2 && i < 64; i++ {
+		message := []byte("message " + string(rune('a'+i)))
+		signature := signPersonal(t, testSignerKey, message)
+		sig, _ := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+		v := sig[64]
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+
+		for _, candidate := range []byte{v, v - 27} {
+			got, err := RecoverPersonalSign(message, withV(signature, candidate))
+			if err != nil {
+				t.Fatalf("v=%d: %v", candidate, err)
+			}
+			if got != testSignerAddress {
+				t.Fatalf("v=%d: signer = %s, want %s", candidate, got, testSignerAddress)
+			}
+		}
+
+		// 翻转恢复ID会恢复出其他地址
+		flipped := 27 + (1 - (v - 27))
+		if got, err := RecoverPersonalSign(message, withV(signature, flipped)); err == nil && got == testSignerAddress {
+			t.Fatalf("v=%d recovered the signer", flipped)
+		}
+	}
+	if !seen[27] || !seen[28] {
+		t.Fatalf("recovery ids covered = %v, want 27 and 28", seen)
+	}
+}
+
+func TestChecksumAddress(t *testing.T) {
+	// EIP-55 规范中的示例
+	for _, address := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	} {
+		if got := ToChecksumAddress(strings.ToLower(address)); got != address {
+			t.Errorf("ToChecksumAddress(%s) = %s", strings.ToLower(address), got)
+		}
+		if !IsChecksumAddress(address) {
+			t.Errorf("IsChecksumAddress(%s) = false", address)
+		}
+		if IsChecksumAddress(strings.ToLower(address)) {
+			t.Errorf("IsChecksumAddress(%s) = true for lowercase", strings.ToLower(address))
+		}
+	}
+}
@@ -1,28 +1,68 @@
 package cache
 
-import "time"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+// ErrCacheMiss is returned by Get when the key does not exist.
+var ErrCacheMiss = errors.New("cache: key not found")
 
 type CacheContext interface {
 	// Get retrieves the value associated with the given key.
-	Get(key string) (string, error)
+	Get(ctx context.Context, key string) (string, error)
 
 	// Set sets the value for the given key.
-	Set(key string, value string) error
+	Set(ctx context.Context, key string, value string) error
 
 	// SetWithTTL sets the value with a time-to-live duration.
-	SetWithTTL(key string, value string, ttl time.Duration) error
+	SetWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error
 
 	// Delete removes the value associated with the key.
-	Delete(key string) error
+	Delete(ctx context.Context, key string) error
 
 	// Exists checks if the key exists in the cache.
-	Exists(key string) (bool, error)
+	Exists(ctx context.Context, key string) (bool, error)
 
 	// Incr atomically increments the integer value of a key by 1.
-	Incr(key string) (int64, error)
+	Incr(ctx context.Context, key string) (int64, error)
 
 	// Decr atomically decrements the integer value of a key by 1.
-	Decr(key string) (int64, error)
+	Decr(ctx context.Context, key string) (int64, error)
+
+	// IncrWithTTL atomically increments the integer value of a key by 1,
+	// setting the ttl when the key is created so that the counter expires as a fixed window.
+	IncrWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error)
+
+	// TTL returns the remaining time-to-live of a key, or 0 if the key does not exist or has no expiry.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// newCacheContext 按 cache.driver 创建 CacheContext 实现，未配置时使用 Redis
+func newCacheContext(conf config.CacheConfig, client redis.UniversalClient) (CacheContext, error) {
+	switch conf.Driver {
+	case "", "redis":
+		return NewRedisCache(client), nil
+	case "memory":
+		return NewMemoryCache(conf.Local.MaxEntries), nil
+	case "layered":
+		return NewLayeredCache(client, NewMemoryCache(conf.Local.MaxEntries), conf.Local.TTL, conf.Local.InvalidationChannel)
+	default:
+		return nil, fmt.Errorf("unsupported cache driver: %s", conf.Driver)
+	}
+}
+
+// newLocker memory 驱动使用进程内锁，其余驱动使用 Redis 锁
+func newLocker(conf config.CacheConfig, client redis.UniversalClient) Locker {
+	if conf.Driver == "memory" {
+		return NewMemoryLocker()
+	}
+	return NewRedisLocker(client)
 }
 
 func Initialize() error {
@@ -1 +1,135 @@
 package cache
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+var (
+	// keyPrefix 所有键的全局前缀，取自 cache.redis.prefix，多个服务共用同一 Redis 时用于隔离
+	keyPrefix string
+
+	registryMu sync.Mutex
+	// keyRegistry 已注册的键命名空间，避免不同模块使用同一命名空间造成冲突
+	keyRegistry = make(map[string]struct{})
+)
+
+// SetKeyPrefix 设置全局键前缀，需要在使用任何键之前调用
+func SetKeyPrefix(prefix string) {
+	keyPrefix = prefix
+}
+
+// KeyBuilder 类型化的键构造器，键格式为 前缀 + 命名空间 + ":" + 参数
+type KeyBuilder[T any] struct {
+	namespace string
+	format    func(T) string
+}
+
+// NewKey 注册命名空间并返回键构造器，命名空间重复注册时 panic
+func NewKey[T any](namespace string, format func(T) string) KeyBuilder[T] {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := keyRegistry[namespace]; exists {
+		panic(fmt.Sprintf("cache: key namespace %q already registered", namespace))
+	}
+	keyRegistry[namespace] = struct{}{}
+
+	return KeyBuilder[T]{namespace: namespace, format: format}
+}
+
+// NewStringKey 注册以字符串为参数的键
+func NewStringKey(namespace string) KeyBuilder[string] {
+	return NewKey(namespace, func(s string) string { return s })
+}
+
+// Key 构造带全局前缀的完整键
+func (b KeyBuilder[T]) Key(v T) string {
+	return keyPrefix + b.namespace + ":" + b.format(v)
+}
+
+// Namespace 返回键的命名空间（不含全局前缀）
+func (b KeyBuilder[T]) Namespace() string {
+	return b.namespace
+}
+
+// Namespaces 返回所有已注册的命名空间
+func Namespaces() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	namespaces := make([]string, 0, len(keyRegistry))
+	for namespace := range keyRegistry {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// TOTPStep 用户的 TOTP 时间步
+type TOTPStep struct {
+	UserID uint64
+	Step   int64
+}
+
+var (
+	// SiweNonceKey Sign-In with Ethereum 一次性 nonce
+	SiweNonceKey = NewStringKey("auth:siwe:nonce")
+
+	// OAuthStateKey OAuth2 授权请求的 state，值为 nonce 和 PKCE code_verifier
+	OAuthStateKey = NewStringKey("auth:oauth:state")
+
+	// SessionKey 登录会话（刷新令牌族），保存用户ID和当前有效的刷新令牌 jti
+	SessionKey = NewStringKey("auth:session")
+
+	// MfaChallengeKey 密码校验通过后等待第二因素的登录挑战，保存用户ID和失败次数
+	MfaChallengeKey = NewStringKey("auth:mfa:challenge")
+
+	// TOTPUsedKey 已使用过的 TOTP 时间步，防止同一验证码被重放
+	TOTPUsedKey = NewKey("auth:mfa:totp_used", func(s TOTPStep) string {
+		return strconv.FormatUint(s.UserID, 10) + ":" + strconv.FormatInt(s.Step, 10)
+	})
+
+	// MfaFailuresKey 关闭多因素认证等账户操作的验证码错误计数，达到上限后在过期前拒绝校验
+	MfaFailuresKey = NewKey("auth:mfa:failures", func(userID uint64) string {
+		return strconv.FormatUint(userID, 10)
+	})
+
+	// LoginFailuresUserKey 用户名的登录失败计数，在失败窗口内累计
+	LoginFailuresUserKey = NewStringKey("auth:login:failures:user")
+
+	// LoginFailuresIPKey IP的登录失败计数，在失败窗口内累计
+	LoginFailuresIPKey = NewStringKey("auth:login:failures:ip")
+
+	// LoginLockUserKey 用户名的登录锁定标记，过期后自动解锁
+	LoginLockUserKey = NewStringKey("auth:login:lock:user")
+
+	// LoginLockIPKey IP的登录锁定标记，过期后自动解锁
+	LoginLockIPKey = NewStringKey("auth:login:lock:ip")
+
+	// UserByIDKey 按ID缓存的用户数据
+	UserByIDKey = NewKey("repo:user:id", func(id uint64) string {
+		return strconv.FormatUint(id, 10)
+	})
+
+	// UserByUsernameKey 用户名到用户ID的索引
+	UserByUsernameKey = NewStringKey("repo:user:username")
+
+	// LockKey 分布式锁，锁名放在 hash tag 中，与栅栏计数器位于集群的同一槽位
+	LockKey = NewKey("lock", func(name string) string {
+		return "{" + name + "}"
+	})
+
+	// LockFenceKey 分布式锁的栅栏计数器
+	LockFenceKey = NewKey("lock:fence", func(name string) string {
+		return "{" + name + "}"
+	})
+
+	// RateLimitKey 接口限流状态，参数为路由组和限流对象
+	RateLimitKey = NewStringKey("ratelimit")
+
+	// IdempotencyKey 幂等请求的指纹和响应，参数为请求方、接口和 Idempotency-Key
+	IdempotencyKey = NewStringKey("idempotency")
+)
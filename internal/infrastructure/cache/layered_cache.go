@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	defaultLocalCacheTTL       = time.Minute
+	defaultInvalidationChannel = "cache:invalidation"
+)
+
+// 保证对接口实现
+var _ CacheContext = (*LayeredCache)(nil)
+
+// LayeredCache 两级缓存：读操作优先命中本地缓存，未命中时读取 Redis 并回填本地
+// 写操作先写 Redis，再清除本地条目，并通过 Redis pub/sub 通知其他实例清除
+// 失效消息可能在断线期间丢失，本地条目的存活时间不超过 localTTL，以此限制不一致的时长
+type LayeredCache struct {
+	local    *MemoryCache
+	remote   *RedisCache
+	client   redis.UniversalClient
+	localTTL time.Duration
+
+	channel    string
+	instanceID string
+	pubsub     *redis.PubSub
+	closeOnce  sync.Once
+}
+
+func NewLayeredCache(client redis.UniversalClient, local *MemoryCache, localTTL time.Duration, channel string) (*LayeredCache, error) {
+	if localTTL <= 0 {
+		localTTL = defaultLocalCacheTTL
+	}
+	if channel == "" {
+		channel = defaultInvalidationChannel
+	}
+
+	instanceID := make([]byte, 8)
+	if _, err := rand.Read(instanceID); err != nil {
+		return nil, err
+	}
+
+	c := &LayeredCache{
+		local:      local,
+		remote:     NewRedisCache(client),
+		client:     client,
+		localTTL:   localTTL,
+		channel:    keyPrefix + channel,
+		instanceID: hex.EncodeToString(instanceID),
+	}
+
+	// 订阅确认后再返回，避免启动期间的失效消息丢失
+	c.pubsub = client.Subscribe(context.Background(), c.channel)
+	if _, err := c.pubsub.Receive(context.Background()); err != nil {
+		_ = c.pubsub.Close()
+		return nil, err
+	}
+	go c.listen()
+
+	return c, nil
+}
+
+func (c *LayeredCache) Get(ctx context.Context, key string) (string, error) {
+	if value, err := c.local.Get(ctx, key); err == nil {
+		return value, nil
+	}
+
+	value, ttl, err := c.remote.getWithTTL(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	// 本地条目不晚于 Redis 中的键过期
+	if ttl <= 0 || ttl > c.localTTL {
+		ttl = c.localTTL
+	}
+	_ = c.local.SetWithTTL(ctx, key, value, ttl)
+
+	return value, nil
+}
+
+func (c *LayeredCache) Set(ctx context.Context, key string, value string) error {
+	if err := c.remote.Set(ctx, key, value); err != nil {
+		return err
+	}
+	return c.invalidate(ctx, key)
+}
+
+func (c *LayeredCache) SetWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error {
+	if err := c.remote.SetWithTTL(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	return c.invalidate(ctx, key)
+}
+
+func (c *LayeredCache) Delete(ctx context.Context, key string) error {
+	if err := c.remote.Delete(ctx, key); err != nil {
+		return err
+	}
+	return c.invalidate(ctx, key)
+}
+
+func (c *LayeredCache) Exists(ctx context.Context, key string) (bool, error) {
+	if ok, _ := c.local.Exists(ctx, key); ok {
+		return true, nil
+	}
+	return c.remote.Exists(ctx, key)
+}
+
+// Incr 计数器只在 Redis 中维护，保证多实例计数一致
+func (c *LayeredCache) Incr(ctx context.Context, key string) (int64, error) {
+	n, err := c.remote.Incr(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return n, c.invalidate(ctx, key)
+}
+
+func (c *LayeredCache) Decr(ctx context.Context, key string) (int64, error) {
+	n, err := c.remote.Decr(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return n, c.invalidate(ctx, key)
+}
+
+func (c *LayeredCache) IncrWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	n, err := c.remote.IncrWithTTL(ctx, key, ttl)
+	if err != nil {
+		return 0, err
+	}
+	return n, c.invalidate(ctx, key)
+}
+
+func (c *LayeredCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return c.remote.TTL(ctx, key)
+}
+
+// Close 取消订阅失效通知
+func (c *LayeredCache) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		err = c.pubsub.Close()
+	})
+	return err
+}
+
+// invalidate 清除本地条目并通知其他实例
+func (c *LayeredCache) invalidate(ctx context.Context, key string) error {
+	_ = c.local.Delete(ctx, key)
+	return c.client.Publish(ctx, c.channel, c.instanceID+"|"+key).Err()
+}
+
+// listen 处理其他实例发布的失效通知，断线重连由 go-redis 自动完成
+func (c *LayeredCache) listen() {
+	for msg := range c.pubsub.Channel() {
+		origin, key, ok := strings.Cut(msg.Payload, "|")
+		if !ok || origin == c.instanceID {
+			continue
+		}
+		_ = c.local.Delete(context.Background(), key)
+	}
+}
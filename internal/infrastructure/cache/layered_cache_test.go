@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, redis.UniversalClient) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, client
+}
+
+func newTestLayeredCache(t *testing.T, client redis.UniversalClient, localTTL time.Duration) *LayeredCache {
+	t.Helper()
+
+	c, err := NewLayeredCache(client, NewMemoryCache(100), localTTL, "test:invalidation")
+	if err != nil {
+		t.Fatalf("NewLayeredCache: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// waitLocalEvicted 等待失效通知清除本地条目
+func waitLocalEvicted(t *testing.T, c *LayeredCache, key string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if ok, _ := c.local.Exists(context.Background(), key); !ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("local entry %s was not invalidated", key)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLayeredCacheReadThrough(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newTestRedis(t)
+	c := newTestLayeredCache(t, client, time.Minute)
+
+	assertMiss(t, c, "k")
+	if ok, _ := c.local.Exists(ctx, "k"); ok {
+		t.Fatal("miss was cached locally")
+	}
+
+	mr.Set("k", "v1")
+	assertCached(t, c, "k", "v1")
+
+	// 绕过 LayeredCache 修改 Redis，本地条目仍然命中
+	mr.Set("k", "v2")
+	assertCached(t, c, "k", "v1")
+}
+
+func TestLayeredCacheWriteInvalidatesLocal(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newTestRedis(t)
+	c := newTestLayeredCache(t, client, time.Minute)
+
+	writes := []struct {
+		name  string
+		write func(key string) error
+		want  string
+	}{
+		{"Set", func(key string) error { return c.Set(ctx, key, "set") }, "set"},
+		{"SetWithTTL", func(key string) error { return c.SetWithTTL(ctx, key, "set-ttl", time.Minute) }, "set-ttl"},
+		{"Incr", func(key string) error { _, err := c.Incr(ctx, key); return err }, "1"},
+		{"Decr", func(key string) error { _, err := c.Decr(ctx, key); return err }, "-1"},
+		{"IncrWithTTL", func(key string) error { _, err := c.IncrWithTTL(ctx, key, time.Minute); return err }, "1"},
+	}
+	for _, w := range writes {
+		t.Run(w.name, func(t *testing.T) {
+			key := "k:" + w.name
+			mr.Set(key, "0")
+			assertCached(t, c, key, "0")
+
+			if err := w.write(key); err != nil {
+				t.Fatalf("%s: %v", w.name, err)
+			}
+			if ok, _ := c.local.Exists(ctx, key); ok {
+				t.Fatalf("local entry not invalidated by %s", w.name)
+			}
+			assertCached(t, c, key, w.want)
+		})
+	}
+
+	t.Run("Delete", func(t *testing.T) {
+		mr.Set("k:Delete", "0")
+		assertCached(t, c, "k:Delete", "0")
+
+		if err := c.Delete(ctx, "k:Delete"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		assertMiss(t, c, "k:Delete")
+	})
+}
+
+func TestLayeredCacheInvalidatesOtherInstances(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newTestRedis(t)
+	a := newTestLayeredCache(t, client, time.Minute)
+	b := newTestLayeredCache(t, client, time.Minute)
+
+	mr.Set("k", "v1")
+	assertCached(t, a, "k", "v1")
+	assertCached(t, b, "k", "v1")
+
+	if err := b.Set(ctx, "k", "v2"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	waitLocalEvicted(t, a, "k")
+	assertCached(t, a, "k", "v2")
+
+	if err := a.Delete(ctx, "k"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	waitLocalEvicted(t, b, "k")
+	assertMiss(t, b, "k")
+}
+
+func TestLayeredCacheLocalTTL(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+	c := newTestLayeredCache(t, client, time.Minute)
+
+	// Redis 中没有过期时间时，本地条目使用 localTTL
+	if err := c.Set(ctx, "forever", "v"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	assertCached(t, c, "forever", "v")
+	if ttl, _ := c.local.TTL(ctx, "forever"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("local TTL = %v, want (0, 1m]", ttl)
+	}
+
+	// 本地条目不晚于 Redis 中的键过期
+	if err := c.SetWithTTL(ctx, "short", "v", 10*time.Second); err != nil {
+		t.Fatalf("SetWithTTL: %v", err)
+	}
+	assertCached(t, c, "short", "v")
+	if ttl, _ := c.local.TTL(ctx, "short"); ttl <= 0 || ttl > 10*time.Second {
+		t.Fatalf("local TTL = %v, want (0, 10s]", ttl)
+	}
+}
+
+func TestRedisCacheIncrWithTTL(t *testing.T) {
+	ctx := context.Background()
+	mr, client := newTestRedis(t)
+	c := NewRedisCache(client)
+
+	if n, err := c.IncrWithTTL(ctx, "counter", time.Minute); err != nil || n != 1 {
+		t.Fatalf("first IncrWithTTL = %d, %v, want 1", n, err)
+	}
+	mr.FastForward(40 * time.Second)
+
+	// 与 MemoryCache 一致：后续自增不重置过期时间
+	if n, err := c.IncrWithTTL(ctx, "counter", time.Minute); err != nil || n != 2 {
+		t.Fatalf("second IncrWithTTL = %d, %v, want 2", n, err)
+	}
+	if ttl, _ := c.TTL(ctx, "counter"); ttl > 20*time.Second {
+		t.Fatalf("TTL = %v, want the window to keep its original expiry", ttl)
+	}
+
+	mr.FastForward(30 * time.Second)
+	if n, err := c.IncrWithTTL(ctx, "counter", time.Minute); err != nil || n != 1 {
+		t.Fatalf("IncrWithTTL after the window = %d, %v, want 1", n, err)
+	}
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+const lockRetryInterval = 50 * time.Millisecond
+
+var (
+	// ErrLockNotAcquired 锁已被其他持有者占用
+	ErrLockNotAcquired = errors.New("cache: lock not acquired")
+	// ErrLockNotHeld 锁已过期或已被其他持有者获取
+	ErrLockNotHeld = errors.New("cache: lock not held")
+)
+
+// Lock 已获取的锁
+// Token 标识持有者，只有持有者可以续期和释放；Fence 为同一锁名单调递增的栅栏令牌，
+// 写入外部资源时携带该值，资源方拒绝比已见过的值更小的请求，可防止锁过期后的旧持有者继续写入
+type Lock struct {
+	Name  string
+	Token string
+	Fence int64
+}
+
+// Locker 分布式锁
+type Locker interface {
+	// Acquire 尝试获取锁，不等待，被占用时返回 ErrLockNotAcquired
+	Acquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error)
+
+	// Extend 将锁的过期时间重置为 ttl，锁已丢失时返回 ErrLockNotHeld
+	Extend(ctx context.Context, lock *Lock, ttl time.Duration) error
+
+	// Release 释放锁，锁已丢失时返回 ErrLockNotHeld
+	Release(ctx context.Context, lock *Lock) error
+}
+
+// AcquireWait 获取锁，被占用时每隔一段时间重试，最多等待 wait
+func AcquireWait(ctx context.Context, locker Locker, name string, ttl, wait time.Duration) (*Lock, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		lock, err := locker.Acquire(ctx, name, ttl)
+		if !errors.Is(err, ErrLockNotAcquired) || !time.Now().Add(lockRetryInterval).Before(deadline) {
+			return lock, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// WithLock 在持有锁期间执行 fn，结束后释放锁
+// fn 的执行时间应小于 ttl，否则锁可能在执行期间过期被其他持有者获取
+func WithLock(ctx context.Context, locker Locker, name string, ttl, wait time.Duration, fn func(ctx context.Context) error) error {
+	lock, err := AcquireWait(ctx, locker, name, ttl, wait)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// 释放失败时锁会在 ttl 后自动过期
+		_ = locker.Release(context.WithoutCancel(ctx), lock)
+	}()
+
+	return fn(ctx)
+}
+
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testLockTTL = 20 * time.Millisecond
+
+// lockerFactory 创建待测的 Locker，expire 让已获取的锁超过 ttl 过期
+type lockerFactory func(t *testing.T) (locker Locker, expire func())
+
+func memoryLockerFactory(t *testing.T) (Locker, func()) {
+	return NewMemoryLocker(), func() { time.Sleep(testLockTTL + 10*time.Millisecond) }
+}
+
+func redisLockerFactory(t *testing.T) (Locker, func()) {
+	mr, client := newTestRedis(t)
+	return NewRedisLocker(client), func() { mr.FastForward(testLockTTL + 10*time.Millisecond) }
+}
+
+func TestLocker(t *testing.T) {
+	factories := []struct {
+		name    string
+		factory lockerFactory
+	}{
+		{"memory", memoryLockerFactory},
+		{"redis", redisLockerFactory},
+	}
+	tests := []struct {
+		name string
+		run  func(t *testing.T, factory lockerFactory)
+	}{
+		{"AcquireContention", testLockerAcquireContention},
+		{"FenceMonotonic", testLockerFenceMonotonic},
+		{"ReleaseByNonOwner", testLockerReleaseByNonOwner},
+		{"ExtendAfterExpiry", testLockerExtendAfterExpiry},
+		{"Extend", testLockerExtend},
+	}
+
+	for _, f := range factories {
+		t.Run(f.name, func(t *testing.T) {
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					tt.run(t, f.factory)
+				})
+			}
+		})
+	}
+}
+
+func testLockerAcquireContention(t *testing.T, factory lockerFactory) {
+	ctx := context.Background()
+	locker, _ := factory(t)
+
+	const contenders = 20
+	var (
+		wg       sync.WaitGroup
+		acquired atomic.Int32
+		start    = make(chan struct{})
+	)
+	for i := 0; i < contenders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := locker.Acquire(ctx, "contended", time.Minute)
+			switch {
+			case err == nil:
+				acquired.Add(1)
+			case !errors.Is(err, ErrLockNotAcquired):
+				t.Errorf("Acquire: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if n := acquired.Load(); n != 1 {
+		t.Fatalf("%d contenders acquired the lock, want 1", n)
+	}
+
+	// 不同锁名互不影响
+	if _, err := locker.Acquire(ctx, "other", time.Minute); err != nil {
+		t.Fatalf("Acquire(other): %v", err)
+	}
+}
+
+func testLockerFenceMonotonic(t *testing.T, factory lockerFactory) {
+	ctx := context.Background()
+	locker, expire := factory(t)
+
+	first, err := locker.Acquire(ctx, "fenced", testLockTTL)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if err := locker.Release(ctx, first); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+
+	second, err := locker.Acquire(ctx, "fenced", testLockTTL)
+	if err != nil {
+		t.Fatalf("Acquire after release: %v", err)
+	}
+	if second.Fence <= first.Fence {
+		t.Fatalf("fence after release = %d, want greater than %d", second.Fence, first.Fence)
+	}
+
+	// 锁过期后被其他持有者获取，新持有者的栅栏令牌更大，资源方可以拒绝旧持有者的写入
+	expire()
+	third, err := locker.Acquire(ctx, "fenced", testLockTTL)
+	if err != nil {
+		t.Fatalf("Acquire after expiry: %v", err)
+	}
+	if third.Fence <= second.Fence {
+		t.Fatalf("fence after expiry = %d, want greater than %d", third.Fence, second.Fence)
+	}
+	if third.Token == second.Token {
+		t.Fatal("token reused across holders")
+	}
+}
+
+func testLockerReleaseByNonOwner(t *testing.T, factory lockerFactory) {
+	ctx := context.Background()
+	locker, _ := factory(t)
+
+	owner, err := locker.Acquire(ctx, "owned", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	intruder := &Lock{Name: owner.Name, Token: "forged-token", Fence: owner.Fence}
+	if err := locker.Release(ctx, intruder); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("Release by non-owner = %v, want ErrLockNotHeld", err)
+	}
+	if _, err := locker.Acquire(ctx, "owned", time.Minute); !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("Acquire after non-owner release = %v, want ErrLockNotAcquired", err)
+	}
+
+	if err := locker.Release(ctx, owner); err != nil {
+		t.Fatalf("Release by owner: %v", err)
+	}
+	if err := locker.Release(ctx, owner); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("second Release = %v, want ErrLockNotHeld", err)
+	}
+	if _, err := locker.Acquire(ctx, "owned", time.Minute); err != nil {
+		t.Fatalf("Acquire after owner release: %v", err)
+	}
+}
+
+func testLockerExtendAfterExpiry(t *testing.T, factory lockerFactory) {
+	ctx := context.Background()
+	locker, expire := factory(t)
+
+	stale, err := locker.Acquire(ctx, "expiring", testLockTTL)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	expire()
+
+	if err := locker.Extend(ctx, stale, time.Minute); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("Extend after expiry = %v, want ErrLockNotHeld", err)
+	}
+
+	// 过期后被其他持有者获取，旧持有者既不能续期也不能释放新持有者的锁
+	current, err := locker.Acquire(ctx, "expiring", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire after expiry: %v", err)
+	}
+	if err := locker.Extend(ctx, stale, time.Minute); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("Extend by stale holder = %v, want ErrLockNotHeld", err)
+	}
+	if err := locker.Release(ctx, stale); !errors.Is(err, ErrLockNotHeld) {
+		t.Fatalf("Release by stale holder = %v, want ErrLockNotHeld", err)
+	}
+	if err := locker.Release(ctx, current); err != nil {
+		t.Fatalf("Release by current holder: %v", err)
+	}
+}
+
+func testLockerExtend(t *testing.T, factory lockerFactory) {
+	ctx := context.Background()
+	locker, expire := factory(t)
+
+	lock, err := locker.Acquire(ctx, "extended", testLockTTL)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if err := locker.Extend(ctx, lock, time.Minute); err != nil {
+		t.Fatalf("Extend: %v", err)
+	}
+
+	// 续期后超过原来的 ttl 仍然持有锁
+	expire()
+	if _, err := locker.Acquire(ctx, "extended", time.Minute); !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("Acquire after extend = %v, want ErrLockNotAcquired", err)
+	}
+	if err := locker.Release(ctx, lock); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+}
+
+func TestWithLockWaitsForRelease(t *testing.T) {
+	ctx := context.Background()
+	locker := NewMemoryLocker()
+
+	held, err := locker.Acquire(ctx, "waited", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	go func() {
+		time.Sleep(2 * lockRetryInterval)
+		locker.Release(ctx, held)
+	}()
+
+	ran := false
+	if err := WithLock(ctx, locker, "waited", time.Minute, time.Second, func(ctx context.Context) error {
+		ran = true
+		return nil
+	}); err != nil {
+		t.Fatalf("WithLock: %v", err)
+	}
+	if !ran {
+		t.Fatal("fn did not run")
+	}
+
+	// WithLock 结束后释放锁
+	if _, err := locker.Acquire(ctx, "waited", time.Minute); err != nil {
+		t.Fatalf("Acquire after WithLock: %v", err)
+	}
+
+	// 等待超时返回 ErrLockNotAcquired，不执行 fn
+	err = WithLock(ctx, locker, "waited", time.Minute, lockRetryInterval, func(ctx context.Context) error {
+		t.Fatal("fn ran without the lock")
+		return nil
+	})
+	if !errors.Is(err, ErrLockNotAcquired) {
+		t.Fatalf("WithLock on held lock = %v, want ErrLockNotAcquired", err)
+	}
+}
@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultMemoryCacheMaxEntries = 10000
+
+// ErrNotInteger 对非整数值执行自增或自减
+var ErrNotInteger = errors.New("cache: value is not an integer")
+
+// 保证对接口实现
+var _ CacheContext = (*MemoryCache)(nil)
+
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time // 零值表示永不过期
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryCache 进程内的 CacheContext 实现，条目数超过上限时淘汰最久未使用的条目，过期条目在访问时清除
+// 适用于测试和单节点部署，多实例部署时各实例数据互不可见
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultMemoryCacheMaxEntries
+	}
+
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.get(key, time.Now())
+	if !ok {
+		return "", ErrCacheMiss
+	}
+	return entry.value, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value string) error {
+	return c.SetWithTTL(ctx, key, value, 0)
+}
+
+func (c *MemoryCache) SetWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	c.set(key, value, expiresAt)
+	return nil
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+	return nil
+}
+
+func (c *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.get(key, time.Now())
+	return ok, nil
+}
+
+func (c *MemoryCache) Incr(ctx context.Context, key string) (int64, error) {
+	return c.incrBy(key, 1, 0)
+}
+
+func (c *MemoryCache) Decr(ctx context.Context, key string) (int64, error) {
+	return c.incrBy(key, -1, 0)
+}
+
+func (c *MemoryCache) IncrWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return c.incrBy(key, 1, ttl)
+}
+
+func (c *MemoryCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := c.get(key, now)
+	if !ok || entry.expiresAt.IsZero() {
+		return 0, nil
+	}
+	return entry.expiresAt.Sub(now), nil
+}
+
+// Len 返回当前条目数，包含尚未清除的过期条目
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// incrBy 与 Redis INCRBY 语义一致：键不存在时从 0 开始，已有的过期时间保持不变
+// ttl 大于 0 时仅在创建键时设置过期时间
+func (c *MemoryCache) incrBy(key string, delta int64, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := c.get(key, now)
+	if !ok {
+		var expiresAt time.Time
+		if ttl > 0 {
+			expiresAt = now.Add(ttl)
+		}
+		c.set(key, strconv.FormatInt(delta, 10), expiresAt)
+		return delta, nil
+	}
+
+	n, err := strconv.ParseInt(entry.value, 10, 64)
+	if err != nil {
+		return 0, ErrNotInteger
+	}
+	n += delta
+	entry.value = strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+// get 查找未过期的条目并标记为最近使用，调用方需持有锁
+func (c *MemoryCache) get(key string, now time.Time) (*memoryEntry, bool) {
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*memoryEntry)
+	if entry.expired(now) {
+		c.remove(elem)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(elem)
+	return entry, true
+}
+
+// set 写入条目，超过上限时淘汰最久未使用的条目，调用方需持有锁
+func (c *MemoryCache) set(key, value string, expiresAt time.Time) {
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+
+	for c.ll.Len() > c.maxEntries {
+		c.remove(c.ll.Back())
+	}
+}
+
+func (c *MemoryCache) remove(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*memoryEntry).key)
+}
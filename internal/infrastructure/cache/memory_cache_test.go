@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func assertCached(t *testing.T, c CacheContext, key, want string) {
+	t.Helper()
+
+	got, err := c.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", key, err)
+	}
+	if got != want {
+		t.Fatalf("Get(%s) = %q, want %q", key, got, want)
+	}
+}
+
+func assertMiss(t *testing.T, c CacheContext, key string) {
+	t.Helper()
+
+	if got, err := c.Get(context.Background(), key); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("Get(%s) = %q, %v, want ErrCacheMiss", key, got, err)
+	}
+}
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(3)
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := c.Set(ctx, key, key); err != nil {
+			t.Fatalf("Set(%s): %v", key, err)
+		}
+	}
+
+	// 读取 a 后 b 成为最久未使用的条目
+	assertCached(t, c, "a", "a")
+	if err := c.Set(ctx, "d", "d"); err != nil {
+		t.Fatalf("Set(d): %v", err)
+	}
+	assertMiss(t, c, "b")
+
+	// 覆盖写入 c 同样视为使用，此时 a 最久未使用
+	if err := c.Set(ctx, "c", "c2"); err != nil {
+		t.Fatalf("Set(c): %v", err)
+	}
+	if _, err := c.Exists(ctx, "d"); err != nil {
+		t.Fatalf("Exists(d): %v", err)
+	}
+	if err := c.Set(ctx, "e", "e"); err != nil {
+		t.Fatalf("Set(e): %v", err)
+	}
+	assertMiss(t, c, "a")
+
+	assertCached(t, c, "c", "c2")
+	assertCached(t, c, "d", "d")
+	assertCached(t, c, "e", "e")
+	if n := c.Len(); n != 3 {
+		t.Fatalf("Len = %d, want 3", n)
+	}
+}
+
+func TestMemoryCacheTTLExpiry(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	if err := c.SetWithTTL(ctx, "short", "v", 20*time.Millisecond); err != nil {
+		t.Fatalf("SetWithTTL: %v", err)
+	}
+	if err := c.Set(ctx, "forever", "v"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	assertCached(t, c, "short", "v")
+	if ttl, _ := c.TTL(ctx, "short"); ttl <= 0 || ttl > 20*time.Millisecond {
+		t.Fatalf("TTL(short) = %v, want (0, 20ms]", ttl)
+	}
+	if ttl, _ := c.TTL(ctx, "forever"); ttl != 0 {
+		t.Fatalf("TTL(forever) = %v, want 0", ttl)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	assertMiss(t, c, "short")
+	if ok, _ := c.Exists(ctx, "short"); ok {
+		t.Fatal("expired key still exists")
+	}
+	if ttl, _ := c.TTL(ctx, "short"); ttl != 0 {
+		t.Fatalf("TTL of expired key = %v, want 0", ttl)
+	}
+	assertCached(t, c, "forever", "v")
+	if n := c.Len(); n != 1 {
+		t.Fatalf("Len = %d, want expired entry to be removed on access", n)
+	}
+
+	// 重新写入不带 TTL 时清除原有的过期时间
+	if err := c.SetWithTTL(ctx, "reset", "v", 20*time.Millisecond); err != nil {
+		t.Fatalf("SetWithTTL: %v", err)
+	}
+	if err := c.Set(ctx, "reset", "v2"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	assertCached(t, c, "reset", "v2")
+}
+
+func TestMemoryCacheIncrWithTTL(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+	const window = 50 * time.Millisecond
+
+	n, err := c.IncrWithTTL(ctx, "counter", window)
+	if err != nil || n != 1 {
+		t.Fatalf("first IncrWithTTL = %d, %v, want 1", n, err)
+	}
+	first, _ := c.TTL(ctx, "counter")
+
+	time.Sleep(20 * time.Millisecond)
+
+	// 后续自增不重置过期时间，计数器按固定窗口过期
+	n, err = c.IncrWithTTL(ctx, "counter", window)
+	if err != nil || n != 2 {
+		t.Fatalf("second IncrWithTTL = %d, %v, want 2", n, err)
+	}
+	if second, _ := c.TTL(ctx, "counter"); second >= first {
+		t.Fatalf("TTL after second increment = %v, want less than %v", second, first)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	n, err = c.IncrWithTTL(ctx, "counter", window)
+	if err != nil || n != 1 {
+		t.Fatalf("IncrWithTTL after the window = %d, %v, want 1", n, err)
+	}
+
+	// 已存在且没有过期时间的键保持永不过期
+	if err := c.Set(ctx, "plain", "5"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	n, err = c.IncrWithTTL(ctx, "plain", window)
+	if err != nil || n != 6 {
+		t.Fatalf("IncrWithTTL on existing key = %d, %v, want 6", n, err)
+	}
+	if ttl, _ := c.TTL(ctx, "plain"); ttl != 0 {
+		t.Fatalf("TTL(plain) = %v, want 0", ttl)
+	}
+
+	if err := c.Set(ctx, "text", "abc"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := c.IncrWithTTL(ctx, "text", window); !errors.Is(err, ErrNotInteger) {
+		t.Fatalf("IncrWithTTL on non-integer = %v, want ErrNotInteger", err)
+	}
+}
+
+func TestMemoryCacheIncrDecr(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(10)
+
+	if n, err := c.Decr(ctx, "n"); err != nil || n != -1 {
+		t.Fatalf("Decr on missing key = %d, %v, want -1", n, err)
+	}
+	if n, err := c.Incr(ctx, "n"); err != nil || n != 0 {
+		t.Fatalf("Incr = %d, %v, want 0", n, err)
+	}
+	assertCached(t, c, "n", "0")
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// 保证对接口实现
+var _ Locker = (*MemoryLocker)(nil)
+
+type memoryLock struct {
+	token     string
+	expiresAt time.Time
+}
+
+// MemoryLocker 进程内的锁，适用于测试和单节点部署
+type MemoryLocker struct {
+	mu     sync.Mutex
+	locks  map[string]memoryLock
+	fences map[string]int64
+}
+
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		locks:  make(map[string]memoryLock),
+		fences: make(map[string]int64),
+	}
+}
+
+func (l *MemoryLocker) Acquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if held, ok := l.locks[name]; ok && now.Before(held.expiresAt) {
+		return nil, ErrLockNotAcquired
+	}
+
+	l.locks[name] = memoryLock{token: token, expiresAt: now.Add(ttl)}
+	l.fences[name]++
+
+	return &Lock{Name: name, Token: token, Fence: l.fences[name]}, nil
+}
+
+func (l *MemoryLocker) Extend(ctx context.Context, lock *Lock, ttl time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.holds(lock, time.Now()) {
+		return ErrLockNotHeld
+	}
+
+	l.locks[lock.Name] = memoryLock{token: lock.Token, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (l *MemoryLocker) Release(ctx context.Context, lock *Lock) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.holds(lock, time.Now()) {
+		return ErrLockNotHeld
+	}
+
+	delete(l.locks, lock.Name)
+	return nil
+}
+
+// holds 判断锁是否仍由 lock 的持有者持有，调用方需持有互斥锁
+func (l *MemoryLocker) holds(lock *Lock, now time.Time) bool {
+	held, ok := l.locks[lock.Name]
+	return ok && held.token == lock.Token && now.Before(held.expiresAt)
+}
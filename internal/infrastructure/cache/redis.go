@@ -3,6 +3,7 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"strconv"
 
 	"github.com/go-redis/redis/v8"
@@ -12,23 +13,70 @@ import (
 
 func init() {
 	config := di.Get[config.Config]()
-	redisClient, err := initRedis(config.Cache.Redis)
-	if err != nil {
-		panic("Failed to initialize Redis client: " + err.Error())
+
+	SetKeyPrefix(config.Cache.Redis.Prefix)
+
+	// memory 驱动不依赖 Redis，在其他组件需要时才连接
+	var redisClient redis.UniversalClient
+	if config.Cache.Driver != "memory" {
+		client, err := initRedis(config.Cache.Redis)
+		if err != nil {
+			panic("Failed to initialize Redis client: " + err.Error())
+		}
+		redisClient = client
 	}
 
-	di.AddSingleton[*redis.Client](func() (*redis.Client, error) {
-		return redisClient, nil
+	di.AddSingleton[redis.UniversalClient](func() (redis.UniversalClient, error) {
+		if redisClient != nil {
+			return redisClient, nil
+		}
+		return initRedis(config.Cache.Redis)
+	})
+
+	di.AddSingleton[CacheContext](func() (CacheContext, error) {
+		return newCacheContext(config.Cache, redisClient)
 	})
-}
 
-// initRedis 初始化Redis客户端
-func initRedis(config config.RedisConfig) (*redis.Client, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     config.Host + ":" + strconv.FormatInt(int64(config.Port), 10),
-		Password: config.Password,
-		DB:       config.Database,
+	di.AddSingleton[Locker](func() (Locker, error) {
+		return newLocker(config.Cache, redisClient), nil
 	})
+}
+
+// initRedis 初始化Redis客户端，按 framework 创建单机、哨兵或集群客户端
+func initRedis(config config.RedisConfig) (redis.UniversalClient, error) {
+	addrs := config.Addrs
+	if len(addrs) == 0 {
+		addrs = []string{config.Host + ":" + strconv.FormatInt(int64(config.Port), 10)}
+	}
+
+	var client redis.UniversalClient
+	switch {
+	case config.IsCluster():
+		client = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    addrs,
+			Username: config.Username,
+			Password: config.Password,
+		})
+	case config.IsSentinel():
+		if config.MasterName == "" {
+			return nil, errors.New("redis master_name is required in sentinel mode")
+		}
+		client = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       config.MasterName,
+			SentinelAddrs:    addrs,
+			SentinelPassword: config.SentinelPassword,
+			Username:         config.Username,
+			Password:         config.Password,
+			DB:               config.Database,
+		})
+	default:
+		client = redis.NewClient(&redis.Options{
+			Addr:     addrs[0],
+			Username: config.Username,
+			Password: config.Password,
+			DB:       config.Database,
+		})
+	}
 
 	// 测试连接
 	_, err := client.Ping(context.Background()).Result()
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// incrWithTTLScript 自增计数器，首次创建时设置过期时间，保证计数器不会因进程中断而永不过期
+var incrWithTTLScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// 保证对接口实现
+var _ CacheContext = (*RedisCache)(nil)
+
+// RedisCache 基于 Redis 的 CacheContext 实现，支持单机、哨兵和集群模式
+// 键应通过 keys.go 中注册的 KeyBuilder 构造，以带上全局前缀
+type RedisCache struct {
+	client redis.UniversalClient
+}
+
+func NewRedisCache(client redis.UniversalClient) *RedisCache {
+	return &RedisCache{client: client}
+}
+
+func (c *RedisCache) Get(ctx context.Context, key string) (string, error) {
+	value, err := c.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrCacheMiss
+	}
+	return value, err
+}
+
+func (c *RedisCache) Set(ctx context.Context, key string, value string) error {
+	return c.client.Set(ctx, key, value, 0).Err()
+}
+
+func (c *RedisCache) SetWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return c.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, key).Err()
+}
+
+func (c *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := c.client.Exists(ctx, key).Result()
+	return n > 0, err
+}
+
+func (c *RedisCache) Incr(ctx context.Context, key string) (int64, error) {
+	return c.client.Incr(ctx, key).Result()
+}
+
+func (c *RedisCache) Decr(ctx context.Context, key string) (int64, error) {
+	return c.client.Decr(ctx, key).Result()
+}
+
+func (c *RedisCache) IncrWithTTL(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrWithTTLScript.Run(ctx, c.client, []string{key}, ttl.Milliseconds()).Int64()
+}
+
+func (c *RedisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// 键不存在时返回 -2，没有过期时间时返回 -1
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// getWithTTL 在一次往返中读取值和剩余过期时间
+func (c *RedisCache) getWithTTL(ctx context.Context, key string) (string, time.Duration, error) {
+	pipe := c.client.Pipeline()
+	getCmd := pipe.Get(ctx, key)
+	ttlCmd := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return "", 0, err
+	}
+
+	value, err := getCmd.Result()
+	if errors.Is(err, redis.Nil) {
+		return "", 0, ErrCacheMiss
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return value, ttlCmd.Val(), nil
+}
@@ -5,18 +5,25 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"testing"
 
 	"github.com/lyonnee/go-template/pkg/di"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Http     HttpConfig     `mapstructure:"http"`
-	Log      LogConfig      `mapstructure:"log"`
-	Auth     AuthConfig     `mapstructure:"auth"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Cache    CacheConfig    `mapstructure:"cache"`
+	App        AppConfig        `mapstructure:"app"`
+	Http       HttpConfig       `mapstructure:"http"`
+	Grpc       GrpcConfig       `mapstructure:"grpc"`
+	Log        LogConfig        `mapstructure:"log"`
+	Auth       AuthConfig       `mapstructure:"auth"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Cache      CacheConfig      `mapstructure:"cache"`
+	MQ         MQConfig         `mapstructure:"mq"`
+	Event      EventConfig      `mapstructure:"event"`
+	Blockchain BlockchainConfig `mapstructure:"blockchain"`
 }
 
 var conf = new(Config)
@@ -25,7 +32,17 @@ func init() {
 	var (
 		env = flag.String("env", "dev", "Environment (dev, test, prod)")
 	)
-	flag.Parse()
+
+	// go test 在包初始化之后才注册测试参数，此时不能解析命令行
+	if testing.Testing() {
+		*env = "test"
+		if err := chdirProjectRoot(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error locating project root: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		flag.Parse()
+	}
 
 	newConf, err := Load(*env)
 	if err != nil {
@@ -33,6 +50,12 @@ func init() {
 		os.Exit(1)
 	}
 
+	if testing.Testing() {
+		// 单元测试不依赖 Redis，使用进程内的缓存和消息队列
+		newConf.Cache.Driver = "memory"
+		newConf.MQ.Driver = "memory"
+	}
+
 	conf = newConf
 
 	di.AddSingleton[Config](func() (Config, error) {
@@ -56,6 +79,12 @@ func Load(env string) (*Config, error) {
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(path.Join(workDir, "configs"))
 
+	// 环境变量覆盖配置文件中的同名配置，如 APP_AUTH_MFA_ENCRYPTION_KEY 覆盖 auth.mfa.encryption_key
+	// 密钥等敏感配置在配置文件中留空，由环境变量或密钥管理服务注入
+	viper.SetEnvPrefix("APP")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+
 	if err := viper.ReadInConfig(); err != nil {
 		switch err.(type) {
 		case viper.ConfigFileNotFoundError:
@@ -72,3 +101,23 @@ func Load(env string) (*Config, error) {
 
 	return &newConf, nil
 }
+
+// chdirProjectRoot 测试的工作目录是包目录，切换到 go.mod 所在的项目根目录，
+// 使配置文件和配置中的相对路径与从项目根目录启动时一致
+func chdirProjectRoot() error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return os.Chdir(dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return fmt.Errorf("go.mod not found")
+		}
+		dir = parent
+	}
+}
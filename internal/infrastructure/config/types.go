@@ -13,24 +13,81 @@ type AppConfig struct {
 
 // ================== AuthConfig ==================
 type AuthConfig struct {
-	JWT JWTConfig `mapstructure:"jwt"`
+	JWT        JWTConfig        `mapstructure:"jwt"`
+	Siwe       SiweConfig       `mapstructure:"siwe"`
+	OAuth      OAuthConfig      `mapstructure:"oauth"`
+	Mfa        MfaConfig        `mapstructure:"mfa"`
+	LoginGuard LoginGuardConfig `mapstructure:"login_guard"`
 }
 
 type JWTConfig struct {
-	SecretKey          string        `mapstructure:"secret_key"`           // 用于对 JWT 进行签名和验证的密钥
+	Algorithm          string        `mapstructure:"algorithm"`            // 签名算法：HS256 / RS256 / ES256 / EdDSA，默认 HS256
+	SecretKey          string        `mapstructure:"secret_key"`           // HS256 用于对 JWT 进行签名和验证的密钥
+	KeyDir             string        `mapstructure:"key_dir"`              // 非对称算法的 PEM 密钥目录，<kid>.pem 为签名私钥，<kid>.pub.pem 为仅用于验证的公钥
+	RotationInterval   time.Duration `mapstructure:"rotation_interval"`    // 非对称签名密钥的轮换间隔，0 表示不自动轮换
 	AccessTokenExpiry  time.Duration `mapstructure:"access_token_expiry"`  // 访问令牌的有效时长（以秒为单位）
 	RefreshTokenExpiry time.Duration `mapstructure:"refresh_token_expiry"` // 刷新令牌的有效时长（以秒为单位）
 	Issuer             string        `mapstructure:"issuer"`               // 颁布单位
 }
 
+// LoginGuardConfig 登录防暴力破解配置
+type LoginGuardConfig struct {
+	MaxFailuresPerUsername int           `mapstructure:"max_failures_per_username"` // 失败窗口内同一用户名允许的失败次数，达到后锁定，0 表示不限制
+	MaxFailuresPerIP       int           `mapstructure:"max_failures_per_ip"`       // 失败窗口内同一IP允许的失败次数，达到后锁定，0 表示不限制
+	FailureWindow          time.Duration `mapstructure:"failure_window"`            // 失败次数的统计窗口
+	LockoutDuration        time.Duration `mapstructure:"lockout_duration"`          // 锁定时长
+	DelayBase              time.Duration `mapstructure:"delay_base"`                // 登录失败后的响应延迟基数，每多失败一次翻倍
+	MaxDelay               time.Duration `mapstructure:"max_delay"`                 // 响应延迟上限
+}
+
+// MfaConfig 多因素认证配置
+type MfaConfig struct {
+	Issuer            string        `mapstructure:"issuer"`              // 认证器应用中显示的签发方名称
+	EncryptionKey     string        `mapstructure:"encryption_key"`      // 加密 TOTP 密钥的 AES-256 密钥（base64 编码的 32 字节）
+	ChallengeTTL      time.Duration `mapstructure:"challenge_ttl"`       // 密码校验通过后提交第二因素的有效期
+	MaxAttempts       int           `mapstructure:"max_attempts"`        // 每个登录挑战允许的最大验证码错误次数，关闭多因素认证等账户操作在锁定时长内同样适用
+	LockoutDuration   time.Duration `mapstructure:"lockout_duration"`    // 账户操作验证码错误的统计窗口，达到最大次数后锁定同样时长
+	RecoveryCodeCount int           `mapstructure:"recovery_code_count"` // 启用时生成的恢复码数量
+}
+
+// SiweConfig Sign-In with Ethereum (EIP-4361) 登录配置
+type SiweConfig struct {
+	Domain    string        `mapstructure:"domain"`     // 允许发起签名请求的域名（含端口），需与消息中的 domain 一致
+	URI       string        `mapstructure:"uri"`        // 允许的 URI 前缀，为空时不校验
+	NonceTTL  time.Duration `mapstructure:"nonce_ttl"`  // nonce 有效期
+	MaxAge    time.Duration `mapstructure:"max_age"`    // 消息签发后的最长有效时长
+	ClockSkew time.Duration `mapstructure:"clock_skew"` // 允许的时钟偏差
+}
+
+// OAuthConfig OAuth2 / OIDC 第三方登录配置
+type OAuthConfig struct {
+	StateTTL  time.Duration                  `mapstructure:"state_ttl"`  // state、nonce 和 PKCE code_verifier 的有效期
+	Timeout   time.Duration                  `mapstructure:"timeout"`    // 请求身份提供方的超时时间
+	ClockSkew time.Duration                  `mapstructure:"clock_skew"` // 校验 ID Token 时允许的时钟偏差
+	Providers map[string]OAuthProviderConfig `mapstructure:"providers"`  // 身份提供方，key 为路由中的 :provider
+}
+
+// OAuthProviderConfig 通用 OIDC 身份提供方配置
+type OAuthProviderConfig struct {
+	Issuer       string   `mapstructure:"issuer"`        // Issuer 地址，用于发现 /.well-known/openid-configuration 并校验 ID Token
+	ClientID     string   `mapstructure:"client_id"`     // 客户端ID
+	ClientSecret string   `mapstructure:"client_secret"` // 客户端密钥，公共客户端可为空
+	RedirectURL  string   `mapstructure:"redirect_url"`  // 回调地址，需指向 /api/auth/oauth/:provider/callback
+	Scopes       []string `mapstructure:"scopes"`        // 申请的权限范围，openid 会自动添加
+}
+
 // ================== DatabaseConfig ==================
 type DatabaseConfig struct {
-	Mysql    MysqlConfig    `mapstructure:"mysql"`
-	Postgres PostgresConfig `mapstructure:"postgres"`
+	Driver      string         `mapstructure:"driver"`       // postgres / mysql，默认 postgres
+	AutoMigrate bool           `mapstructure:"auto_migrate"` // 服务启动时执行未执行的迁移，也可使用 cmd/migrate 手动执行
+	TxRetry     TxRetryConfig  `mapstructure:"tx_retry"`     // TransactionWithRetry 的重试策略
+	Mysql       MysqlConfig    `mapstructure:"mysql"`
+	Postgres    PostgresConfig `mapstructure:"postgres"`
 }
 
 type PostgresConfig struct {
-	DSN string `mapstructure:"dsn"`
+	DSN      string        `mapstructure:"dsn"`      // 主库
+	Replicas ReplicaConfig `mapstructure:"replicas"` // 只读副本
 
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
@@ -38,8 +95,23 @@ type PostgresConfig struct {
 	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
 }
 
+// TxRetryConfig 事务遇到序列化失败、死锁等并发冲突时的重试策略，退避时间按指数增长并加入随机抖动
+type TxRetryConfig struct {
+	MaxAttempts    int           `mapstructure:"max_attempts"`    // 最多执行的次数，包含第一次，默认 5
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // 第一次重试前的最长等待时间，默认 10ms
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`     // 每次重试前的最长等待时间，默认 500ms
+}
+
+// ReplicaConfig 只读副本配置，Database.Conn 优先使用健康的副本，Database.Transaction 总是使用主库
+type ReplicaConfig struct {
+	DSNs                []string      `mapstructure:"dsns"`                  // 只读副本的 DSN，为空时读写都使用主库
+	Policy              string        `mapstructure:"policy"`                // 副本选择策略：round_robin / least_connections，默认 round_robin
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"` // 健康检查间隔，默认 10s
+}
+
 type MysqlConfig struct {
-	DSN string `mapstructure:"dsn"`
+	DSN      string        `mapstructure:"dsn"`      // 主库
+	Replicas ReplicaConfig `mapstructure:"replicas"` // 只读副本
 
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
@@ -47,29 +119,132 @@ type MysqlConfig struct {
 	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
 }
 
+// ================== BlockchainConfig ==================
+type BlockchainConfig struct {
+	Enable   bool           `mapstructure:"enable"` // 是否启动时在后台校验节点的链ID，并在就绪检查中报告节点状态
+	Ethereum EthereumConfig `mapstructure:"ethereum"`
+}
+
+type EthereumConfig struct {
+	RPCURL       string        `mapstructure:"rpc_url"`       // EVM 节点 JSON-RPC 地址
+	ChainID      int64         `mapstructure:"chain_id"`      // 链ID
+	Timeout      time.Duration `mapstructure:"timeout"`       // 单次请求超时
+	MaxRetries   int           `mapstructure:"max_retries"`   // 网络错误、429、5xx 的最大重试次数
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"` // 首次重试间隔，之后按指数增长
+}
+
 // ================== CacheConfig ==================
 type CacheConfig struct {
-	Redis RedisConfig `mapstructure:"redis"`
+	Driver string           `mapstructure:"driver"` // CacheContext 实现，redis / memory / layered
+	Local  LocalCacheConfig `mapstructure:"local"`
+	Redis  RedisConfig      `mapstructure:"redis"`
+}
+
+// LocalCacheConfig 进程内缓存配置，用于 memory 和 layered 驱动
+type LocalCacheConfig struct {
+	MaxEntries          int           `mapstructure:"max_entries"`          // 最大条目数，超过后淘汰最久未使用的条目
+	TTL                 time.Duration `mapstructure:"ttl"`                  // layered 驱动中本地条目的最长存活时间
+	InvalidationChannel string        `mapstructure:"invalidation_channel"` // layered 驱动跨实例失效通知的 pub/sub 频道
 }
 
 type RedisConfig struct {
-	Host      string `mapstructure:"host"`
-	Port      int    `mapstructure:"port"`
-	Username  string `mapstructure:"username"`
-	Password  string `mapstructure:"password"`
-	Database  int    `mapstructure:"database"`
-	Framework string `mapstructure:"framework"`
-	Prefix    string `mapstructure:"prefix"`
+	Host             string   `mapstructure:"host"`
+	Port             int      `mapstructure:"port"`
+	Addrs            []string `mapstructure:"addrs"` // 集群节点或哨兵地址，为空时使用 host:port
+	Username         string   `mapstructure:"username"`
+	Password         string   `mapstructure:"password"`
+	Database         int      `mapstructure:"database"` // 集群模式不支持选择数据库
+	Framework        string   `mapstructure:"framework"`
+	MasterName       string   `mapstructure:"master_name"`       // 哨兵模式的主节点名称
+	SentinelPassword string   `mapstructure:"sentinel_password"` // 哨兵节点的密码
+	Prefix           string   `mapstructure:"prefix"`
 }
 
 func (conf RedisConfig) IsCluster() bool {
 	return conf.Framework == "cluster"
 }
 
+func (conf RedisConfig) IsSentinel() bool {
+	return conf.Framework == "sentinel"
+}
+
+// ================== MQConfig ==================
+type MQConfig struct {
+	Driver           string        `mapstructure:"driver"`             // 消息队列实现，redis / memory
+	DeadLetterSuffix string        `mapstructure:"dead_letter_suffix"` // 死信 topic 后缀
+	Retry            MQRetryConfig `mapstructure:"retry"`
+	Redis            MQRedisConfig `mapstructure:"redis"`
+}
+
+type MQRetryConfig struct {
+	MaxRetries     int           `mapstructure:"max_retries"`     // 最大重试次数，超过后进入死信
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // 首次重试间隔
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`     // 最大重试间隔
+	Multiplier     float64       `mapstructure:"multiplier"`      // 重试间隔增长倍数
+}
+
+type MQRedisConfig struct {
+	BatchSize    int64         `mapstructure:"batch_size"`     // 单次拉取的消息数
+	BlockTimeout time.Duration `mapstructure:"block_timeout"`  // 拉取消息的阻塞时长
+	ClaimMinIdle time.Duration `mapstructure:"claim_min_idle"` // 接管其他消费者未确认消息的最小空闲时长
+	MaxLen       int64         `mapstructure:"max_len"`        // stream 最大长度（近似裁剪），0 表示不裁剪
+}
+
+// ================== EventConfig ==================
+type EventConfig struct {
+	PublishToMQ bool         `mapstructure:"publish_to_mq"` // 是否将领域事件发布到消息队列
+	TopicPrefix string       `mapstructure:"topic_prefix"`  // 领域事件 topic 前缀
+	Outbox      OutboxConfig `mapstructure:"outbox"`
+}
+
+// OutboxConfig 事务发件箱配置，开启后领域事件先随业务事务写入 outbox 表，再由 relay 发布到消息队列
+type OutboxConfig struct {
+	Enable       bool          `mapstructure:"enable"`        // 是否启用事务发件箱
+	PollInterval time.Duration `mapstructure:"poll_interval"` // 轮询间隔
+	BatchSize    int           `mapstructure:"batch_size"`    // 单次投递的消息数
+	MaxAttempts  int           `mapstructure:"max_attempts"`  // 消息本身导致投递失败的最大次数，超过后隔离，消息队列不可用时不计入
+	RetryBackoff time.Duration `mapstructure:"retry_backoff"` // 首次重试间隔
+	MaxBackoff   time.Duration `mapstructure:"max_backoff"`   // 最大重试间隔
+	Retention    time.Duration `mapstructure:"retention"`     // 投递成功的消息保留时长，超过后由定时任务删除
+}
+
 // ================== HttpConfig ==================
 // HttpConfig 包含 HTTP 服务的配置
 
 type HttpConfig struct {
+	Port           string            `mapstructure:"port"`
+	TrustedProxies []string          `mapstructure:"trusted_proxies"` // 可信反向代理的 CIDR 或 IP，只有直连地址属于其中时才读取 X-Forwarded-For / X-Real-IP
+	RateLimit      RateLimitConfig   `mapstructure:"rate_limit"`
+	Idempotency    IdempotencyConfig `mapstructure:"idempotency"`
+}
+
+// IdempotencyConfig Idempotency-Key 幂等请求配置
+type IdempotencyConfig struct {
+	TTL            time.Duration `mapstructure:"ttl"`             // 响应的保存时长，期间相同 Idempotency-Key 的重试直接返回保存的响应，默认 24h
+	LockTTL        time.Duration `mapstructure:"lock_ttl"`        // 处理中请求的锁时长，期间相同 Idempotency-Key 的并发请求被拒绝，默认 30s
+	FingerprintKey string        `mapstructure:"fingerprint_key"` // 计算请求指纹的 HMAC 密钥，至少 32 字节，多副本需使用相同的密钥
+}
+
+// RateLimitConfig 接口限流配置
+type RateLimitConfig struct {
+	Enable  bool                     `mapstructure:"enable"`  // 是否启用限流
+	Backend string                   `mapstructure:"backend"` // 限流状态的存储，redis / memory，memory 仅适用于单节点部署
+	Groups  map[string]RateLimitRule `mapstructure:"groups"`  // 按路由组配置的限流规则，key 为 router.go 中 middleware.RateLimit 的组名
+}
+
+// RateLimitRule 限流规则
+type RateLimitRule struct {
+	Algorithm string        `mapstructure:"algorithm"` // token_bucket / sliding_window，默认 sliding_window
+	Key       string        `mapstructure:"key"`       // 限流对象：ip / user / route，user 未登录时按 ip
+	Limit     int           `mapstructure:"limit"`     // 每个窗口允许的请求数，token_bucket 为窗口内补充的令牌数
+	Window    time.Duration `mapstructure:"window"`    // 窗口时长，默认 1m
+	Burst     int           `mapstructure:"burst"`     // token_bucket 的桶容量，默认等于 limit
+}
+
+// ================== GrpcConfig ==================
+// GrpcConfig 包含 gRPC 服务的配置
+
+type GrpcConfig struct {
 	Port string `mapstructure:"port"`
 }
 
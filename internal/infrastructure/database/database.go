@@ -2,16 +2,27 @@ package database
 
 import (
 	"context"
-	"database/sql"
+	"fmt"
+	"testing"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/infrastructure/database/dialect"
+	"github.com/lyonnee/go-template/internal/infrastructure/database/migrate"
 	"github.com/lyonnee/go-template/pkg/di"
 	"github.com/lyonnee/go-template/pkg/log"
+	"github.com/lyonnee/go-template/sqls"
+	"go.uber.org/zap"
 )
 
 type Database struct {
-	db *sqlx.DB
+	db       *sqlx.DB    // 主库
+	replicas *replicaSet // 只读副本，未配置时为 nil
+	dialect  dialect.Dialect
+	logger   *log.Logger
+
+	retryPolicy   RetryPolicy
+	retryCounters txRetryCounters
 }
 
 // func (dbc *Database) Conn(ctx context.Context) (DBExecutor, error) {
@@ -22,38 +33,60 @@ type Database struct {
 // 	return dbc.db.BeginTxx(ctx, opts)
 // }
 
+// Conn 在只读副本的连接上执行 fn，没有健康的副本或 ctx 经过 WithPrimary 时使用主库
+// 副本的数据可能落后于主库，写操作及写后需要立即读取的场景应使用 WithPrimary 或 Transaction
+// ctx 中已存在事务时直接在该事务中执行 fn，读取到事务内未提交的写入，回调交给外层事务
 func (dbc *Database) Conn(ctx context.Context, fn func(context.Context) error) error {
-	conn, err := dbc.db.Connx(ctx)
+	if _, inTx := currentTx(ctx); inTx {
+		return fn(ctx)
+	}
+
+	conn, onReplica, err := dbc.connx(ctx)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	return fn(SetDBExecutor(ctx, conn))
-}
-
-func (dbc *Database) Transaction(ctx context.Context, opts *sql.TxOptions, fn func(context.Context) error) error {
-	tx, err := dbc.db.BeginTxx(ctx, opts)
-	if err != nil {
+	hooksCtx, hooks := withTxHooks(withReplica(ctx, onReplica))
+	if err := fn(SetDBExecutor(hooksCtx, conn)); err != nil {
+		hooks.runAfterRollback(ctx)
 		return err
 	}
 
-	defer func() {
-		if p := recover(); p != nil {
-			_ = tx.Rollback()
-		} else if err != nil {
-			_ = tx.Rollback()
-		} else {
-			err = tx.Commit()
+	hooks.runAfterCommit(ctx)
+	return nil
+}
+
+// connx 选择健康的副本获取连接，获取失败时摘除该副本并回退到主库
+func (dbc *Database) connx(ctx context.Context) (*sqlx.Conn, bool, error) {
+	if dbc.replicas != nil && !usePrimary(ctx) {
+		if r := dbc.replicas.pick(); r != nil {
+			conn, err := r.db.Connx(ctx)
+			if err == nil {
+				return conn, true, nil
+			}
+			if ctx.Err() != nil {
+				return nil, false, err
+			}
+			dbc.replicas.markUnhealthy(r, err)
 		}
-	}()
+	}
 
-	err = fn(SetDBExecutor(ctx, tx))
+	conn, err := dbc.db.Connx(ctx)
+	return conn, false, err
+}
 
-	return err
+// Dialect 返回当前数据库的方言
+func (dbc *Database) Dialect() dialect.Dialect {
+	return dbc.dialect
 }
 
 func (dbc *Database) Close() error {
+	if dbc.replicas != nil {
+		if err := dbc.replicas.Close(); err != nil {
+			return err
+		}
+	}
 	if dbc.db != nil {
 		return dbc.db.Close()
 	}
@@ -63,21 +96,84 @@ func (dbc *Database) Close() error {
 var db *Database
 
 func init() {
+	// 单元测试不连接数据库，需要数据库的测试自行创建 Database
+	if testing.Testing() {
+		return
+	}
+
 	config := di.Get[config.Config]()
 	logger := di.Get[*log.Logger]()
 
-	pgsql, err := newPostgresDB(config.Database.Postgres, logger)
+	newDB, err := newDatabase(config.Database, logger)
 	if err != nil {
-		panic("Failed to initialize PostgreSQL database: " + err.Error())
+		panic("Failed to initialize database: " + err.Error())
 	}
 
-	db = pgsql
+	db = newDB
+
+	if config.Database.AutoMigrate {
+		if err := autoMigrate(db, logger); err != nil {
+			panic("Failed to migrate database: " + err.Error())
+		}
+	}
 
 	di.AddSingleton[*Database](func() (*Database, error) {
 		return db, nil
 	})
 }
 
+// newDatabase 按 database.driver 连接数据库，未配置时使用 PostgreSQL
+func newDatabase(conf config.DatabaseConfig, logger *log.Logger) (*Database, error) {
+	var dbc *Database
+	var err error
+	switch conf.Driver {
+	case "", dialect.Postgres:
+		dbc, err = newPostgresDB(conf.Postgres, logger)
+	case dialect.Mysql:
+		dbc, err = newMysqlDB(conf.Mysql, logger)
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", conf.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dbc.logger = logger
+	dbc.retryPolicy = newRetryPolicy(conf.TxRetry)
+	return dbc, nil
+}
+
+// autoMigrate 执行嵌入的迁移脚本中未执行的迁移
+func autoMigrate(dbc *Database, logger *log.Logger) error {
+	migrator, err := migrate.New(dbc.db, dbc.dialect, sqls.Migrations(dbc.dialect.Name()), logger)
+	if err != nil {
+		return err
+	}
+
+	count, err := migrator.Up(context.Background())
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Database migrated", zap.Int("applied", count))
+	return nil
+}
+
+// Rebind 将 ? 占位符转换为当前数据库的占位符
+func Rebind(query string) string {
+	return db.dialect.Rebind(query)
+}
+
+// InsertReturningID 执行 INSERT 并返回自增主键，query 使用 ? 占位符且不包含 RETURNING 子句
+func InsertReturningID(ctx context.Context, dbExecutor DBExecutor, query string, args ...any) (uint64, error) {
+	return db.dialect.InsertReturningID(ctx, dbExecutor, query, args...)
+}
+
+// IsUniqueViolation 判断错误是否为唯一约束冲突
+func IsUniqueViolation(err error) bool {
+	return db.dialect.IsUniqueViolation(err)
+}
+
 func Close() error {
 	if db != nil {
 		return db.Close()
@@ -0,0 +1,67 @@
+package dialect
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	Postgres = "postgres"
+	Mysql    = "mysql"
+)
+
+// Executor 执行 SQL 的连接或事务，与 database.DBExecutor 相同
+type Executor interface {
+	sqlx.ExecerContext
+	sqlx.QueryerContext
+}
+
+// Dialect 屏蔽不同数据库之间的 SQL 差异
+// 存储库中的 SQL 统一使用 ? 作为占位符，执行前通过 Rebind 转换为数据库的占位符
+type Dialect interface {
+	// Name 方言名称，同时也是 database/sql 驱动名称和迁移脚本的目录名
+	Name() string
+
+	// Rebind 将 ? 占位符转换为数据库的占位符
+	Rebind(query string) string
+
+	// InsertReturningID 执行 INSERT 并返回自增主键，query 不包含 RETURNING 子句
+	InsertReturningID(ctx context.Context, executor Executor, query string, args ...any) (uint64, error)
+
+	// IsUniqueViolation 判断错误是否为唯一约束冲突
+	IsUniqueViolation(err error) bool
+
+	// IsRetryable 判断错误是否为重试整个事务即可能成功的并发冲突，如序列化失败和死锁
+	IsRetryable(err error) bool
+
+	// Lock 获取与连接绑定的命名锁，阻塞直到获取成功或 ctx 结束
+	Lock(ctx context.Context, conn *sqlx.Conn, name string) error
+
+	// Unlock 释放 Lock 获取的命名锁
+	Unlock(ctx context.Context, conn *sqlx.Conn, name string) error
+
+	// MultiStatements 一次 Exec 是否可以执行包含多条语句的脚本
+	MultiStatements() bool
+}
+
+// Get 按名称返回方言，名称为空时使用 PostgreSQL
+func Get(name string) (Dialect, error) {
+	switch name {
+	case "", Postgres:
+		return postgresDialect{}, nil
+	case Mysql:
+		return mysqlDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", name)
+	}
+}
+
+// lockKey 将锁名转换为 PostgreSQL advisory lock 使用的整数键
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,47 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		dialect string
+		name    string
+		err     error
+		want    bool
+	}{
+		{Postgres, "serialization failure", &pq.Error{Code: "40001"}, true},
+		{Postgres, "deadlock detected", &pq.Error{Code: "40P01"}, true},
+		{Postgres, "wrapped serialization failure", fmt.Errorf("update user: %w", &pq.Error{Code: "40001"}), true},
+		{Postgres, "unique violation", &pq.Error{Code: "23505"}, false},
+		{Postgres, "mysql deadlock", &mysql.MySQLError{Number: 1213}, false},
+		{Postgres, "plain error", errors.New("40001"), false},
+		{Postgres, "nil", nil, false},
+
+		{Mysql, "deadlock", &mysql.MySQLError{Number: 1213}, true},
+		{Mysql, "lock wait timeout", &mysql.MySQLError{Number: 1205}, true},
+		{Mysql, "wrapped deadlock", fmt.Errorf("update user: %w", &mysql.MySQLError{Number: 1213}), true},
+		{Mysql, "duplicate entry", &mysql.MySQLError{Number: 1062}, false},
+		{Mysql, "postgres serialization failure", &pq.Error{Code: "40001"}, false},
+		{Mysql, "plain error", errors.New("1213"), false},
+		{Mysql, "nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect+"/"+tt.name, func(t *testing.T) {
+			d, err := Get(tt.dialect)
+			if err != nil {
+				t.Fatalf("Get(%q): %v", tt.dialect, err)
+			}
+			if got := d.IsRetryable(tt.err); got != tt.want {
+				t.Fatalf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// duplicateEntry MySQL 唯一约束冲突的错误码
+const duplicateEntry = 1062
+
+const (
+	// lockWaitTimeout 等待行锁超时
+	lockWaitTimeout = 1205
+	// lockDeadlock 检测到死锁，当前事务已被回滚；可串行化隔离级别下的冲突同样表现为死锁
+	lockDeadlock = 1213
+)
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string {
+	return Mysql
+}
+
+func (mysqlDialect) Rebind(query string) string {
+	return query
+}
+
+func (mysqlDialect) InsertReturningID(ctx context.Context, executor Executor, query string, args ...any) (uint64, error) {
+	result, err := executor.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := result.LastInsertId()
+	return uint64(id), err
+}
+
+func (mysqlDialect) IsUniqueViolation(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == duplicateEntry
+}
+
+func (mysqlDialect) IsRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	return mysqlErr.Number == lockDeadlock || mysqlErr.Number == lockWaitTimeout
+}
+
+func (mysqlDialect) Lock(ctx context.Context, conn *sqlx.Conn, name string) error {
+	// 超时时间为负数时一直等待
+	var acquired sql.NullInt64
+	if err := conn.QueryRowxContext(ctx, "SELECT GET_LOCK(?, -1)", name).Scan(&acquired); err != nil {
+		return err
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return fmt.Errorf("GET_LOCK(%q) failed", name)
+	}
+	return nil
+}
+
+func (mysqlDialect) Unlock(ctx context.Context, conn *sqlx.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name)
+	return err
+}
+
+func (mysqlDialect) MultiStatements() bool {
+	return false
+}
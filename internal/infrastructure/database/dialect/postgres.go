@@ -0,0 +1,62 @@
+package dialect
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// uniqueViolation PostgreSQL 唯一约束冲突的错误码
+const uniqueViolation = "23505"
+
+const (
+	// serializationFailure 可串行化隔离级别下的读写冲突
+	serializationFailure = "40001"
+	// deadlockDetected 检测到死锁，当前事务被选为牺牲者
+	deadlockDetected = "40P01"
+)
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return Postgres
+}
+
+func (postgresDialect) Rebind(query string) string {
+	return sqlx.Rebind(sqlx.DOLLAR, query)
+}
+
+func (d postgresDialect) InsertReturningID(ctx context.Context, executor Executor, query string, args ...any) (uint64, error) {
+	var id uint64
+	err := executor.QueryRowxContext(ctx, d.Rebind(query)+" RETURNING id", args...).Scan(&id)
+	return id, err
+}
+
+func (postgresDialect) IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
+
+func (postgresDialect) IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+}
+
+func (postgresDialect) Lock(ctx context.Context, conn *sqlx.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey(name))
+	return err
+}
+
+func (postgresDialect) Unlock(ctx context.Context, conn *sqlx.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey(name))
+	return err
+}
+
+func (postgresDialect) MultiStatements() bool {
+	return true
+}
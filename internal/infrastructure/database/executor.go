@@ -30,3 +30,9 @@ func GetDBExecutor(ctx context.Context) (DBExecutor, error) {
 	}
 	return dbExecutor, nil
 }
+
+// InTransaction 判断当前上下文是否处于 Transaction 回调中
+func InTransaction(ctx context.Context) bool {
+	_, ok := currentTx(ctx)
+	return ok
+}
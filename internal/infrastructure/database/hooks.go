@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"sync"
+)
+
+const txHooksKey = "tx_hooks"
+
+// txHooks 保存当前 Transaction/Conn 回调中注册的提交后和回滚后回调
+type txHooks struct {
+	mu            sync.Mutex
+	afterCommit   []func(context.Context)
+	afterRollback []func(context.Context)
+}
+
+func withTxHooks(ctx context.Context) (context.Context, *txHooks) {
+	hooks := &txHooks{}
+	return context.WithValue(ctx, txHooksKey, hooks), hooks
+}
+
+func getTxHooks(ctx context.Context) (*txHooks, bool) {
+	hooks, ok := ctx.Value(txHooksKey).(*txHooks)
+	return hooks, ok
+}
+
+func (h *txHooks) runAfterCommit(ctx context.Context) {
+	h.mu.Lock()
+	fns := h.afterCommit
+	h.afterCommit = nil
+	h.afterRollback = nil
+	h.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(ctx)
+	}
+}
+
+func (h *txHooks) runAfterRollback(ctx context.Context) {
+	h.mu.Lock()
+	fns := h.afterRollback
+	h.afterCommit = nil
+	h.afterRollback = nil
+	h.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(ctx)
+	}
+}
+
+// mergeInto 保存点释放后，回调交给外层事务，在外层事务提交或回滚时执行
+func (h *txHooks) mergeInto(parent *txHooks) {
+	h.mu.Lock()
+	afterCommit, afterRollback := h.afterCommit, h.afterRollback
+	h.afterCommit = nil
+	h.afterRollback = nil
+	h.mu.Unlock()
+
+	parent.mu.Lock()
+	defer parent.mu.Unlock()
+
+	parent.afterCommit = append(parent.afterCommit, afterCommit...)
+	parent.afterRollback = append(parent.afterRollback, afterRollback...)
+}
+
+// AfterCommit 注册在 Transaction/Conn 回调成功提交后执行的回调，回滚时不会执行
+// 不在 Transaction/Conn 回调中调用时立即执行
+func AfterCommit(ctx context.Context, fn func(context.Context)) {
+	hooks, ok := getTxHooks(ctx)
+	if !ok {
+		fn(ctx)
+		return
+	}
+
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+
+	hooks.afterCommit = append(hooks.afterCommit, fn)
+}
+
+// AfterRollback 注册在 Transaction 回滚或 Conn 回调返回错误后执行的回调，提交时不会执行
+// 在 PropagationNested 中注册时，回滚到保存点后即执行；不在 Transaction/Conn 回调中调用时不会执行
+func AfterRollback(ctx context.Context, fn func(context.Context)) {
+	hooks, ok := getTxHooks(ctx)
+	if !ok {
+		return
+	}
+
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+
+	hooks.afterRollback = append(hooks.afterRollback, fn)
+}
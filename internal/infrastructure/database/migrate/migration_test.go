@@ -0,0 +1,130 @@
+package migrate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "single statement without trailing newline",
+			script: "DROP TABLE users;",
+			want:   []string{"DROP TABLE users;"},
+		},
+		{
+			name: "comments and multi-line statements",
+			script: `-- 创建用户表
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY, -- 主键
+    name VARCHAR(255) NOT NULL
+);
+
+-- 创建索引
+CREATE INDEX idx_users_name ON users (name);
+`,
+			want: []string{
+				"-- 创建用户表\nCREATE TABLE users (\n    id BIGINT PRIMARY KEY, -- 主键\n    name VARCHAR(255) NOT NULL\n);",
+				"-- 创建索引\nCREATE INDEX idx_users_name ON users (name);",
+			},
+		},
+		{
+			name:   "semicolon inside a comment does not split",
+			script: "-- 删除字段;\nALTER TABLE users\n    DROP COLUMN a;\n",
+			want:   []string{"-- 删除字段;\nALTER TABLE users\n    DROP COLUMN a;"},
+		},
+		{
+			name:   "trailing statement without semicolon",
+			script: "DELETE FROM a;\nDELETE FROM b",
+			want:   []string{"DELETE FROM a;", "DELETE FROM b"},
+		},
+		{
+			name:   "comment only fragments are dropped",
+			script: "DELETE FROM a;\n\n-- 结束\n",
+			want:   []string{"DELETE FROM a;"},
+		},
+		{
+			name:   "empty",
+			script: "",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("splitStatements = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000002_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD COLUMN email TEXT;\n")},
+		"000001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id BIGINT);\n")},
+		"000001_create_users.down.sql": {Data: []byte("DROP TABLE users;\n")},
+		"README.md":                    {Data: []byte("ignored")},
+		"000003_Bad-Name.up.sql":       {Data: []byte("ignored")},
+		"nested/000004_x.up.sql":       {Data: []byte("ignored")},
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("len = %d, want 2", len(migrations))
+	}
+
+	first, second := migrations[0], migrations[1]
+	if first.Version != 1 || first.Name != "create_users" || first.Down != "DROP TABLE users;\n" {
+		t.Fatalf("first migration = %+v", first)
+	}
+	if first.Checksum != checksum([]byte("CREATE TABLE users (id BIGINT);\n")) {
+		t.Fatalf("checksum = %s, want checksum of up script", first.Checksum)
+	}
+	if second.Version != 2 || second.Name != "add_email" || second.Down != "" {
+		t.Fatalf("second migration = %+v", second)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"000001_create_users.up.sql":  {Data: []byte("SELECT 1;")},
+				"000001_create_orders.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			wantErr: "duplicate migration version 1",
+		},
+		{
+			name: "missing up script",
+			fsys: fstest.MapFS{
+				"000001_create_users.up.sql": {Data: []byte("SELECT 1;")},
+				"000002_add_email.down.sql":  {Data: []byte("SELECT 1;")},
+			},
+			wantErr: "migration 2_add_email has no up script",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Load err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lyonnee/go-template/internal/infrastructure/database/dialect"
+	"go.uber.org/zap"
+)
+
+// fakeStore 模拟 schema_migrations 表的测试数据库，记录迁移脚本执行的语句
+// 事务回滚时恢复执行记录和语句
+type fakeStore struct {
+	mu       sync.Mutex
+	records  map[int64]appliedMigration
+	executed []string
+	failOn   string // 执行包含该内容的语句时返回错误
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{records: make(map[int64]appliedMigration)}
+}
+
+func (s *fakeStore) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{store: s}, nil
+}
+
+func (s *fakeStore) Driver() driver.Driver {
+	return nil
+}
+
+func (s *fakeStore) versions() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	versions := make([]int64, 0, len(s.records))
+	for v := range s.records {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	return versions
+}
+
+func (s *fakeStore) statements() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.executed...)
+}
+
+type fakeConn struct {
+	store *fakeStore
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	s := c.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make(map[int64]appliedMigration, len(s.records))
+	for k, v := range s.records {
+		records[k] = v
+	}
+	return &fakeTx{store: s, records: records, executed: len(s.executed)}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s := c.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+	case strings.HasPrefix(query, "INSERT INTO schema_migrations"):
+		version := args[0].Value.(int64)
+		s.records[version] = appliedMigration{
+			Version:   version,
+			Name:      args[1].Value.(string),
+			Checksum:  args[2].Value.(string),
+			AppliedAt: args[3].Value.(int64),
+		}
+	case strings.HasPrefix(query, "DELETE FROM schema_migrations"):
+		delete(s.records, args[0].Value.(int64))
+	default:
+		if s.failOn != "" && strings.Contains(query, s.failOn) {
+			return nil, fmt.Errorf("syntax error near %q", s.failOn)
+		}
+		s.executed = append(s.executed, query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if !strings.HasPrefix(query, "SELECT version, name, checksum, applied_at FROM schema_migrations") {
+		return nil, fmt.Errorf("unexpected query %q", query)
+	}
+
+	s := c.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := &fakeRows{}
+	for _, r := range s.records {
+		rows.values = append(rows.values, []driver.Value{r.Version, r.Name, r.Checksum, r.AppliedAt})
+	}
+	return rows, nil
+}
+
+type fakeTx struct {
+	store    *fakeStore
+	records  map[int64]appliedMigration
+	executed int
+}
+
+func (t *fakeTx) Commit() error {
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	s := t.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records = t.records
+	s.executed = s.executed[:t.executed]
+	return nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"version", "name", "checksum", "applied_at"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// fakeDialect 测试使用的方言，命名锁为空操作
+type fakeDialect struct {
+	dialect.Dialect
+	multiStatements bool
+}
+
+func (d fakeDialect) Name() string                                   { return "fake" }
+func (d fakeDialect) Rebind(query string) string                     { return query }
+func (d fakeDialect) Lock(context.Context, *sqlx.Conn, string) error { return nil }
+func (d fakeDialect) Unlock(context.Context, *sqlx.Conn, string) error {
+	return nil
+}
+func (d fakeDialect) MultiStatements() bool { return d.multiStatements }
+
+func newTestMigrator(t *testing.T, store *fakeStore, fsys fstest.MapFS, multiStatements bool) *Migrator {
+	t.Helper()
+
+	db := sql.OpenDB(store)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := New(sqlx.NewDb(db, "fake"), fakeDialect{multiStatements: multiStatements}, fsys, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return m
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"000001_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id BIGINT);\nCREATE INDEX idx_users_id ON users (id);\n")},
+		"000001_create_users.down.sql": {Data: []byte("DROP TABLE users;\n")},
+		"000002_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD COLUMN email TEXT;\n")},
+		"000002_add_email.down.sql":    {Data: []byte("ALTER TABLE users DROP COLUMN email;\n")},
+		"000003_add_phone.up.sql":      {Data: []byte("ALTER TABLE users ADD COLUMN phone TEXT;\n")},
+	}
+}
+
+func assertEqual[T any](t *testing.T, name string, got, want T) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestMigratorUp(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("split statements", func(t *testing.T) {
+		store := newFakeStore()
+		m := newTestMigrator(t, store, testMigrations(), false)
+
+		n, err := m.Up(ctx)
+		if err != nil {
+			t.Fatalf("Up: %v", err)
+		}
+		assertEqual(t, "applied", n, 3)
+		assertEqual(t, "versions", store.versions(), []int64{1, 2, 3})
+		assertEqual(t, "statements", store.statements(), []string{
+			"CREATE TABLE users (id BIGINT);",
+			"CREATE INDEX idx_users_id ON users (id);",
+			"ALTER TABLE users ADD COLUMN email TEXT;",
+			"ALTER TABLE users ADD COLUMN phone TEXT;",
+		})
+
+		// 已执行的迁移不会重复执行
+		n, err = m.Up(ctx)
+		if err != nil {
+			t.Fatalf("second Up: %v", err)
+		}
+		assertEqual(t, "applied again", n, 0)
+	})
+
+	t.Run("multi statements", func(t *testing.T) {
+		store := newFakeStore()
+		m := newTestMigrator(t, store, testMigrations(), true)
+
+		if _, err := m.Up(ctx); err != nil {
+			t.Fatalf("Up: %v", err)
+		}
+		assertEqual(t, "first script", store.statements()[0], "CREATE TABLE users (id BIGINT);\nCREATE INDEX idx_users_id ON users (id);\n")
+	})
+
+	t.Run("failed migration is rolled back", func(t *testing.T) {
+		store := newFakeStore()
+		store.failOn = "email"
+		m := newTestMigrator(t, store, testMigrations(), false)
+
+		n, err := m.Up(ctx)
+		if err == nil || !strings.Contains(err.Error(), "migration 2_add_email up") {
+			t.Fatalf("Up err = %v, want migration 2_add_email up error", err)
+		}
+		assertEqual(t, "applied", n, 1)
+		assertEqual(t, "versions", store.versions(), []int64{1})
+
+		// 修复后继续执行剩余的迁移
+		store.failOn = ""
+		n, err = m.Up(ctx)
+		if err != nil {
+			t.Fatalf("Up after fix: %v", err)
+		}
+		assertEqual(t, "applied after fix", n, 2)
+		assertEqual(t, "versions after fix", store.versions(), []int64{1, 2, 3})
+	})
+}
+
+func TestMigratorDown(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeStore()
+	fsys := testMigrations()
+	fsys["000003_add_phone.down.sql"] = &fstest.MapFile{Data: []byte("ALTER TABLE users DROP COLUMN phone;\n")}
+	m := newTestMigrator(t, store, fsys, false)
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	n, err := m.Down(ctx, 2)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	assertEqual(t, "rolled back", n, 2)
+	assertEqual(t, "versions", store.versions(), []int64{1})
+	statements := store.statements()
+	assertEqual(t, "down statements", statements[len(statements)-2:], []string{
+		"ALTER TABLE users DROP COLUMN phone;",
+		"ALTER TABLE users DROP COLUMN email;",
+	})
+
+	// 回滚数量超过已执行的迁移时只回滚已执行的
+	n, err = m.Down(ctx, 5)
+	if err != nil {
+		t.Fatalf("Down all: %v", err)
+	}
+	assertEqual(t, "rolled back all", n, 1)
+	assertEqual(t, "versions after rollback", store.versions(), []int64{})
+}
+
+func TestMigratorDownWithoutScript(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeStore()
+	m := newTestMigrator(t, store, testMigrations(), false)
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	_, err := m.Down(ctx, 1)
+	if !errors.Is(err, ErrNoDownScript) {
+		t.Fatalf("Down err = %v, want ErrNoDownScript", err)
+	}
+	assertEqual(t, "versions", store.versions(), []int64{1, 2, 3})
+}
+
+func TestMigratorVerify(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		modify  func(fsys fstest.MapFS)
+		wantErr error
+	}{
+		{
+			name: "checksum mismatch",
+			modify: func(fsys fstest.MapFS) {
+				fsys["000002_add_email.up.sql"] = &fstest.MapFile{Data: []byte("ALTER TABLE users ADD COLUMN email VARCHAR(255);\n")}
+			},
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			name: "missing migration",
+			modify: func(fsys fstest.MapFS) {
+				delete(fsys, "000003_add_phone.up.sql")
+			},
+			wantErr: ErrMissingMigration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			if _, err := newTestMigrator(t, store, testMigrations(), false).Up(ctx); err != nil {
+				t.Fatalf("Up: %v", err)
+			}
+			executed := len(store.statements())
+
+			fsys := testMigrations()
+			tt.modify(fsys)
+			fsys["000004_add_address.up.sql"] = &fstest.MapFile{Data: []byte("ALTER TABLE users ADD COLUMN address TEXT;\n")}
+			m := newTestMigrator(t, store, fsys, false)
+
+			if _, err := m.Up(ctx); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Up err = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := m.Down(ctx, 1); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Down err = %v, want %v", err, tt.wantErr)
+			}
+			// 校验失败时不执行任何迁移
+			assertEqual(t, "statements executed", len(store.statements()), executed)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/infrastructure/database/dialect"
+	"github.com/lyonnee/go-template/pkg/log"
+	"github.com/qustavo/sqlhooks/v2"
+)
+
+func newMysqlDB(config config.MysqlConfig, logger *log.Logger) (*Database, error) {
+	sql.Register(SQL_LOGGER_DRIVER, sqlhooks.Wrap(&mysql.MySQLDriver{}, &LoggerHooks{Logger: logger}))
+
+	dsn, err := mysqlDSN(config.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	mysqlDb, err := sqlx.Connect(SQL_LOGGER_DRIVER, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	mysqlDialect, err := dialect.Get(dialect.Mysql)
+	if err != nil {
+		return nil, err
+	}
+
+	db := mysqlDb
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+
+	replicas, err := newReplicaSet(config.Replicas, func(dsn string) (*sqlx.DB, error) {
+		replicaDsn, err := mysqlDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		replicaDb, err := sqlx.Open(SQL_LOGGER_DRIVER, replicaDsn)
+		if err != nil {
+			return nil, err
+		}
+		replicaDb.SetMaxOpenConns(config.MaxOpenConns)
+		replicaDb.SetMaxIdleConns(config.MaxIdleConns)
+		replicaDb.SetConnMaxLifetime(config.ConnMaxLifetime)
+		replicaDb.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+		return replicaDb, nil
+	}, logger)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Database{db: db, replicas: replicas, dialect: mysqlDialect}, nil
+}
+
+func mysqlDSN(dsn string) (string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+	// 返回匹配的行数而不是实际修改的行数，与 PostgreSQL 一致，
+	// 否则更新为相同的值时 RowsAffected 为 0，会被存储库误判为记录不存在
+	cfg.ClientFoundRows = true
+	return cfg.FormatDSN(), nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/lib/pq"
 	_ "github.com/lib/pq" // PostgreSQL驱动
 	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/infrastructure/database/dialect"
 	"github.com/lyonnee/go-template/pkg/log"
 	"github.com/qustavo/sqlhooks/v2"
 )
@@ -19,11 +20,32 @@ func newPostgresDB(config config.PostgresConfig, logger *log.Logger) (*Database,
 		return nil, err
 	}
 
+	pgDialect, err := dialect.Get(dialect.Postgres)
+	if err != nil {
+		return nil, err
+	}
+
 	db := pgDb
 	db.SetMaxOpenConns(config.MaxOpenConns)
 	db.SetMaxIdleConns(config.MaxIdleConns)
 	db.SetConnMaxLifetime(config.ConnMaxLifetime)
 	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
 
-	return &Database{db: db}, nil
+	replicas, err := newReplicaSet(config.Replicas, func(dsn string) (*sqlx.DB, error) {
+		replicaDb, err := sqlx.Open(SQL_LOGGER_DRIVER, dsn)
+		if err != nil {
+			return nil, err
+		}
+		replicaDb.SetMaxOpenConns(config.MaxOpenConns)
+		replicaDb.SetMaxIdleConns(config.MaxIdleConns)
+		replicaDb.SetConnMaxLifetime(config.ConnMaxLifetime)
+		replicaDb.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+		return replicaDb, nil
+	}, logger)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Database{db: db, replicas: replicas, dialect: pgDialect}, nil
 }
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+const (
+	ReplicaPolicyRoundRobin       = "round_robin"
+	ReplicaPolicyLeastConnections = "least_connections"
+
+	defaultHealthCheckInterval = 10 * time.Second
+	healthCheckTimeout         = 3 * time.Second
+
+	usePrimaryKey = "db_use_primary"
+	onReplicaKey  = "db_on_replica"
+)
+
+// WithPrimary 要求后续的 Conn 使用主库，用于写后立即读取等需要读到最新数据的场景
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, usePrimaryKey, true)
+}
+
+func usePrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(usePrimaryKey).(bool)
+	return v
+}
+
+// OnReplica 判断当前 Conn 回调是否使用只读副本，副本的数据可能落后于主库
+func OnReplica(ctx context.Context) bool {
+	v, _ := ctx.Value(onReplicaKey).(bool)
+	return v
+}
+
+func withReplica(ctx context.Context, onReplica bool) context.Context {
+	return context.WithValue(ctx, onReplicaKey, onReplica)
+}
+
+type replica struct {
+	index   int
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// replicaSet 只读副本，按策略选择健康的副本，全部不可用时由调用方回退到主库
+// 后台定期检查副本的健康状态，恢复后重新参与选择
+type replicaSet struct {
+	replicas []*replica
+	policy   string
+	next     atomic.Uint64
+	logger   *log.Logger
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// newReplicaSet 使用 open 打开 conf.DSNs 中的副本，未配置副本时返回 nil
+// 副本连接失败不影响启动，会在健康检查恢复后使用
+func newReplicaSet(conf config.ReplicaConfig, open func(dsn string) (*sqlx.DB, error), logger *log.Logger) (*replicaSet, error) {
+	if len(conf.DSNs) == 0 {
+		return nil, nil
+	}
+
+	policy := conf.Policy
+	switch policy {
+	case "":
+		policy = ReplicaPolicyRoundRobin
+	case ReplicaPolicyRoundRobin, ReplicaPolicyLeastConnections:
+	default:
+		return nil, fmt.Errorf("unsupported replica policy: %s", conf.Policy)
+	}
+	interval := conf.HealthCheckInterval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+
+	set := &replicaSet{
+		policy: policy,
+		logger: logger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	for i, dsn := range conf.DSNs {
+		db, err := open(dsn)
+		if err != nil {
+			set.closeDBs()
+			return nil, fmt.Errorf("open replica %d: %w", i, err)
+		}
+		set.replicas = append(set.replicas, &replica{index: i, db: db})
+	}
+
+	set.check()
+	go set.run(interval)
+
+	return set, nil
+}
+
+// pick 按策略选择一个健康的副本，没有健康的副本时返回 nil
+func (s *replicaSet) pick() *replica {
+	if s.policy == ReplicaPolicyLeastConnections {
+		var best *replica
+		bestInUse := 0
+		for _, r := range s.replicas {
+			if !r.healthy.Load() {
+				continue
+			}
+			if inUse := r.db.Stats().InUse; best == nil || inUse < bestInUse {
+				best, bestInUse = r, inUse
+			}
+		}
+		return best
+	}
+
+	n := uint64(len(s.replicas))
+	start := s.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := s.replicas[(start+i)%n]; r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// markUnhealthy 获取连接失败时立即摘除副本，等待健康检查恢复
+func (s *replicaSet) markUnhealthy(r *replica, err error) {
+	if r.healthy.CompareAndSwap(true, false) {
+		s.logger.Warn("Database replica unhealthy", zap.Int("replica", r.index), zap.Error(err))
+	}
+}
+
+func (s *replicaSet) run(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.check()
+		}
+	}
+}
+
+// check 并发 Ping 全部副本并更新健康状态
+func (s *replicaSet) check() {
+	var wg sync.WaitGroup
+	for _, r := range s.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+			defer cancel()
+
+			if err := r.db.PingContext(ctx); err != nil {
+				s.markUnhealthy(r, err)
+				return
+			}
+			if r.healthy.CompareAndSwap(false, true) {
+				s.logger.Info("Database replica healthy", zap.Int("replica", r.index))
+			}
+		}(r)
+	}
+	wg.Wait()
+}
+
+func (s *replicaSet) closeDBs() error {
+	var firstErr error
+	for _, r := range s.replicas {
+		if err := r.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s *replicaSet) Close() error {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+	return s.closeDBs()
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTxMaxAttempts    = 5
+	defaultTxInitialBackoff = 10 * time.Millisecond
+	defaultTxMaxBackoff     = 500 * time.Millisecond
+)
+
+// RetryPolicy 事务遇到并发冲突时的重试策略
+type RetryPolicy struct {
+	MaxAttempts    int           // 最多执行的次数，包含第一次
+	InitialBackoff time.Duration // 第一次重试前的最长等待时间
+	MaxBackoff     time.Duration // 每次重试前的最长等待时间
+}
+
+func newRetryPolicy(conf config.TxRetryConfig) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts:    conf.MaxAttempts,
+		InitialBackoff: conf.InitialBackoff,
+		MaxBackoff:     conf.MaxBackoff,
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultTxMaxAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultTxInitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultTxMaxBackoff
+	}
+	return policy
+}
+
+// backoff 第 attempt 次执行失败后的等待时间，上限按指数增长，在 [0, 上限] 内随机取值，
+// 避免冲突的事务同时重试再次冲突
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	limit := p.MaxBackoff
+	if attempt-1 < 32 {
+		if d := p.InitialBackoff << (attempt - 1); d > 0 && d < limit {
+			limit = d
+		}
+	}
+	return rand.N(limit + 1)
+}
+
+// TxRetryStats 事务重试的统计
+type TxRetryStats struct {
+	Retries   uint64 `json:"retries"`   // 重试的次数
+	Exhausted uint64 `json:"exhausted"` // 达到最多执行次数仍失败的事务数
+}
+
+type txRetryCounters struct {
+	retries   atomic.Uint64
+	exhausted atomic.Uint64
+}
+
+// RetryStats 返回进程启动以来的事务重试统计
+func (dbc *Database) RetryStats() TxRetryStats {
+	return TxRetryStats{
+		Retries:   dbc.retryCounters.retries.Load(),
+		Exhausted: dbc.retryCounters.exhausted.Load(),
+	}
+}
+
+// TransactionWithRetry 与 Transaction 相同，fn 返回序列化失败、死锁等并发冲突时回滚并按 database.tx_retry 重新执行整个事务
+// fn 可能执行多次，需要在 fn 中重新读取数据，不能依赖上一次执行修改的内存状态；
+// ctx 中已存在事务时加入该事务且不重试，冲突错误返回给外层事务处理
+func (dbc *Database) TransactionWithRetry(ctx context.Context, opts *sql.TxOptions, fn func(context.Context) error) error {
+	if InTransaction(ctx) {
+		return dbc.Transaction(ctx, opts, fn)
+	}
+
+	policy := dbc.retryPolicy
+	for attempt := 1; ; attempt++ {
+		err := dbc.Transaction(ctx, opts, fn)
+		if err == nil || !dbc.dialect.IsRetryable(err) {
+			return err
+		}
+
+		if attempt >= policy.MaxAttempts {
+			dbc.retryCounters.exhausted.Add(1)
+			dbc.logger.Warn("Transaction retries exhausted", zap.Int("attempts", attempt), zap.Error(err))
+			return err
+		}
+
+		backoff := policy.backoff(attempt)
+		dbc.retryCounters.retries.Add(1)
+		dbc.logger.Info("Retrying transaction",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/infrastructure/database/dialect"
+)
+
+func TestNewRetryPolicyDefaults(t *testing.T) {
+	policy := newRetryPolicy(config.TxRetryConfig{})
+	want := RetryPolicy{
+		MaxAttempts:    defaultTxMaxAttempts,
+		InitialBackoff: defaultTxInitialBackoff,
+		MaxBackoff:     defaultTxMaxBackoff,
+	}
+	if policy != want {
+		t.Fatalf("newRetryPolicy = %+v, want %+v", policy, want)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     100 * time.Millisecond,
+	}
+
+	tests := []struct {
+		attempt int
+		limit   time.Duration
+	}{
+		{1, 10 * time.Millisecond},
+		{2, 20 * time.Millisecond},
+		{3, 40 * time.Millisecond},
+		{4, 80 * time.Millisecond},
+		{5, 100 * time.Millisecond},
+		{40, 100 * time.Millisecond},  // 位移超过 32 位时使用上限
+		{100, 100 * time.Millisecond}, // 位移溢出时使用上限
+	}
+
+	for _, tt := range tests {
+		var max time.Duration
+		for range 1000 {
+			d := policy.backoff(tt.attempt)
+			if d < 0 || d > tt.limit {
+				t.Fatalf("backoff(%d) = %v, want within [0, %v]", tt.attempt, d, tt.limit)
+			}
+			if d > max {
+				max = d
+			}
+		}
+		// 随机取值应覆盖大部分区间，而不是固定返回某个值
+		if max < tt.limit/2 {
+			t.Fatalf("backoff(%d) max over 1000 samples = %v, want close to %v", tt.attempt, max, tt.limit)
+		}
+	}
+}
+
+func newRetryTestDatabase(t *testing.T) *Database {
+	t.Helper()
+
+	dbc, _ := newTestDatabase(t)
+	d, err := dialect.Get(dialect.Postgres)
+	if err != nil {
+		t.Fatalf("dialect.Get: %v", err)
+	}
+	dbc.dialect = d
+	dbc.retryPolicy = RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}
+	return dbc
+}
+
+func TestTransactionWithRetry(t *testing.T) {
+	ctx := context.Background()
+	conflict := &pq.Error{Code: "40001"}
+	errNotRetryable := errors.New("constraint failed")
+
+	tests := []struct {
+		name      string
+		failures  int // fn 在成功前返回错误的次数
+		err       error
+		wantCalls int
+		wantErr   error
+		wantStats TxRetryStats
+	}{
+		{
+			name:      "succeeds after conflict",
+			failures:  1,
+			err:       conflict,
+			wantCalls: 2,
+			wantStats: TxRetryStats{Retries: 1},
+		},
+		{
+			name:      "retries exhausted",
+			failures:  10,
+			err:       conflict,
+			wantCalls: 3,
+			wantErr:   conflict,
+			wantStats: TxRetryStats{Retries: 2, Exhausted: 1},
+		},
+		{
+			name:      "not retryable",
+			failures:  10,
+			err:       errNotRetryable,
+			wantCalls: 1,
+			wantErr:   errNotRetryable,
+			wantStats: TxRetryStats{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbc := newRetryTestDatabase(t)
+
+			calls := 0
+			err := dbc.TransactionWithRetry(ctx, nil, func(context.Context) error {
+				calls++
+				if calls <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TransactionWithRetry err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Fatalf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if stats := dbc.RetryStats(); stats != tt.wantStats {
+				t.Fatalf("RetryStats = %+v, want %+v", stats, tt.wantStats)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const savepointDepthKey = "db_savepoint_depth"
+
+// Propagation 事务传播方式，决定 ctx 中已存在事务时 Transaction 的行为
+type Propagation int
+
+const (
+	// PropagationRequired 加入已存在的事务，不存在时开启新事务，Transaction 的默认传播方式
+	// 加入已存在的事务时忽略 opts，回调返回错误时由外层事务决定是否回滚
+	PropagationRequired Propagation = iota
+	// PropagationRequiresNew 总是在新的连接上开启独立的事务，与外层事务分别提交或回滚
+	// 外层事务持有的行锁不会释放，内层事务更新相同的行时会死锁
+	PropagationRequiresNew
+	// PropagationNested 在已存在的事务中创建保存点，回调返回错误时只回滚到保存点，外层事务可以继续执行
+	// 不存在事务时与 PropagationRequired 相同
+	PropagationNested
+)
+
+func (p Propagation) String() string {
+	switch p {
+	case PropagationRequired:
+		return "REQUIRED"
+	case PropagationRequiresNew:
+		return "REQUIRES_NEW"
+	case PropagationNested:
+		return "NESTED"
+	default:
+		return fmt.Sprintf("Propagation(%d)", int(p))
+	}
+}
+
+// Transaction 在主库的事务中执行 fn，ctx 中已存在事务时加入该事务
+func (dbc *Database) Transaction(ctx context.Context, opts *sql.TxOptions, fn func(context.Context) error) error {
+	return dbc.TransactionWithPropagation(ctx, PropagationRequired, opts, fn)
+}
+
+// TransactionWithPropagation 按 propagation 在主库的事务中执行 fn
+func (dbc *Database) TransactionWithPropagation(ctx context.Context, propagation Propagation, opts *sql.TxOptions, fn func(context.Context) error) error {
+	tx, inTx := currentTx(ctx)
+
+	switch propagation {
+	case PropagationRequired:
+		if inTx {
+			return fn(ctx)
+		}
+	case PropagationRequiresNew:
+	case PropagationNested:
+		if inTx {
+			return dbc.savepoint(ctx, tx, fn)
+		}
+	default:
+		return fmt.Errorf("unsupported transaction propagation: %s", propagation)
+	}
+
+	return dbc.begin(ctx, opts, fn)
+}
+
+// begin 开启新的事务执行 fn，提交后执行 AfterCommit 回调，回滚后执行 AfterRollback 回调
+func (dbc *Database) begin(ctx context.Context, opts *sql.TxOptions, fn func(context.Context) error) error {
+	tx, err := dbc.db.BeginTxx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	txCtx := context.WithValue(withReplica(ctx, false), savepointDepthKey, 0)
+	hooksCtx, hooks := withTxHooks(txCtx)
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			hooks.runAfterRollback(ctx)
+			panic(p)
+		}
+	}()
+
+	if err := fn(SetDBExecutor(hooksCtx, tx)); err != nil {
+		_ = tx.Rollback()
+		hooks.runAfterRollback(ctx)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		hooks.runAfterRollback(ctx)
+		return err
+	}
+
+	hooks.runAfterCommit(ctx)
+	return nil
+}
+
+// savepoint 在已存在的事务中通过保存点执行 fn
+// 释放保存点后回调交给外层事务；回滚到保存点后立即执行 AfterRollback 回调，丢弃 AfterCommit 回调
+func (dbc *Database) savepoint(ctx context.Context, tx *sqlx.Tx, fn func(context.Context) error) error {
+	depth, _ := ctx.Value(savepointDepthKey).(int)
+	depth++
+	name := fmt.Sprintf("sp_%d", depth)
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	hooksCtx, hooks := withTxHooks(context.WithValue(ctx, savepointDepthKey, depth))
+
+	rollback := func() error {
+		_, err := tx.ExecContext(context.WithoutCancel(ctx), "ROLLBACK TO SAVEPOINT "+name)
+		hooks.runAfterRollback(ctx)
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(hooksCtx); err != nil {
+		if rbErr := rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback to savepoint: %v)", err, rbErr)
+		}
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	if parent, ok := getTxHooks(ctx); ok {
+		hooks.mergeInto(parent)
+	}
+	return nil
+}
+
+// currentTx 返回 ctx 中的事务
+func currentTx(ctx context.Context) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(DBExecutorKey).(*sqlx.Tx)
+	return tx, ok
+}
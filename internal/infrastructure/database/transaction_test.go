@@ -0,0 +1,274 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// recordingDriver 记录执行的语句和事务操作的测试驱动，不连接真实的数据库
+type recordingDriver struct {
+	mu    sync.Mutex
+	stmts []string
+}
+
+func (d *recordingDriver) record(stmt string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stmts = append(d.stmts, stmt)
+}
+
+func (d *recordingDriver) statements() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.stmts...)
+}
+
+func (d *recordingDriver) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) Driver() driver.Driver {
+	return nil
+}
+
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.driver.record("BEGIN")
+	return &recordingTx{driver: c.driver}, nil
+}
+
+func (c *recordingConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.driver.record(query)
+	return emptyRows{}, nil
+}
+
+type recordingTx struct {
+	driver *recordingDriver
+}
+
+func (t *recordingTx) Commit() error {
+	t.driver.record("COMMIT")
+	return nil
+}
+
+func (t *recordingTx) Rollback() error {
+	t.driver.record("ROLLBACK")
+	return nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return nil }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+func newTestDatabase(t *testing.T) (*Database, *recordingDriver) {
+	t.Helper()
+
+	drv := &recordingDriver{}
+	sqlDB := sql.OpenDB(drv)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	return &Database{
+		db:     sqlx.NewDb(sqlDB, "postgres"),
+		logger: zap.NewNop(),
+	}, drv
+}
+
+// hookRecorder 记录 AfterCommit / AfterRollback 回调的执行顺序
+type hookRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *hookRecorder) register(ctx context.Context, name string) {
+	AfterCommit(ctx, func(context.Context) { r.add(name + ":commit") })
+	AfterRollback(ctx, func(context.Context) { r.add(name + ":rollback") })
+}
+
+func (r *hookRecorder) add(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *hookRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls
+	r.calls = nil
+	return calls
+}
+
+func assertStrings(t *testing.T, name string, got, want []string) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("%s = %q, want %q", name, got, want)
+	}
+}
+
+func TestNestedTransactionRollsBackToSavepoint(t *testing.T) {
+	dbc, drv := newTestDatabase(t)
+	hooks := &hookRecorder{}
+	errInner := errors.New("inner failed")
+
+	err := dbc.Transaction(context.Background(), nil, func(ctx context.Context) error {
+		hooks.register(ctx, "outer")
+
+		err := dbc.TransactionWithPropagation(ctx, PropagationNested, nil, func(ctx context.Context) error {
+			hooks.register(ctx, "inner")
+			return errInner
+		})
+		if !errors.Is(err, errInner) {
+			t.Fatalf("nested err = %v, want %v", err, errInner)
+		}
+		// 回滚到保存点后立即执行内层的回滚回调，丢弃提交回调
+		assertStrings(t, "hooks after savepoint rollback", hooks.take(), []string{"inner:rollback"})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+
+	assertStrings(t, "statements", drv.statements(), []string{
+		"BEGIN",
+		"SAVEPOINT sp_1",
+		"ROLLBACK TO SAVEPOINT sp_1",
+		"COMMIT",
+	})
+	assertStrings(t, "hooks after commit", hooks.take(), []string{"outer:commit"})
+}
+
+func TestNestedTransactionMergesHooks(t *testing.T) {
+	errOuter := errors.New("outer failed")
+
+	tests := []struct {
+		name       string
+		outerErr   error
+		statements []string
+		hooks      []string
+	}{
+		{
+			name:       "outer commit",
+			statements: []string{"BEGIN", "SAVEPOINT sp_1", "SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_1", "COMMIT"},
+			hooks:      []string{"outer:commit", "inner:commit", "innermost:commit"},
+		},
+		{
+			name:       "outer rollback",
+			outerErr:   errOuter,
+			statements: []string{"BEGIN", "SAVEPOINT sp_1", "SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_1", "ROLLBACK"},
+			hooks:      []string{"outer:rollback", "inner:rollback", "innermost:rollback"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbc, drv := newTestDatabase(t)
+			hooks := &hookRecorder{}
+
+			err := dbc.Transaction(context.Background(), nil, func(ctx context.Context) error {
+				hooks.register(ctx, "outer")
+
+				err := dbc.TransactionWithPropagation(ctx, PropagationNested, nil, func(ctx context.Context) error {
+					hooks.register(ctx, "inner")
+					return dbc.TransactionWithPropagation(ctx, PropagationNested, nil, func(ctx context.Context) error {
+						hooks.register(ctx, "innermost")
+						return nil
+					})
+				})
+				if err != nil {
+					t.Fatalf("nested: %v", err)
+				}
+				// 释放保存点后回调交给外层事务，此时都不执行
+				assertStrings(t, "hooks before outer finishes", hooks.take(), nil)
+				return tt.outerErr
+			})
+			if !errors.Is(err, tt.outerErr) {
+				t.Fatalf("Transaction err = %v, want %v", err, tt.outerErr)
+			}
+
+			assertStrings(t, "statements", drv.statements(), tt.statements)
+			assertStrings(t, "hooks", hooks.take(), tt.hooks)
+		})
+	}
+}
+
+func TestConnJoinsTransaction(t *testing.T) {
+	dbc, drv := newTestDatabase(t)
+	hooks := &hookRecorder{}
+
+	err := dbc.Transaction(context.Background(), nil, func(txCtx context.Context) error {
+		txExecutor, err := GetDBExecutor(txCtx)
+		if err != nil {
+			t.Fatalf("GetDBExecutor: %v", err)
+		}
+
+		return dbc.Conn(txCtx, func(ctx context.Context) error {
+			executor, err := GetDBExecutor(ctx)
+			if err != nil {
+				t.Fatalf("GetDBExecutor in Conn: %v", err)
+			}
+			if executor != txExecutor {
+				t.Fatal("Conn did not reuse the transaction")
+			}
+			if _, err := executor.ExecContext(ctx, "SELECT 1"); err != nil {
+				t.Fatalf("exec: %v", err)
+			}
+
+			// 回调交给外层事务，提交后才执行
+			hooks.register(ctx, "conn")
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+
+	assertStrings(t, "statements", drv.statements(), []string{"BEGIN", "SELECT 1", "COMMIT"})
+	assertStrings(t, "hooks", hooks.take(), []string{"conn:commit"})
+}
+
+func TestConnOutsideTransaction(t *testing.T) {
+	dbc, drv := newTestDatabase(t)
+	hooks := &hookRecorder{}
+	errConn := errors.New("conn failed")
+
+	err := dbc.Conn(context.Background(), func(ctx context.Context) error {
+		if InTransaction(ctx) {
+			t.Fatal("Conn outside a transaction reported InTransaction")
+		}
+		hooks.register(ctx, "conn")
+		return errConn
+	})
+	if !errors.Is(err, errConn) {
+		t.Fatalf("Conn err = %v, want %v", err, errConn)
+	}
+
+	assertStrings(t, "statements", drv.statements(), nil)
+	assertStrings(t, "hooks", hooks.take(), []string{"conn:rollback"})
+}
@@ -0,0 +1,185 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/lyonnee/go-template/internal/domain/event"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/infrastructure/database"
+	"github.com/lyonnee/go-template/internal/infrastructure/mq"
+	"github.com/lyonnee/go-template/internal/infrastructure/outbox"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+const HeaderEventName = "x-event-name"
+
+// Handler 进程内事件处理器
+type Handler func(ctx context.Context, e event.Event) error
+
+// EventBus 领域事件总线
+// 事件在 Transaction/Conn 回调中收集，提交成功后分发给进程内处理器，并按配置发布到消息队列
+// 启用事务发件箱时，事件在收集时随业务事务写入 outbox 表，由 outbox.Relay 发布
+type EventBus struct {
+	logger    *log.Logger
+	conf      config.EventConfig
+	publisher mq.Publisher
+	outbox    outbox.Repository
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func init() {
+	di.AddSingleton[*EventBus](NewEventBus)
+}
+
+func NewEventBus() (*EventBus, error) {
+	conf := di.Get[config.Config]()
+
+	bus := &EventBus{
+		logger:   di.Get[*log.Logger](),
+		conf:     conf.Event,
+		handlers: make(map[string][]Handler),
+	}
+	if conf.Event.PublishToMQ {
+		if conf.Event.Outbox.Enable {
+			bus.outbox = di.Get[outbox.Repository]()
+		} else {
+			bus.publisher = di.Get[mq.Broker]()
+		}
+	}
+
+	return bus, nil
+}
+
+// Subscribe 注册进程内事件处理器
+func (b *EventBus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Collect 收集领域事件，所在的 Transaction/Conn 回调成功提交后才会分发，回滚时丢弃
+// 启用事务发件箱时写入 outbox 失败会返回错误，调用方应回滚事务
+func (b *EventBus) Collect(ctx context.Context, events ...event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if b.outbox != nil {
+		msgs := make([]*outbox.Message, 0, len(events))
+		for _, e := range events {
+			payload, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+
+			msgs = append(msgs, &outbox.Message{
+				Topic:   b.Topic(e.EventName()),
+				Payload: payload,
+				Headers: map[string]string{HeaderEventName: e.EventName()},
+			})
+		}
+
+		if err := b.outbox.Add(ctx, msgs...); err != nil {
+			return err
+		}
+	}
+
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		b.Dispatch(ctx, events...)
+	})
+	return nil
+}
+
+// Dispatch 立即分发领域事件，处理器的错误只记录日志，不影响其他处理器
+func (b *EventBus) Dispatch(ctx context.Context, events ...event.Event) {
+	for _, e := range events {
+		b.mu.RLock()
+		handlers := b.handlers[e.EventName()]
+		b.mu.RUnlock()
+
+		for _, handler := range handlers {
+			if err := b.handle(ctx, handler, e); err != nil {
+				b.logger.Error("Event handler failed", zap.String("event", e.EventName()), zap.Error(err))
+			}
+		}
+
+		if b.publisher != nil {
+			if err := b.publish(ctx, e); err != nil {
+				b.logger.Error("Failed to publish event", zap.String("event", e.EventName()), zap.Error(err))
+			}
+		}
+	}
+}
+
+// Topic 返回事件在消息队列中的 topic
+func (b *EventBus) Topic(name string) string {
+	return b.conf.TopicPrefix + name
+}
+
+func (b *EventBus) handle(ctx context.Context, handler Handler, e event.Event) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("event handler panic: %v", p)
+		}
+	}()
+
+	return handler(ctx, e)
+}
+
+func (b *EventBus) publish(ctx context.Context, e event.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return b.publisher.Publish(ctx, b.Topic(e.EventName()), &mq.Message{
+		Payload: payload,
+		Headers: map[string]string{HeaderEventName: e.EventName()},
+	})
+}
+
+// On 注册指定类型事件的进程内处理器，事务提交后同步执行
+func On[T event.Event](b *EventBus, handler func(ctx context.Context, e T) error) {
+	b.Subscribe(eventName[T](), func(ctx context.Context, e event.Event) error {
+		typed, ok := e.(T)
+		if !ok {
+			return nil
+		}
+		return handler(ctx, typed)
+	})
+}
+
+// OnAsync 以消费组 group 通过消息队列异步处理指定类型事件，需开启 event.publish_to_mq
+func OnAsync[T event.Event](b *EventBus, group string, handler func(ctx context.Context, e T) error) {
+	mq.RegisterConsumer(mq.Consumer{
+		Topic: b.Topic(eventName[T]()),
+		Group: group,
+		Handler: func(ctx context.Context, msg *mq.Message) error {
+			e := newEvent[T]()
+			if err := json.Unmarshal(msg.Payload, e); err != nil {
+				return mq.NonRetryable(fmt.Errorf("decode event: %w", err))
+			}
+			return handler(ctx, e)
+		},
+	})
+}
+
+// eventName 事件类型均以指针实现 Event，零值 nil 指针可直接获取事件名称
+func eventName[T event.Event]() string {
+	var zero T
+	return zero.EventName()
+}
+
+func newEvent[T event.Event]() T {
+	var zero T
+	return reflect.New(reflect.TypeOf(zero).Elem()).Interface().(T)
+}
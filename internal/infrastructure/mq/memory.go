@@ -0,0 +1,208 @@
+package mq
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+const memoryQueueSize = 1024
+
+// 保证对接口实现
+var _ Broker = (*MemoryBroker)(nil)
+
+// MemoryBroker 进程内消息队列，用于测试和单节点部署
+// 与 Redis Streams 语义一致：新建的消费组只接收之后发布的消息，消息不持久化
+// 死信例外：死信 topic 还没有消费组时暂存死信，第一个消费组订阅时投递
+type MemoryBroker struct {
+	conf   config.MQConfig
+	retry  RetryPolicy
+	logger *log.Logger
+
+	seq    atomic.Uint64
+	mu     sync.Mutex
+	queues map[string]map[string]chan *Message // topic -> group -> queue
+
+	// pendingDeadLetters 没有消费组的死信 topic 暂存的消息，第一个消费组订阅时投递
+	pendingDeadLetters map[string][]*Message
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func NewMemoryBroker(conf config.MQConfig, logger *log.Logger) *MemoryBroker {
+	return &MemoryBroker{
+		conf:   conf,
+		retry:  newRetryPolicy(conf.Retry),
+		logger: logger,
+		queues: make(map[string]map[string]chan *Message),
+		closed: make(chan struct{}),
+
+		pendingDeadLetters: make(map[string][]*Message),
+	}
+}
+
+// Publish 将消息投递给 topic 的每个消费组
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, msg *Message) error {
+	select {
+	case <-b.closed:
+		return ErrBrokerClosed
+	default:
+	}
+
+	msg.ID = strconv.FormatUint(b.seq.Add(1), 10)
+	msg.Topic = topic
+
+	b.mu.Lock()
+	queues := make([]chan *Message, 0, len(b.queues[topic]))
+	for _, q := range b.queues[topic] {
+		queues = append(queues, q)
+	}
+	b.mu.Unlock()
+
+	for _, q := range queues {
+		delivery := &Message{
+			ID:      msg.ID,
+			Topic:   topic,
+			Payload: msg.Payload,
+			Headers: msg.Headers,
+			Attempt: 1,
+		}
+
+		select {
+		case q <- delivery:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.closed:
+			return ErrBrokerClosed
+		}
+	}
+
+	return nil
+}
+
+// Subscribe 以消费组订阅 topic，同一消费组的订阅者共享同一个队列
+func (b *MemoryBroker) Subscribe(ctx context.Context, topic, group string, handler Handler) error {
+	q := b.queue(topic, group)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-b.closed:
+			return nil
+		case msg := <-q:
+			b.process(ctx, group, q, handler, msg)
+		}
+	}
+}
+
+func (b *MemoryBroker) queue(topic, group string) chan *Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	groups, ok := b.queues[topic]
+	if !ok {
+		groups = make(map[string]chan *Message)
+		b.queues[topic] = groups
+	}
+
+	q, ok := groups[group]
+	if !ok {
+		q = make(chan *Message, memoryQueueSize)
+		groups[group] = q
+
+		// 暂存的死信数量不超过队列容量，不会阻塞
+		for _, msg := range b.pendingDeadLetters[topic] {
+			q <- msg
+		}
+		delete(b.pendingDeadLetters, topic)
+	}
+
+	return q
+}
+
+func (b *MemoryBroker) process(ctx context.Context, group string, q chan *Message, handler Handler, msg *Message) {
+	err := handle(context.WithoutCancel(ctx), handler, msg)
+	if err == nil {
+		return
+	}
+
+	if IsRetryable(err) && !b.retry.Exhausted(msg.Attempt) {
+		backoff := b.retry.Backoff(msg.Attempt)
+		b.logger.Warn("MQ message handling failed, will retry",
+			zap.String("topic", msg.Topic),
+			zap.String("group", group),
+			zap.String("id", msg.ID),
+			zap.Int("attempt", msg.Attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+
+		time.AfterFunc(backoff, func() {
+			msg.Attempt++
+			select {
+			case q <- msg:
+			case <-b.closed:
+			}
+		})
+		return
+	}
+
+	dlq := deadLetterTopic(b.conf, msg.Topic)
+	b.logger.Error("MQ message moved to dead letter topic",
+		zap.String("topic", msg.Topic),
+		zap.String("group", group),
+		zap.String("id", msg.ID),
+		zap.String("deadLetterTopic", dlq),
+		zap.Int("attempt", msg.Attempt),
+		zap.Error(err))
+
+	if err := b.publishDeadLetter(context.WithoutCancel(ctx), dlq, deadLetterMessage(msg, group, err)); err != nil {
+		b.logger.Error("MQ dead letter publish failed", zap.String("topic", dlq), zap.String("id", msg.ID), zap.Error(err))
+	}
+}
+
+// publishDeadLetter 发布死信，死信 topic 还没有消费组时暂存消息，避免死信丢失
+// 暂存的消息超过队列容量时丢弃最早的消息
+func (b *MemoryBroker) publishDeadLetter(ctx context.Context, topic string, msg *Message) error {
+	b.mu.Lock()
+	if len(b.queues[topic]) > 0 {
+		b.mu.Unlock()
+		return b.Publish(ctx, topic, msg)
+	}
+
+	msg.ID = strconv.FormatUint(b.seq.Add(1), 10)
+	msg.Topic = topic
+	msg.Attempt = 1
+
+	pending := append(b.pendingDeadLetters[topic], msg)
+	var dropped *Message
+	if len(pending) > memoryQueueSize {
+		dropped, pending = pending[0], pending[1:]
+	}
+	b.pendingDeadLetters[topic] = pending
+	b.mu.Unlock()
+
+	b.logger.Warn("MQ dead letter topic has no subscriber, message kept until one subscribes",
+		zap.String("topic", topic),
+		zap.String("id", msg.ID),
+		zap.Int("pending", len(pending)))
+	if dropped != nil {
+		b.logger.Error("MQ pending dead letter dropped", zap.String("topic", topic), zap.String("id", dropped.ID))
+	}
+	return nil
+}
+
+// Close 停止所有订阅，未处理的消息将被丢弃
+func (b *MemoryBroker) Close() error {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+	})
+	return nil
+}
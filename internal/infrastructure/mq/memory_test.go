@@ -0,0 +1,342 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"go.uber.org/zap"
+)
+
+const testTimeout = 2 * time.Second
+
+func newTestBroker(t *testing.T, maxRetries int) *MemoryBroker {
+	t.Helper()
+
+	b := NewMemoryBroker(config.MQConfig{
+		Retry: config.MQRetryConfig{
+			MaxRetries:     maxRetries,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+		},
+	}, zap.NewNop())
+	t.Cleanup(func() { b.Close() })
+	return b
+}
+
+// subscribe 在后台订阅，返回前消费组已创建，之后发布的消息一定会被投递
+func subscribe(t *testing.T, b *MemoryBroker, topic, group string, handler Handler) (context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	b.queue(topic, group)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Subscribe(ctx, topic, group, handler)
+	}()
+	t.Cleanup(cancel)
+	return cancel, done
+}
+
+func receive(t *testing.T, ch <-chan *Message) *Message {
+	t.Helper()
+
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for message")
+		return nil
+	}
+}
+
+func TestMemoryBrokerAck(t *testing.T) {
+	b := newTestBroker(t, 3)
+
+	received := make(chan *Message, 2)
+	subscribe(t, b, "orders", "billing", func(ctx context.Context, msg *Message) error {
+		received <- msg
+		return nil
+	})
+
+	if err := b.Publish(context.Background(), "orders", &Message{Payload: []byte("1")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	msg := receive(t, received)
+	if string(msg.Payload) != "1" || msg.Attempt != 1 || msg.Topic != "orders" || msg.ID == "" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+
+	select {
+	case msg := <-received:
+		t.Fatalf("acked message redelivered: %+v", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMemoryBrokerNackRedelivery(t *testing.T) {
+	b := newTestBroker(t, 3)
+
+	attempts := make(chan int, 4)
+	subscribe(t, b, "orders", "billing", func(ctx context.Context, msg *Message) error {
+		attempts <- msg.Attempt
+		if msg.Attempt < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+
+	if err := b.Publish(context.Background(), "orders", &Message{Payload: []byte("1")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	for want := 1; want <= 3; want++ {
+		select {
+		case got := <-attempts:
+			if got != want {
+				t.Fatalf("attempt = %d, want %d", got, want)
+			}
+		case <-time.After(testTimeout):
+			t.Fatalf("timed out waiting for attempt %d", want)
+		}
+	}
+}
+
+func TestMemoryBrokerEachGroupReceivesMessage(t *testing.T) {
+	b := newTestBroker(t, 3)
+
+	billing := make(chan *Message, 1)
+	shipping := make(chan *Message, 1)
+	subscribe(t, b, "orders", "billing", func(ctx context.Context, msg *Message) error {
+		billing <- msg
+		return nil
+	})
+	subscribe(t, b, "orders", "shipping", func(ctx context.Context, msg *Message) error {
+		shipping <- msg
+		return nil
+	})
+
+	if err := b.Publish(context.Background(), "orders", &Message{Payload: []byte("1")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	receive(t, billing)
+	receive(t, shipping)
+}
+
+func TestMemoryBrokerMaxRetriesToDeadLetter(t *testing.T) {
+	b := newTestBroker(t, 2)
+
+	var calls atomic.Int32
+	subscribe(t, b, "orders", "billing", func(ctx context.Context, msg *Message) error {
+		calls.Add(1)
+		return errors.New("always fails")
+	})
+	dead := make(chan *Message, 1)
+	subscribe(t, b, "orders.dlq", "ops", func(ctx context.Context, msg *Message) error {
+		dead <- msg
+		return nil
+	})
+
+	msg := &Message{Payload: []byte("1"), Headers: map[string]string{"trace": "abc"}}
+	if err := b.Publish(context.Background(), "orders", msg); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	dl := receive(t, dead)
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("handler called %d times, want 3 (1 + 2 retries)", got)
+	}
+	if string(dl.Payload) != "1" || dl.Topic != "orders.dlq" {
+		t.Fatalf("unexpected dead letter: %+v", dl)
+	}
+	want := map[string]string{
+		"trace":                 "abc",
+		HeaderDeadLetterError:   "always fails",
+		HeaderDeadLetterSource:  msg.ID,
+		HeaderDeadLetterGroup:   "billing",
+		HeaderDeadLetterAttempt: "3",
+	}
+	for k, v := range want {
+		if dl.Headers[k] != v {
+			t.Errorf("header %s = %q, want %q", k, dl.Headers[k], v)
+		}
+	}
+}
+
+func TestMemoryBrokerNonRetryableToDeadLetter(t *testing.T) {
+	b := newTestBroker(t, 5)
+
+	var calls atomic.Int32
+	subscribe(t, b, "orders", "billing", func(ctx context.Context, msg *Message) error {
+		calls.Add(1)
+		return NonRetryable(errors.New("malformed payload"))
+	})
+	dead := make(chan *Message, 1)
+	subscribe(t, b, "orders.dlq", "ops", func(ctx context.Context, msg *Message) error {
+		dead <- msg
+		return nil
+	})
+
+	if err := b.Publish(context.Background(), "orders", &Message{Payload: []byte("1")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	dl := receive(t, dead)
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("handler called %d times, want 1", got)
+	}
+	if dl.Headers[HeaderDeadLetterAttempt] != "1" {
+		t.Fatalf("dead letter attempt = %q, want 1", dl.Headers[HeaderDeadLetterAttempt])
+	}
+}
+
+func TestMemoryBrokerHandlerPanicIsRetried(t *testing.T) {
+	b := newTestBroker(t, 3)
+
+	attempts := make(chan int, 2)
+	subscribe(t, b, "orders", "billing", func(ctx context.Context, msg *Message) error {
+		attempts <- msg.Attempt
+		if msg.Attempt == 1 {
+			panic("boom")
+		}
+		return nil
+	})
+
+	if err := b.Publish(context.Background(), "orders", &Message{Payload: []byte("1")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	for want := 1; want <= 2; want++ {
+		select {
+		case got := <-attempts:
+			if got != want {
+				t.Fatalf("attempt = %d, want %d", got, want)
+			}
+		case <-time.After(testTimeout):
+			t.Fatalf("timed out waiting for attempt %d", want)
+		}
+	}
+}
+
+func TestMemoryBrokerDeadLetterKeptUntilSubscribe(t *testing.T) {
+	b := newTestBroker(t, 1)
+
+	failed := make(chan struct{}, 2)
+	subscribe(t, b, "orders", "billing", func(ctx context.Context, msg *Message) error {
+		failed <- struct{}{}
+		return errors.New("always fails")
+	})
+
+	if err := b.Publish(context.Background(), "orders", &Message{Payload: []byte("1")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	// 等待两次投递都失败，死信 topic 此时没有消费组
+	for i := 0; i < 2; i++ {
+		select {
+		case <-failed:
+		case <-time.After(testTimeout):
+			t.Fatal("timed out waiting for handler")
+		}
+	}
+	deadline := time.Now().Add(testTimeout)
+	for {
+		b.mu.Lock()
+		pending := len(b.pendingDeadLetters["orders.dlq"])
+		b.mu.Unlock()
+		if pending == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pending dead letters = %d, want 1", pending)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	dead := make(chan *Message, 1)
+	subscribe(t, b, "orders.dlq", "ops", func(ctx context.Context, msg *Message) error {
+		dead <- msg
+		return nil
+	})
+
+	dl := receive(t, dead)
+	if string(dl.Payload) != "1" || dl.Headers[HeaderDeadLetterGroup] != "billing" {
+		t.Fatalf("unexpected dead letter: %+v", dl)
+	}
+}
+
+func TestMemoryBrokerCloseWaitsForInFlightHandler(t *testing.T) {
+	b := newTestBroker(t, 3)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var finished atomic.Bool
+	_, done := subscribe(t, b, "orders", "billing", func(ctx context.Context, msg *Message) error {
+		close(started)
+		<-release
+		finished.Store(true)
+		return nil
+	})
+
+	if err := b.Publish(context.Background(), "orders", &Message{Payload: []byte("1")}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	select {
+	case <-started:
+	case <-time.After(testTimeout):
+		t.Fatal("timed out waiting for handler")
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+
+	select {
+	case <-done:
+		t.Fatal("Subscribe returned before the in-flight handler finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Subscribe: %v", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("Subscribe did not return after Close")
+	}
+	if !finished.Load() {
+		t.Fatal("handler did not finish")
+	}
+
+	if err := b.Publish(context.Background(), "orders", &Message{}); !errors.Is(err, ErrBrokerClosed) {
+		t.Fatalf("Publish after Close = %v, want ErrBrokerClosed", err)
+	}
+}
+
+func TestMemoryBrokerSubscribeReturnsOnCancel(t *testing.T) {
+	b := newTestBroker(t, 3)
+
+	cancel, done := subscribe(t, b, "orders", "billing", func(ctx context.Context, msg *Message) error {
+		return nil
+	})
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Subscribe: %v", err)
+		}
+	case <-time.After(testTimeout):
+		t.Fatal("Subscribe did not return after cancel")
+	}
+}
@@ -1,7 +1,136 @@
 package mq
 
-func Initialize() error {
-	// Initialize message queue-related services or configurations here
-	// For example, setting up RabbitMQ, Kafka, etc.
-	return nil
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+)
+
+// Message 消息
+type Message struct {
+	ID      string            // 消息ID，由消息队列实现生成
+	Topic   string            // 消息所属 topic
+	Payload []byte            // 消息体
+	Headers map[string]string // 消息头
+	Attempt int               // 第几次投递，从 1 开始
+}
+
+// Handler 消息处理函数
+// 返回 nil 表示确认（ack）；返回错误表示拒绝（nack），消息将按重试策略重新投递，
+// 重试次数耗尽或错误由 NonRetryable 包装时进入死信 topic
+type Handler func(ctx context.Context, msg *Message) error
+
+// Publisher 消息发布者
+type Publisher interface {
+	// Publish 发布消息到 topic
+	// 消息本身无法发布（如编码失败）时返回 NonRetryable 包装的错误，其余错误表示消息队列暂时不可用
+	Publish(ctx context.Context, topic string, msg *Message) error
+}
+
+// Subscriber 消息订阅者
+type Subscriber interface {
+	// Subscribe 以消费组 group 订阅 topic，同一消费组内每条消息只会被一个订阅者处理
+	// 阻塞直到 ctx 取消，取消后会等待处理中的消息完成再返回
+	Subscribe(ctx context.Context, topic, group string, handler Handler) error
+}
+
+// Broker 消息队列
+type Broker interface {
+	Publisher
+	Subscriber
+
+	Close() error
+}
+
+var ErrBrokerClosed = errors.New("mq: broker closed")
+
+type nonRetryableError struct {
+	err error
+}
+
+func (e *nonRetryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *nonRetryableError) Unwrap() error {
+	return e.err
+}
+
+// NonRetryable 标记错误不可重试，消息将直接进入死信 topic
+func NonRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &nonRetryableError{err: err}
+}
+
+// IsRetryable 判断错误是否可以重试，NonRetryable 包装的错误不可重试
+func IsRetryable(err error) bool {
+	var nonRetryable *nonRetryableError
+	return !errors.As(err, &nonRetryable)
+}
+
+// handle 执行 handler，并将 panic 转换为错误
+func handle(ctx context.Context, handler Handler, msg *Message) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("mq: handler panic: %v", p)
+		}
+	}()
+
+	return handler(ctx, msg)
+}
+
+// Consumer 消费者声明，由 services.MQService 统一运行
+type Consumer struct {
+	Topic   string
+	Group   string
+	Handler Handler
+}
+
+var (
+	consumersMu sync.Mutex
+	consumers   []Consumer
+)
+
+// RegisterConsumer 注册消费者
+func RegisterConsumer(c Consumer) {
+	consumersMu.Lock()
+	defer consumersMu.Unlock()
+
+	consumers = append(consumers, c)
+}
+
+// Consumers 返回已注册的消费者
+func Consumers() []Consumer {
+	consumersMu.Lock()
+	defer consumersMu.Unlock()
+
+	return append([]Consumer(nil), consumers...)
+}
+
+func init() {
+	conf := di.Get[config.Config]()
+
+	switch conf.MQ.Driver {
+	case "memory":
+		err := di.AddSingletonImpl[Broker, *MemoryBroker](func() (*MemoryBroker, error) {
+			return NewMemoryBroker(conf.MQ, di.Get[*log.Logger]()), nil
+		})
+		if err != nil {
+			panic(err)
+		}
+	case "redis", "":
+		err := di.AddSingletonImpl[Broker, *RedisStreamsBroker](newRedisStreamsBroker)
+		if err != nil {
+			panic(err)
+		}
+	default:
+		panic("unsupported mq driver: " + conf.MQ.Driver)
+	}
 }
@@ -0,0 +1,317 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/idgen"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+
+	_ "github.com/lyonnee/go-template/internal/infrastructure/cache" // 注册 redis.UniversalClient
+)
+
+const (
+	fieldPayload = "payload"
+	fieldHeaders = "headers"
+
+	defaultBatchSize    = 16
+	defaultBlockTimeout = 2 * time.Second
+	defaultClaimMinIdle = 5 * time.Minute
+	readErrorBackoff    = time.Second
+)
+
+// 保证对接口实现
+var _ Broker = (*RedisStreamsBroker)(nil)
+
+// RedisStreamsBroker 基于 Redis Streams 的消息队列
+// 失败的消息保持未确认状态，按重试策略的间隔通过 XCLAIM 重新投递，
+// 其他消费者长时间未确认的消息也会被接管，避免消费者崩溃导致消息丢失
+type RedisStreamsBroker struct {
+	client redis.UniversalClient
+	conf   config.MQConfig
+	retry  RetryPolicy
+	logger *log.Logger
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newRedisStreamsBroker() (*RedisStreamsBroker, error) {
+	conf := di.Get[config.Config]()
+
+	return NewRedisStreamsBroker(di.Get[redis.UniversalClient](), conf.MQ, di.Get[*log.Logger]()), nil
+}
+
+func NewRedisStreamsBroker(client redis.UniversalClient, conf config.MQConfig, logger *log.Logger) *RedisStreamsBroker {
+	if conf.Redis.BatchSize <= 0 {
+		conf.Redis.BatchSize = defaultBatchSize
+	}
+	if conf.Redis.BlockTimeout <= 0 {
+		conf.Redis.BlockTimeout = defaultBlockTimeout
+	}
+	if conf.Redis.ClaimMinIdle <= 0 {
+		conf.Redis.ClaimMinIdle = defaultClaimMinIdle
+	}
+
+	return &RedisStreamsBroker{
+		client: client,
+		conf:   conf,
+		retry:  newRetryPolicy(conf.Retry),
+		logger: logger,
+		closed: make(chan struct{}),
+	}
+}
+
+// Publish 发布消息，消息ID为 stream entry ID
+func (b *RedisStreamsBroker) Publish(ctx context.Context, topic string, msg *Message) error {
+	select {
+	case <-b.closed:
+		return ErrBrokerClosed
+	default:
+	}
+
+	values, err := encodeValues(msg)
+	if err != nil {
+		return err
+	}
+
+	id, err := b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: topic,
+		MaxLen: b.conf.Redis.MaxLen,
+		Approx: b.conf.Redis.MaxLen > 0,
+		Values: values,
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("mq: publish to %s: %w", topic, err)
+	}
+
+	msg.ID = id
+	msg.Topic = topic
+	return nil
+}
+
+// Subscribe 以消费组订阅 stream，消费组不存在时自动创建并从最新消息开始消费
+func (b *RedisStreamsBroker) Subscribe(ctx context.Context, topic, group string, handler Handler) error {
+	err := b.client.XGroupCreateMkStream(ctx, topic, group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("mq: create group %s for %s: %w", group, topic, err)
+	}
+
+	hostname, _ := os.Hostname()
+	consumer := hostname + "-" + idgen.GenerateStringId()
+
+	b.logger.Info("MQ consumer started",
+		zap.String("topic", topic),
+		zap.String("group", group),
+		zap.String("consumer", consumer))
+
+	var lastClaim time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-b.closed:
+			return nil
+		default:
+		}
+
+		// 重新投递到期的失败消息
+		if time.Since(lastClaim) >= b.retry.InitialBackoff {
+			b.claimPending(ctx, topic, group, consumer, handler)
+			lastClaim = time.Now()
+		}
+
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: consumer,
+			Streams:  []string{topic, ">"},
+			Count:    b.conf.Redis.BatchSize,
+			Block:    b.conf.Redis.BlockTimeout,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			b.logger.Error("MQ read failed", zap.String("topic", topic), zap.String("group", group), zap.Error(err))
+			sleep(ctx, readErrorBackoff)
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, xmsg := range stream.Messages {
+				b.process(ctx, topic, group, handler, xmsg, 1)
+			}
+		}
+	}
+}
+
+// claimPending 接管已到重试时间的未确认消息
+func (b *RedisStreamsBroker) claimPending(ctx context.Context, topic, group, consumer string, handler Handler) {
+	pending, err := b.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: topic,
+		Group:  group,
+		Idle:   b.retry.InitialBackoff,
+		Start:  "-",
+		End:    "+",
+		Count:  b.conf.Redis.BatchSize,
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			b.logger.Error("MQ pending lookup failed", zap.String("topic", topic), zap.String("group", group), zap.Error(err))
+		}
+		return
+	}
+
+	for _, p := range pending {
+		// 其他消费者的消息可能仍在处理中，空闲足够久才接管
+		if p.Consumer != consumer && p.Idle < b.conf.Redis.ClaimMinIdle {
+			continue
+		}
+		if p.Idle < b.retry.Backoff(int(p.RetryCount)) {
+			continue
+		}
+
+		claimed, err := b.client.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   topic,
+			Group:    group,
+			Consumer: consumer,
+			MinIdle:  p.Idle,
+			Messages: []string{p.ID},
+		}).Result()
+		if err != nil {
+			b.logger.Error("MQ claim failed", zap.String("topic", topic), zap.String("id", p.ID), zap.Error(err))
+			continue
+		}
+
+		for _, xmsg := range claimed {
+			b.process(ctx, topic, group, handler, xmsg, int(p.RetryCount)+1)
+		}
+	}
+}
+
+// process 处理单条消息，成功后确认，失败则保持未确认等待重试或转入死信
+func (b *RedisStreamsBroker) process(ctx context.Context, topic, group string, handler Handler, xmsg redis.XMessage, attempt int) {
+	// 消息已被裁剪
+	if xmsg.Values == nil {
+		b.ack(ctx, topic, group, xmsg.ID)
+		return
+	}
+
+	msg := decodeMessage(topic, xmsg, attempt)
+
+	// 处理中的消息不受 ctx 取消影响，保证优雅退出时处理完成
+	err := handle(context.WithoutCancel(ctx), handler, msg)
+	if err == nil {
+		b.ack(ctx, topic, group, xmsg.ID)
+		return
+	}
+
+	if IsRetryable(err) && !b.retry.Exhausted(attempt) {
+		b.logger.Warn("MQ message handling failed, will retry",
+			zap.String("topic", topic),
+			zap.String("group", group),
+			zap.String("id", msg.ID),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", b.retry.Backoff(attempt)),
+			zap.Error(err))
+		return
+	}
+
+	b.deadLetter(ctx, topic, group, msg, err)
+}
+
+// deadLetter 将消息转入死信 topic 并确认原消息
+func (b *RedisStreamsBroker) deadLetter(ctx context.Context, topic, group string, msg *Message, cause error) {
+	ctx = context.WithoutCancel(ctx)
+
+	values, err := encodeValues(deadLetterMessage(msg, group, cause))
+	if err != nil {
+		b.logger.Error("MQ dead letter encode failed", zap.String("topic", topic), zap.String("id", msg.ID), zap.Error(err))
+		return
+	}
+
+	dlq := deadLetterTopic(b.conf, topic)
+	if err := b.client.XAdd(ctx, &redis.XAddArgs{Stream: dlq, Values: values}).Err(); err != nil {
+		// 转入死信失败时保持未确认，等待下次重试
+		b.logger.Error("MQ dead letter publish failed", zap.String("topic", dlq), zap.String("id", msg.ID), zap.Error(err))
+		return
+	}
+
+	b.logger.Error("MQ message moved to dead letter topic",
+		zap.String("topic", topic),
+		zap.String("group", group),
+		zap.String("id", msg.ID),
+		zap.String("deadLetterTopic", dlq),
+		zap.Int("attempt", msg.Attempt),
+		zap.Error(cause))
+
+	b.ack(ctx, topic, group, msg.ID)
+}
+
+func (b *RedisStreamsBroker) ack(ctx context.Context, topic, group, id string) {
+	if err := b.client.XAck(context.WithoutCancel(ctx), topic, group, id).Err(); err != nil {
+		b.logger.Error("MQ ack failed", zap.String("topic", topic), zap.String("group", group), zap.String("id", id), zap.Error(err))
+	}
+}
+
+// Close 停止所有订阅，Redis 客户端由容器管理，不在此关闭
+func (b *RedisStreamsBroker) Close() error {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+	})
+	return nil
+}
+
+func encodeValues(msg *Message) (map[string]interface{}, error) {
+	values := map[string]interface{}{
+		fieldPayload: msg.Payload,
+	}
+
+	if len(msg.Headers) > 0 {
+		headers, err := json.Marshal(msg.Headers)
+		if err != nil {
+			// 编码失败与消息内容有关，重试不会成功
+			return nil, NonRetryable(fmt.Errorf("mq: encode headers: %w", err))
+		}
+		values[fieldHeaders] = headers
+	}
+
+	return values, nil
+}
+
+func decodeMessage(topic string, xmsg redis.XMessage, attempt int) *Message {
+	msg := &Message{
+		ID:      xmsg.ID,
+		Topic:   topic,
+		Attempt: attempt,
+	}
+
+	if payload, ok := xmsg.Values[fieldPayload].(string); ok {
+		msg.Payload = []byte(payload)
+	}
+	if headers, ok := xmsg.Values[fieldHeaders].(string); ok {
+		_ = json.Unmarshal([]byte(headers), &msg.Headers)
+	}
+
+	return msg
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,99 @@
+package mq
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+const (
+	defaultMaxRetries       = 5
+	defaultInitialBackoff   = time.Second
+	defaultMaxBackoff       = time.Minute
+	defaultMultiplier       = 2
+	defaultDeadLetterSuffix = ".dlq"
+)
+
+// RetryPolicy 消息重试策略，重试间隔按指数增长
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+func newRetryPolicy(conf config.MQRetryConfig) RetryPolicy {
+	policy := RetryPolicy{
+		MaxRetries:     conf.MaxRetries,
+		InitialBackoff: conf.InitialBackoff,
+		MaxBackoff:     conf.MaxBackoff,
+		Multiplier:     conf.Multiplier,
+	}
+
+	if policy.MaxRetries <= 0 {
+		policy.MaxRetries = defaultMaxRetries
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultInitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultMaxBackoff
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = defaultMultiplier
+	}
+
+	return policy
+}
+
+// Backoff 返回第 attempt 次投递失败后，下一次投递前的等待时长
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if backoff > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(backoff)
+}
+
+// Exhausted 第 attempt 次投递失败后是否已无重试机会
+func (p RetryPolicy) Exhausted(attempt int) bool {
+	return attempt > p.MaxRetries
+}
+
+func deadLetterTopic(conf config.MQConfig, topic string) string {
+	suffix := conf.DeadLetterSuffix
+	if suffix == "" {
+		suffix = defaultDeadLetterSuffix
+	}
+	return topic + suffix
+}
+
+// 死信消息头，记录进入死信的原因
+const (
+	HeaderDeadLetterError   = "x-dead-letter-error"
+	HeaderDeadLetterSource  = "x-dead-letter-source-id"
+	HeaderDeadLetterGroup   = "x-dead-letter-group"
+	HeaderDeadLetterAttempt = "x-dead-letter-attempt"
+)
+
+func deadLetterMessage(msg *Message, group string, cause error) *Message {
+	headers := make(map[string]string, len(msg.Headers)+4)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[HeaderDeadLetterError] = cause.Error()
+	headers[HeaderDeadLetterSource] = msg.ID
+	headers[HeaderDeadLetterGroup] = group
+	headers[HeaderDeadLetterAttempt] = strconv.Itoa(msg.Attempt)
+
+	return &Message{
+		Payload: msg.Payload,
+		Headers: headers,
+	}
+}
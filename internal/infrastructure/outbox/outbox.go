@@ -0,0 +1,37 @@
+package outbox
+
+import "context"
+
+const (
+	StatusPending     = 0 // 待投递
+	StatusDelivered   = 1 // 已投递
+	StatusQuarantined = 2 // 多次投递失败，已隔离
+)
+
+// Message 发件箱消息
+type Message struct {
+	ID        uint64
+	CreatedAt int64
+
+	Topic   string
+	Payload []byte
+	Headers map[string]string
+
+	Attempts int
+}
+
+// Repository 发件箱存储库，所有方法均通过上下文中的 DBExecutor 执行
+type Repository interface {
+	// Add 写入待投递消息，需在业务数据所在的事务中调用
+	Add(ctx context.Context, msgs ...*Message) error
+	// FetchPending 锁定并返回已到投递时间的消息（FOR UPDATE SKIP LOCKED），需在事务中调用
+	FetchPending(ctx context.Context, limit int) ([]*Message, error)
+	// MarkDelivered 标记消息已投递
+	MarkDelivered(ctx context.Context, id uint64) error
+	// MarkFailed 记录投递失败，并设置下次投递时间
+	MarkFailed(ctx context.Context, id uint64, attempts int, nextAttemptAt int64, reason string) error
+	// Quarantine 隔离多次投递失败的消息，不再自动投递
+	Quarantine(ctx context.Context, id uint64, attempts int, reason string) error
+	// DeleteDelivered 删除 before 之前投递成功的消息，单次最多删除 limit 条，返回删除的消息数
+	DeleteDelivered(ctx context.Context, before int64, limit int) (int64, error)
+}
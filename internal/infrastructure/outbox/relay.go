@@ -0,0 +1,211 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/infrastructure/database"
+	"github.com/lyonnee/go-template/internal/infrastructure/mq"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderOutboxID = "x-outbox-id"
+
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultMaxAttempts  = 10
+	defaultRetryBackoff = 5 * time.Second
+	defaultMaxBackoff   = time.Hour
+	defaultRetention    = 7 * 24 * time.Hour
+	cleanupBatchSize    = 1000
+)
+
+// Relay 轮询发件箱，将待投递消息发布到消息队列
+// 消息至少投递一次，消费者可根据 x-outbox-id 消息头去重
+type Relay struct {
+	logger    *log.Logger
+	dbContext *database.Database
+	repo      Repository
+	publisher mq.Publisher
+	conf      config.OutboxConfig
+}
+
+func init() {
+	di.AddSingleton[*Relay](NewRelay)
+}
+
+func NewRelay() (*Relay, error) {
+	conf := di.Get[config.Config]().Event.Outbox
+	if conf.PollInterval <= 0 {
+		conf.PollInterval = defaultPollInterval
+	}
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = defaultBatchSize
+	}
+	if conf.MaxAttempts <= 0 {
+		conf.MaxAttempts = defaultMaxAttempts
+	}
+	if conf.RetryBackoff <= 0 {
+		conf.RetryBackoff = defaultRetryBackoff
+	}
+	if conf.MaxBackoff <= 0 {
+		conf.MaxBackoff = defaultMaxBackoff
+	}
+	if conf.Retention <= 0 {
+		conf.Retention = defaultRetention
+	}
+
+	return &Relay{
+		logger:    di.Get[*log.Logger](),
+		dbContext: di.Get[*database.Database](),
+		repo:      di.Get[Repository](),
+		publisher: di.Get[mq.Broker](),
+		conf:      conf,
+	}, nil
+}
+
+// Run 持续投递直到 ctx 取消
+// 投递失败（消息队列或数据库不可用）时按 RetryBackoff 指数退避，恢复后回到 PollInterval 轮询
+func (r *Relay) Run(ctx context.Context) {
+	var failures int
+	for {
+		// 一批取满时说明仍有积压，立即继续投递
+		n, err := r.RelayOnce(ctx)
+		wait := r.conf.PollInterval
+		if err != nil {
+			failures++
+			wait = r.backoff(failures)
+			if ctx.Err() == nil {
+				r.logger.Error("Outbox relay failed", zap.Duration("backoff", wait), zap.Error(err))
+			}
+		} else {
+			failures = 0
+			if n >= r.conf.BatchSize {
+				continue
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// RelayOnce 投递一批到期消息，返回本批消息数
+// 消息队列不可用时停止投递本批剩余的消息并返回错误，已投递的消息照常提交，未投递的消息不计入投递次数
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	var n int
+	var publishErr error
+	err := r.dbContext.Transaction(ctx, nil, func(ctx context.Context) error {
+		msgs, err := r.repo.FetchPending(ctx, r.conf.BatchSize)
+		if err != nil {
+			return err
+		}
+		n = len(msgs)
+
+		for _, msg := range msgs {
+			err := r.publish(ctx, msg)
+			if err == nil {
+				if err := r.repo.MarkDelivered(ctx, msg.ID); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if mq.IsRetryable(err) {
+				publishErr = fmt.Errorf("outbox: publish message %d: %w", msg.ID, err)
+				return nil
+			}
+			if err := r.fail(ctx, msg, err); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+
+	return n, publishErr
+}
+
+func (r *Relay) publish(ctx context.Context, msg *Message) error {
+	headers := make(map[string]string, len(msg.Headers)+1)
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[HeaderOutboxID] = strconv.FormatUint(msg.ID, 10)
+
+	return r.publisher.Publish(ctx, msg.Topic, &mq.Message{
+		Payload: msg.Payload,
+		Headers: headers,
+	})
+}
+
+// fail 记录消息本身导致的投递失败，达到 MaxAttempts 后隔离
+func (r *Relay) fail(ctx context.Context, msg *Message, err error) error {
+	attempts := msg.Attempts + 1
+	if attempts >= r.conf.MaxAttempts {
+		r.logger.Error("Outbox message quarantined",
+			zap.Uint64("id", msg.ID),
+			zap.String("topic", msg.Topic),
+			zap.Int("attempts", attempts),
+			zap.Error(err))
+		return r.repo.Quarantine(ctx, msg.ID, attempts, err.Error())
+	}
+
+	backoff := r.backoff(attempts)
+	r.logger.Warn("Outbox message delivery failed, will retry",
+		zap.Uint64("id", msg.ID),
+		zap.String("topic", msg.Topic),
+		zap.Int("attempts", attempts),
+		zap.Duration("backoff", backoff),
+		zap.Error(err))
+
+	return r.repo.MarkFailed(ctx, msg.ID, attempts, time.Now().Add(backoff).Unix(), err.Error())
+}
+
+// Cleanup 分批删除投递成功超过 Retention 的消息，返回删除的消息数，隔离的消息保留供人工处理
+func (r *Relay) Cleanup(ctx context.Context) (int64, error) {
+	before := time.Now().Add(-r.conf.Retention).Unix()
+
+	var total int64
+	for {
+		var n int64
+		err := r.dbContext.Transaction(ctx, nil, func(ctx context.Context) error {
+			var err error
+			n, err = r.repo.DeleteDelivered(ctx, before, cleanupBatchSize)
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += n
+		if n < cleanupBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// backoff 指数退避，第 n 次失败后等待 RetryBackoff * 2^(n-1)
+func (r *Relay) backoff(attempts int) time.Duration {
+	backoff := r.conf.RetryBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= r.conf.MaxBackoff {
+			return r.conf.MaxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+// sweepInterval 每处理多少次请求清理一次过期的状态
+const sweepInterval = 1024
+
+// 保证对接口实现
+var _ Limiter = (*MemoryLimiter)(nil)
+
+type bucketState struct {
+	tokens    float64
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+type windowState struct {
+	start     time.Time
+	cur       int
+	prev      int
+	expiresAt time.Time
+}
+
+// MemoryLimiter 进程内的限流器，适用于测试和单节点部署，多副本时各副本的配额相互独立
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucketState
+	windows map[string]*windowState
+	calls   int
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*bucketState),
+		windows: make(map[string]*windowState),
+	}
+}
+
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, rule config.RateLimitRule) (*Result, error) {
+	rule = normalize(rule)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.calls++
+	if l.calls%sweepInterval == 0 {
+		l.sweep(now)
+	}
+
+	switch rule.Algorithm {
+	case AlgorithmTokenBucket:
+		return l.tokenBucket(key, rule, now), nil
+	case AlgorithmSlidingWindow:
+		return l.slidingWindow(key, rule, now), nil
+	default:
+		return nil, fmt.Errorf("unsupported rate limit algorithm: %s", rule.Algorithm)
+	}
+}
+
+func (l *MemoryLimiter) tokenBucket(key string, rule config.RateLimitRule, now time.Time) *Result {
+	capacity := float64(rule.Burst)
+	// 每纳秒补充的令牌数
+	rate := float64(rule.Limit) / float64(rule.Window)
+
+	state, ok := l.buckets[key]
+	if !ok {
+		state = &bucketState{tokens: capacity, updatedAt: now}
+		l.buckets[key] = state
+	}
+	if elapsed := now.Sub(state.updatedAt); elapsed > 0 {
+		state.tokens = math.Min(capacity, state.tokens+float64(elapsed)*rate)
+	}
+	state.updatedAt = now
+	state.expiresAt = now.Add(time.Duration(capacity / rate))
+
+	result := &Result{Limit: rule.Burst}
+	if state.tokens >= 1 {
+		state.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - state.tokens) / rate))
+	}
+	result.Remaining = int(state.tokens)
+	result.Reset = time.Duration(math.Ceil((capacity - state.tokens) / rate))
+
+	return result
+}
+
+func (l *MemoryLimiter) slidingWindow(key string, rule config.RateLimitRule, now time.Time) *Result {
+	start := now.Truncate(rule.Window)
+	elapsed := now.Sub(start)
+
+	state, ok := l.windows[key]
+	if !ok {
+		state = &windowState{start: start}
+		l.windows[key] = state
+	}
+	if !state.start.Equal(start) {
+		if state.start.Equal(start.Add(-rule.Window)) {
+			state.prev = state.cur
+		} else {
+			state.prev = 0
+		}
+		state.cur = 0
+		state.start = start
+	}
+	state.expiresAt = start.Add(2 * rule.Window)
+
+	result := &Result{Limit: rule.Limit, Reset: rule.Window - elapsed}
+
+	estimated := float64(state.prev)*float64(rule.Window-elapsed)/float64(rule.Window) + float64(state.cur)
+	if estimated+1 > float64(rule.Limit) {
+		retry := rule.Window - elapsed
+		if state.cur+1 <= rule.Limit && state.prev > 0 {
+			ratio := 1 - float64(rule.Limit-state.cur-1)/float64(state.prev)
+			retry = time.Duration(math.Ceil(float64(rule.Window)*ratio)) - elapsed
+		}
+		if retry < time.Millisecond {
+			retry = time.Millisecond
+		}
+		result.RetryAfter = retry
+		return result
+	}
+
+	state.cur++
+	result.Allowed = true
+	result.Remaining = int(float64(rule.Limit) - estimated - 1)
+	return result
+}
+
+// sweep 清理已过期的状态，调用方需持有锁
+func (l *MemoryLimiter) sweep(now time.Time) {
+	for key, state := range l.buckets {
+		if now.After(state.expiresAt) {
+			delete(l.buckets, key)
+		}
+	}
+	for key, state := range l.windows {
+		if now.After(state.expiresAt) {
+			delete(l.windows, key)
+		}
+	}
+}
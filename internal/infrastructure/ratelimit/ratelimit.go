@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/pkg/di"
+)
+
+const (
+	// AlgorithmTokenBucket 令牌桶，允许不超过桶容量的突发请求，长期速率为 limit/window
+	AlgorithmTokenBucket = "token_bucket"
+	// AlgorithmSlidingWindow 滑动窗口计数，按上一窗口的计数加权估算最近一个窗口内的请求数
+	AlgorithmSlidingWindow = "sliding_window"
+
+	defaultWindow = time.Minute
+)
+
+// Result 限流判断结果
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // 配额完全恢复前的时长
+	RetryAfter time.Duration // 被拒绝时，距离下一次可能成功的时长
+}
+
+// Limiter 限流器，key 相同的请求共享配额
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule config.RateLimitRule) (*Result, error)
+}
+
+func init() {
+	conf := di.Get[config.Config]()
+
+	di.AddSingleton[Limiter](func() (Limiter, error) {
+		if conf.Http.RateLimit.Backend == "memory" {
+			return NewMemoryLimiter(), nil
+		}
+		return NewRedisLimiter(di.Get[redis.UniversalClient]()), nil
+	})
+}
+
+// normalize 补全规则的默认值
+func normalize(rule config.RateLimitRule) config.RateLimitRule {
+	if rule.Window <= 0 {
+		rule.Window = defaultWindow
+	}
+	if rule.Burst <= 0 {
+		rule.Burst = rule.Limit
+	}
+	if rule.Algorithm == "" {
+		rule.Algorithm = AlgorithmSlidingWindow
+	}
+	return rule
+}
@@ -0,0 +1,189 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+// step 在距离起始时间 at 时发起一次请求及期望的结果
+type step struct {
+	at         time.Duration
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+	reset      time.Duration
+}
+
+// 起始时间与窗口边界对齐
+var testStart = time.Unix(1_700_000_000, 0)
+
+var tokenBucketRule = config.RateLimitRule{
+	Algorithm: AlgorithmTokenBucket,
+	Limit:     2,
+	Window:    time.Second,
+	Burst:     3,
+}
+
+// 每 500ms 补充一个令牌，桶容量为 3
+var tokenBucketSteps = []step{
+	{at: 0, allowed: true, remaining: 2, reset: 500 * time.Millisecond},
+	{at: 0, allowed: true, remaining: 1, reset: time.Second},
+	{at: 0, allowed: true, remaining: 0, reset: 1500 * time.Millisecond},
+	// 令牌耗尽，等待补充一个令牌
+	{at: 0, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond, reset: 1500 * time.Millisecond},
+	{at: 250 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 250 * time.Millisecond, reset: 1250 * time.Millisecond},
+	// 恰好补充一个令牌
+	{at: 500 * time.Millisecond, allowed: true, remaining: 0, reset: 1500 * time.Millisecond},
+	{at: 500 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond, reset: 1500 * time.Millisecond},
+	// 补充的令牌不超过桶容量
+	{at: 10 * time.Second, allowed: true, remaining: 2, reset: 500 * time.Millisecond},
+}
+
+var slidingWindowRule = config.RateLimitRule{
+	Algorithm: AlgorithmSlidingWindow,
+	Limit:     4,
+	Window:    time.Second,
+}
+
+var slidingWindowSteps = []step{
+	{at: 0, allowed: true, remaining: 3, reset: time.Second},
+	{at: 0, allowed: true, remaining: 2, reset: time.Second},
+	{at: 0, allowed: true, remaining: 1, reset: time.Second},
+	{at: 100 * time.Millisecond, allowed: true, remaining: 0, reset: 900 * time.Millisecond},
+	// 上一窗口没有请求，需要等到当前窗口结束
+	{at: 100 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 900 * time.Millisecond, reset: 900 * time.Millisecond},
+	{at: 999 * time.Millisecond, allowed: false, remaining: 0, retryAfter: time.Millisecond, reset: time.Millisecond},
+	// 新窗口开始时上一窗口的 4 次请求全部计入，等到其权重降到 3 次
+	{at: time.Second, allowed: false, remaining: 0, retryAfter: 250 * time.Millisecond, reset: time.Second},
+	{at: 1250 * time.Millisecond, allowed: true, remaining: 0, reset: 750 * time.Millisecond},
+	{at: 1250 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 250 * time.Millisecond, reset: 750 * time.Millisecond},
+	{at: 1500 * time.Millisecond, allowed: true, remaining: 0, reset: 500 * time.Millisecond},
+	// 跳过一个窗口后上一窗口的计数清零
+	{at: 3 * time.Second, allowed: true, remaining: 3, reset: time.Second},
+}
+
+func checkSteps(t *testing.T, rule config.RateLimitRule, steps []step, allow func(now time.Time) *Result) {
+	t.Helper()
+
+	// 令牌桶的配额为桶容量
+	limit := rule.Limit
+	if rule.Algorithm == AlgorithmTokenBucket {
+		limit = rule.Burst
+	}
+
+	for i, s := range steps {
+		r := allow(testStart.Add(s.at))
+		want := Result{
+			Allowed:    s.allowed,
+			Limit:      limit,
+			Remaining:  s.remaining,
+			Reset:      s.reset,
+			RetryAfter: s.retryAfter,
+		}
+		if *r != want {
+			t.Fatalf("step %d at %v: got %+v, want %+v", i, s.at, *r, want)
+		}
+	}
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  config.RateLimitRule
+		steps []step
+	}{
+		{"token bucket", tokenBucketRule, tokenBucketSteps},
+		{"sliding window", slidingWindowRule, slidingWindowSteps},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewMemoryLimiter()
+			rule := normalize(tt.rule)
+
+			checkSteps(t, rule, tt.steps, func(now time.Time) *Result {
+				if rule.Algorithm == AlgorithmTokenBucket {
+					return l.tokenBucket("k", rule, now)
+				}
+				return l.slidingWindow("k", rule, now)
+			})
+		})
+	}
+}
+
+func TestRedisLimiter(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  config.RateLimitRule
+		steps []step
+	}{
+		{"token bucket", tokenBucketRule, tokenBucketSteps},
+		{"sliding window", slidingWindowRule, slidingWindowSteps},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { client.Close() })
+			l := NewRedisLimiter(client)
+
+			checkSteps(t, tt.rule, tt.steps, func(now time.Time) *Result {
+				// 脚本通过 TIME 读取 Redis 服务器时间
+				mr.SetTime(now)
+				r, err := l.Allow(context.Background(), "k", tt.rule)
+				if err != nil {
+					t.Fatalf("Allow: %v", err)
+				}
+				return r
+			})
+		})
+	}
+}
+
+func TestLimiterKeysAreIndependent(t *testing.T) {
+	rule := config.RateLimitRule{Algorithm: AlgorithmSlidingWindow, Limit: 1, Window: time.Minute}
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	limiters := map[string]Limiter{
+		"memory": NewMemoryLimiter(),
+		"redis":  NewRedisLimiter(client),
+	}
+	for name, l := range limiters {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, key := range []string{name + ":a", name + ":b"} {
+				r, err := l.Allow(ctx, key, rule)
+				if err != nil {
+					t.Fatalf("Allow(%s): %v", key, err)
+				}
+				if !r.Allowed || r.Limit != 1 {
+					t.Fatalf("Allow(%s) = %+v, want allowed with limit 1", key, *r)
+				}
+			}
+
+			r, err := l.Allow(ctx, name+":a", rule)
+			if err != nil {
+				t.Fatalf("Allow: %v", err)
+			}
+			if r.Allowed || r.RetryAfter <= 0 {
+				t.Fatalf("second Allow = %+v, want denied with RetryAfter", *r)
+			}
+		})
+	}
+}
+
+func TestUnsupportedAlgorithm(t *testing.T) {
+	rule := config.RateLimitRule{Algorithm: "fixed_window", Limit: 1}
+	if _, err := NewMemoryLimiter().Allow(context.Background(), "k", rule); err == nil {
+		t.Fatal("MemoryLimiter.Allow with unsupported algorithm returned nil error")
+	}
+}
@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+)
+
+var (
+	// tokenBucketScript 令牌桶，使用 Redis 服务器时间，避免各副本时钟不一致
+	// ARGV: 桶容量, 每毫秒补充的令牌数
+	// 返回 {是否允许, 剩余令牌, 重试等待毫秒, 桶装满所需毫秒}
+	tokenBucketScript = redis.NewScript(`
+redis.replicate_commands()
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local data = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(data[1]) or capacity
+local ts = tonumber(data[2]) or now
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) * rate)
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(capacity / rate))
+
+return {allowed, math.floor(tokens), retry, math.ceil((capacity - tokens) / rate)}
+`)
+
+	// slidingWindowScript 滑动窗口计数，保存当前窗口和上一窗口的计数
+	// ARGV: 窗口内允许的请求数, 窗口毫秒
+	// 返回 {是否允许, 剩余请求数, 重试等待毫秒, 当前窗口结束前的毫秒}
+	slidingWindowScript = redis.NewScript(`
+redis.replicate_commands()
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local start = now - (now % window)
+local elapsed = now - start
+
+local data = redis.call("HMGET", KEYS[1], "start", "cur", "prev")
+local saved = tonumber(data[1])
+local cur = tonumber(data[2]) or 0
+local prev = tonumber(data[3]) or 0
+if saved ~= start then
+	if saved == start - window then
+		prev = cur
+	else
+		prev = 0
+	end
+	cur = 0
+end
+
+local estimated = prev * (window - elapsed) / window + cur
+if estimated + 1 > limit then
+	local retry = window - elapsed
+	if cur + 1 <= limit and prev > 0 then
+		retry = math.ceil(window * (1 - (limit - cur - 1) / prev)) - elapsed
+	end
+	return {0, 0, math.max(retry, 1), window - elapsed}
+end
+
+cur = cur + 1
+redis.call("HSET", KEYS[1], "start", start, "cur", cur, "prev", prev)
+redis.call("PEXPIRE", KEYS[1], window * 2)
+
+return {1, math.floor(limit - estimated - 1), 0, window - elapsed}
+`)
+)
+
+// 保证对接口实现
+var _ Limiter = (*RedisLimiter)(nil)
+
+// RedisLimiter 基于 Redis 的限流器，多副本共享配额
+type RedisLimiter struct {
+	client redis.UniversalClient
+}
+
+func NewRedisLimiter(client redis.UniversalClient) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, rule config.RateLimitRule) (*Result, error) {
+	rule = normalize(rule)
+	windowMs := rule.Window.Milliseconds()
+
+	var values []int64
+	var err error
+	switch rule.Algorithm {
+	case AlgorithmTokenBucket:
+		rate := float64(rule.Limit) / float64(windowMs)
+		values, err = tokenBucketScript.Run(ctx, l.client, []string{cache.RateLimitKey.Key(key)}, rule.Burst, rate).Int64Slice()
+	case AlgorithmSlidingWindow:
+		values, err = slidingWindowScript.Run(ctx, l.client, []string{cache.RateLimitKey.Key(key)}, rule.Limit, windowMs).Int64Slice()
+	default:
+		return nil, fmt.Errorf("unsupported rate limit algorithm: %s", rule.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("unexpected rate limit script result: %v", values)
+	}
+
+	limit := rule.Limit
+	if rule.Algorithm == AlgorithmTokenBucket {
+		limit = rule.Burst
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		Reset:      time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
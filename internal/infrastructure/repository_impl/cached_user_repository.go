@@ -0,0 +1,299 @@
+package repository_impl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/lyonnee/go-template/internal/domain/entity"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/domain/repository"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
+	"github.com/lyonnee/go-template/internal/infrastructure/database"
+	"github.com/lyonnee/go-template/internal/infrastructure/repository_impl/model"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+const (
+	// userCacheTTL 用户数据的缓存时长
+	userCacheTTL = 10 * time.Minute
+	// userNotFoundTTL 用户不存在的缓存时长，避免不存在的ID或用户名反复查询数据库
+	userNotFoundTTL = 30 * time.Second
+	// userReplicaCacheTTL 从只读副本读取的数据的最长缓存时长，副本落后于主库时避免长时间缓存旧数据
+	userReplicaCacheTTL = 5 * time.Second
+	// userNotFoundMarker 用户不存在时缓存的值
+	userNotFoundMarker = "-"
+)
+
+// 保证对接口实现
+var _ repository.UserRepository = (*CachedUserRepository)(nil)
+
+// CachedUserRepository 用户存储库的缓存装饰器，FindById 和 FindByUsername 先读缓存，未命中时查询数据库并回填
+// 并发的未命中请求合并为一次数据库查询，写操作后清除相关缓存
+// 事务中的查询不使用缓存，保证读到事务内的最新数据
+// 缓存的用户数据包含密码哈希和加密后的 MFA 密钥，Redis 需要与数据库同等的访问控制
+type CachedUserRepository struct {
+	*UserRepositoryImpl
+
+	cache  cache.CacheContext
+	group  singleflight.Group
+	logger *log.Logger
+}
+
+func init() {
+	err := di.AddSingletonImpl[repository.UserRepository, *CachedUserRepository](NewCachedUserRepository)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewCachedUserRepository 创建带缓存的用户存储库
+func NewCachedUserRepository() (*CachedUserRepository, error) {
+	next, err := NewUserRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	return &CachedUserRepository{
+		UserRepositoryImpl: next,
+		cache:              di.Get[cache.CacheContext](),
+		logger:             di.Get[*log.Logger](),
+	}, nil
+}
+
+// FindById 根据ID查找用户，优先读取缓存
+func (r *CachedUserRepository) FindById(ctx context.Context, userId uint64) (*entity.User, error) {
+	if database.InTransaction(ctx) {
+		return r.UserRepositoryImpl.FindById(ctx, userId)
+	}
+
+	key := cache.UserByIDKey.Key(userId)
+	// 合并后的结果是序列化数据，每个调用方各自反序列化，避免共享同一个实体
+	data, err, _ := r.group.Do(key, func() (interface{}, error) {
+		return r.loadById(ctx, userId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.decode(data.(string))
+}
+
+// FindByUsername 根据用户名查找用户，缓存中只保存用户名到ID的索引，用户数据与 FindById 共用
+func (r *CachedUserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	if database.InTransaction(ctx) {
+		return r.UserRepositoryImpl.FindByUsername(ctx, username)
+	}
+
+	key := cache.UserByUsernameKey.Key(username)
+	data, err, _ := r.group.Do(key, func() (interface{}, error) {
+		return r.loadByUsername(ctx, username)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.decode(data.(string))
+}
+
+func (r *CachedUserRepository) loadById(ctx context.Context, userId uint64) (string, error) {
+	key := cache.UserByIDKey.Key(userId)
+
+	if data, ok, err := r.getCached(ctx, key); ok {
+		return data, err
+	}
+
+	user, err := r.UserRepositoryImpl.FindById(ctx, userId)
+	if err != nil {
+		if errors.Is(err, domainErrors.ErrUserNotFound) {
+			r.setCached(ctx, key, userNotFoundMarker, userNotFoundTTL)
+		}
+		return "", err
+	}
+
+	return r.store(ctx, user)
+}
+
+func (r *CachedUserRepository) loadByUsername(ctx context.Context, username string) (string, error) {
+	key := cache.UserByUsernameKey.Key(username)
+
+	value, ok, err := r.getCached(ctx, key)
+	if ok && err != nil {
+		return "", err
+	}
+	if ok {
+		// 索引可能在用户改名或删除后失效，校验通过才使用
+		if userId, parseErr := strconv.ParseUint(value, 10, 64); parseErr == nil {
+			data, err := r.loadById(ctx, userId)
+			if err == nil {
+				if user, decodeErr := r.decode(data); decodeErr == nil && user.Username == username {
+					return data, nil
+				}
+			} else if !errors.Is(err, domainErrors.ErrUserNotFound) {
+				return "", err
+			}
+		}
+		r.deleteCached(ctx, key)
+	}
+
+	user, err := r.UserRepositoryImpl.FindByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, domainErrors.ErrUserNotFound) {
+			r.setCached(ctx, key, userNotFoundMarker, userNotFoundTTL)
+		}
+		return "", err
+	}
+
+	return r.store(ctx, user)
+}
+
+// Create 创建用户后清除用户名的不存在标记
+func (r *CachedUserRepository) Create(ctx context.Context, user *entity.User) error {
+	if err := r.UserRepositoryImpl.Create(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user.ID, user.Username)
+	return nil
+}
+
+func (r *CachedUserRepository) Update(ctx context.Context, user *entity.User) error {
+	if err := r.UserRepositoryImpl.Update(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user.ID, user.Username)
+	return nil
+}
+
+func (r *CachedUserRepository) Delete(ctx context.Context, userId uint64) error {
+	if err := r.UserRepositoryImpl.Delete(ctx, userId); err != nil {
+		return err
+	}
+	r.invalidate(ctx, userId, "")
+	return nil
+}
+
+// UpdateUsername 旧用户名的索引在读取时校验失效，这里清除新用户名可能存在的不存在标记
+func (r *CachedUserRepository) UpdateUsername(ctx context.Context, user *entity.User) error {
+	if err := r.UserRepositoryImpl.UpdateUsername(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user.ID, user.Username)
+	return nil
+}
+
+func (r *CachedUserRepository) UpdatePwdSecret(ctx context.Context, user *entity.User) error {
+	if err := r.UserRepositoryImpl.UpdatePwdSecret(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user.ID, "")
+	return nil
+}
+
+func (r *CachedUserRepository) UpdateEmail(ctx context.Context, user *entity.User) error {
+	if err := r.UserRepositoryImpl.UpdateEmail(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user.ID, "")
+	return nil
+}
+
+func (r *CachedUserRepository) UpdatePhone(ctx context.Context, user *entity.User) error {
+	if err := r.UserRepositoryImpl.UpdatePhone(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user.ID, "")
+	return nil
+}
+
+func (r *CachedUserRepository) UpdateWalletAddress(ctx context.Context, user *entity.User) error {
+	if err := r.UserRepositoryImpl.UpdateWalletAddress(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user.ID, "")
+	return nil
+}
+
+func (r *CachedUserRepository) UpdateMfa(ctx context.Context, user *entity.User) error {
+	if err := r.UserRepositoryImpl.UpdateMfa(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user.ID, "")
+	return nil
+}
+
+// invalidate 立即清除缓存，并在事务提交后再清除一次，
+// 避免事务提交前其他请求读取旧数据回填缓存
+func (r *CachedUserRepository) invalidate(ctx context.Context, userId uint64, username string) {
+	keys := []string{cache.UserByIDKey.Key(userId)}
+	if username != "" {
+		keys = append(keys, cache.UserByUsernameKey.Key(username))
+	}
+
+	evict := func(ctx context.Context) {
+		for _, key := range keys {
+			r.deleteCached(ctx, key)
+		}
+	}
+
+	evict(ctx)
+	database.AfterCommit(ctx, evict)
+}
+
+// store 缓存用户数据和用户名索引，返回序列化后的数据
+func (r *CachedUserRepository) store(ctx context.Context, user *entity.User) (string, error) {
+	data, err := json.Marshal(r.entityToModel(user))
+	if err != nil {
+		return "", err
+	}
+
+	r.setCached(ctx, cache.UserByIDKey.Key(user.ID), string(data), userCacheTTL)
+	r.setCached(ctx, cache.UserByUsernameKey.Key(user.Username), strconv.FormatUint(user.ID, 10), userCacheTTL)
+
+	return string(data), nil
+}
+
+func (r *CachedUserRepository) decode(data string) (*entity.User, error) {
+	var userModel model.UserModel
+	if err := json.Unmarshal([]byte(data), &userModel); err != nil {
+		return nil, err
+	}
+	return r.modelToEntity(&userModel), nil
+}
+
+// getCached 读取缓存，ok 为 false 表示需要查询数据库
+// 缓存不可用时降级为直接查询数据库
+func (r *CachedUserRepository) getCached(ctx context.Context, key string) (value string, ok bool, err error) {
+	value, err = r.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrCacheMiss) {
+			r.logger.Warn("Failed to read user cache", zap.Error(err), zap.String("key", key))
+		}
+		return "", false, nil
+	}
+
+	if value == userNotFoundMarker {
+		return "", true, domainErrors.ErrUserNotFound
+	}
+	return value, true, nil
+}
+
+func (r *CachedUserRepository) setCached(ctx context.Context, key, value string, ttl time.Duration) {
+	if database.OnReplica(ctx) && ttl > userReplicaCacheTTL {
+		ttl = userReplicaCacheTTL
+	}
+	if err := r.cache.SetWithTTL(ctx, key, value, ttl); err != nil {
+		r.logger.Warn("Failed to write user cache", zap.Error(err), zap.String("key", key))
+	}
+}
+
+func (r *CachedUserRepository) deleteCached(ctx context.Context, key string) {
+	if err := r.cache.Delete(ctx, key); err != nil {
+		r.logger.Warn("Failed to delete user cache", zap.Error(err), zap.String("key", key))
+	}
+}
@@ -0,0 +1,173 @@
+package repository_impl
+
+import (
+	"context"
+
+	"github.com/lyonnee/go-template/internal/domain/entity"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/domain/repository"
+	"github.com/lyonnee/go-template/internal/infrastructure/blockchain"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// ERC-20 balanceOf(address) 方法选择器
+const erc20BalanceOfSelector = "0x70a08231"
+
+// 保证对接口实现
+var _ repository.EthRepository = (*EthRepositoryImpl)(nil)
+
+// EthRepositoryImpl 基于 JSON-RPC 的 EVM 链数据存储库实现
+type EthRepositoryImpl struct {
+	logger *log.Logger
+	client *blockchain.Client
+}
+
+func init() {
+	err := di.AddSingletonImpl[repository.EthRepository, *EthRepositoryImpl](NewEthRepository)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewEthRepository 创建一个新的链数据存储库实例
+func NewEthRepository() (*EthRepositoryImpl, error) {
+	repo := &EthRepositoryImpl{
+		logger: di.Get[*log.Logger](),
+		client: di.Get[*blockchain.Client](),
+	}
+
+	return repo, nil
+}
+
+// ETHBalance 查询地址的原生代币余额
+func (r *EthRepositoryImpl) ETHBalance(ctx context.Context, address string) (string, error) {
+	if !blockchain.IsAddress(address) {
+		return "", domainErrors.ErrInvalidAddress
+	}
+
+	var result string
+	if err := r.client.Call(ctx, &result, "eth_getBalance", address, "latest"); err != nil {
+		r.logger.Error("Failed to get balance", zap.String("address", address), zap.Error(err))
+		return "", err
+	}
+
+	balance, err := blockchain.ParseBigQuantity(result)
+	if err != nil {
+		return "", err
+	}
+
+	return balance.String(), nil
+}
+
+// Nonce 查询地址的交易计数（包含待打包交易）
+func (r *EthRepositoryImpl) Nonce(ctx context.Context, address string) (uint64, error) {
+	if !blockchain.IsAddress(address) {
+		return 0, domainErrors.ErrInvalidAddress
+	}
+
+	var result string
+	if err := r.client.Call(ctx, &result, "eth_getTransactionCount", address, "pending"); err != nil {
+		r.logger.Error("Failed to get nonce", zap.String("address", address), zap.Error(err))
+		return 0, err
+	}
+
+	return blockchain.ParseQuantity(result)
+}
+
+// BlockNumber 查询最新区块高度
+func (r *EthRepositoryImpl) BlockNumber(ctx context.Context) (uint64, error) {
+	var result string
+	if err := r.client.Call(ctx, &result, "eth_blockNumber"); err != nil {
+		r.logger.Error("Failed to get block number", zap.Error(err))
+		return 0, err
+	}
+
+	return blockchain.ParseQuantity(result)
+}
+
+type receiptResult struct {
+	TransactionHash   string  `json:"transactionHash"`
+	BlockHash         string  `json:"blockHash"`
+	BlockNumber       string  `json:"blockNumber"`
+	From              string  `json:"from"`
+	To                *string `json:"to"`
+	ContractAddress   *string `json:"contractAddress"`
+	GasUsed           string  `json:"gasUsed"`
+	EffectiveGasPrice string  `json:"effectiveGasPrice"`
+	Status            string  `json:"status"`
+}
+
+// TransactionReceipt 查询交易回执
+func (r *EthRepositoryImpl) TransactionReceipt(ctx context.Context, txHash string) (*entity.TransactionReceipt, error) {
+	if !blockchain.IsHash(txHash) {
+		return nil, domainErrors.ErrInvalidTxHash
+	}
+
+	var result *receiptResult
+	if err := r.client.Call(ctx, &result, "eth_getTransactionReceipt", txHash); err != nil {
+		r.logger.Error("Failed to get transaction receipt", zap.String("txHash", txHash), zap.Error(err))
+		return nil, err
+	}
+	if result == nil {
+		return nil, domainErrors.ErrReceiptNotFound
+	}
+
+	receipt := &entity.TransactionReceipt{
+		TxHash:    result.TransactionHash,
+		BlockHash: result.BlockHash,
+		From:      result.From,
+	}
+	if result.To != nil {
+		receipt.To = *result.To
+	}
+	if result.ContractAddress != nil {
+		receipt.ContractAddress = *result.ContractAddress
+	}
+
+	var err error
+	if receipt.BlockNumber, err = blockchain.ParseQuantity(result.BlockNumber); err != nil {
+		return nil, err
+	}
+	if receipt.GasUsed, err = blockchain.ParseQuantity(result.GasUsed); err != nil {
+		return nil, err
+	}
+	if receipt.Status, err = blockchain.ParseQuantity(result.Status); err != nil {
+		return nil, err
+	}
+	if result.EffectiveGasPrice != "" {
+		gasPrice, err := blockchain.ParseBigQuantity(result.EffectiveGasPrice)
+		if err != nil {
+			return nil, err
+		}
+		receipt.EffectiveGasPrice = gasPrice.String()
+	}
+
+	return receipt, nil
+}
+
+// ERC20BalanceOf 查询 ERC-20 代币余额
+func (r *EthRepositoryImpl) ERC20BalanceOf(ctx context.Context, token, owner string) (string, error) {
+	if !blockchain.IsAddress(token) || !blockchain.IsAddress(owner) {
+		return "", domainErrors.ErrInvalidAddress
+	}
+
+	call := map[string]string{
+		"to":   token,
+		"data": erc20BalanceOfSelector + blockchain.EncodeAddressArg(owner),
+	}
+
+	var result string
+	if err := r.client.Call(ctx, &result, "eth_call", call, "latest"); err != nil {
+		r.logger.Error("Failed to get ERC-20 balance", zap.String("token", token), zap.String("owner", owner), zap.Error(err))
+		return "", err
+	}
+
+	balance, err := blockchain.ParseBigQuantity(result)
+	if err != nil {
+		return "", err
+	}
+
+	return balance.String(), nil
+}
@@ -0,0 +1,14 @@
+package model
+
+type OutboxModel struct {
+	BaseModel
+
+	Topic         string `json:"topic" db:"topic"`                     // Message queue topic
+	Payload       []byte `json:"payload" db:"payload"`                 // Message body
+	Headers       []byte `json:"headers" db:"headers"`                 // Message headers (JSON)
+	Status        int    `json:"status" db:"status"`                   // Delivery status
+	Attempts      int    `json:"attempts" db:"attempts"`               // Failed delivery attempts
+	NextAttemptAt int64  `json:"next_attempt_at" db:"next_attempt_at"` // Next delivery time
+	LastError     string `json:"last_error" db:"last_error"`           // Last delivery error
+	DeliveredAt   int64  `json:"delivered_at" db:"delivered_at"`       // Delivery time
+}
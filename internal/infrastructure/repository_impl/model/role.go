@@ -0,0 +1,9 @@
+package model
+
+type RoleModel struct {
+	BaseModel
+
+	Name        string   `json:"name" db:"name"`               // Unique role name
+	Description string   `json:"description" db:"description"` // Human readable description
+	Permissions []string `json:"permissions" db:"-"`           // Names of the permissions granted to the role
+}
@@ -8,4 +8,9 @@ type UserModel struct {
 	Email       string `json:"email" db:"email"`                 // Email of the user
 	Phone       string `json:"phone" db:"phone"`                 // Phone number of the user
 	LastLoginAt int64  `json:"last_login_at" db:"last_login_at"` // Last login time of the user
+
+	WalletAddress string `json:"wallet_address" db:"wallet_address"` // EIP-55 checksum wallet address of the user
+
+	MfaSecret  string `json:"mfa_secret" db:"mfa_secret"`   // Encrypted TOTP secret of the user
+	MfaEnabled bool   `json:"mfa_enabled" db:"mfa_enabled"` // Whether multi-factor authentication is enabled
 }
@@ -0,0 +1,10 @@
+package model
+
+type UserIdentityModel struct {
+	BaseModel
+
+	UserID   uint64 `json:"user_id" db:"user_id"`   // ID of the linked user
+	Provider string `json:"provider" db:"provider"` // Name of the identity provider
+	Subject  string `json:"subject" db:"subject"`   // Subject identifier at the identity provider
+	Email    string `json:"email" db:"email"`       // Email returned by the identity provider when linked
+}
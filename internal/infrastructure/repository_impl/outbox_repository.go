@@ -0,0 +1,223 @@
+package repository_impl
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/database"
+	"github.com/lyonnee/go-template/internal/infrastructure/outbox"
+	"github.com/lyonnee/go-template/internal/infrastructure/repository_impl/model"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// 保证对接口实现
+var _ outbox.Repository = (*OutboxRepositoryImpl)(nil)
+
+// OutboxRepositoryImpl 发件箱存储库实现
+type OutboxRepositoryImpl struct {
+	logger *log.Logger
+}
+
+func init() {
+	err := di.AddSingletonImpl[outbox.Repository, *OutboxRepositoryImpl](NewOutboxRepository)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewOutboxRepository 创建一个新的发件箱存储库实例
+func NewOutboxRepository() (*OutboxRepositoryImpl, error) {
+	repo := &OutboxRepositoryImpl{
+		logger: di.Get[*log.Logger](),
+	}
+
+	return repo, nil
+}
+
+// Add 写入待投递消息
+func (r *OutboxRepositoryImpl) Add(ctx context.Context, msgs ...*outbox.Message) error {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return err
+	}
+
+	now := time.Now().Unix()
+	query := `
+		INSERT INTO outbox (created_at, updated_at, topic, payload, headers, status, attempts, next_attempt_at) 
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?)
+	`
+
+	for _, msg := range msgs {
+		headers, err := json.Marshal(msg.Headers)
+		if err != nil {
+			return err
+		}
+
+		// headers 以字符串写入，MySQL 的 JSON 列不接受二进制字符串
+		id, err := database.InsertReturningID(ctx, dbExecutor, query,
+			now,
+			now,
+			msg.Topic,
+			msg.Payload,
+			string(headers),
+			outbox.StatusPending,
+			now,
+		)
+		if err != nil {
+			r.logger.Error("Failed to add outbox message", zap.String("topic", msg.Topic), zap.Error(err))
+			return err
+		}
+
+		msg.ID = id
+		msg.CreatedAt = now
+	}
+
+	return nil
+}
+
+// FetchPending 锁定并返回已到投递时间的消息
+func (r *OutboxRepositoryImpl) FetchPending(ctx context.Context, limit int) ([]*outbox.Message, error) {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return nil, err
+	}
+
+	query := database.Rebind(`
+		SELECT id, created_at, updated_at, topic, payload, headers, status, attempts, next_attempt_at, last_error, delivered_at 
+		FROM outbox 
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED
+	`)
+
+	rows, err := dbExecutor.QueryxContext(ctx, query, outbox.StatusPending, time.Now().Unix(), limit)
+	if err != nil {
+		r.logger.Error("Failed to fetch pending outbox messages", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var msgs []*outbox.Message
+	for rows.Next() {
+		var outboxModel model.OutboxModel
+		if err := rows.StructScan(&outboxModel); err != nil {
+			return nil, err
+		}
+
+		msg, err := r.modelToMessage(&outboxModel)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, rows.Err()
+}
+
+// MarkDelivered 标记消息已投递
+func (r *OutboxRepositoryImpl) MarkDelivered(ctx context.Context, id uint64) error {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return err
+	}
+
+	now := time.Now().Unix()
+	query := database.Rebind(`
+		UPDATE outbox 
+		SET updated_at = ?, status = ?, delivered_at = ? 
+		WHERE id = ?
+	`)
+
+	_, err = dbExecutor.ExecContext(ctx, query, now, outbox.StatusDelivered, now, id)
+	return err
+}
+
+// MarkFailed 记录投递失败
+func (r *OutboxRepositoryImpl) MarkFailed(ctx context.Context, id uint64, attempts int, nextAttemptAt int64, reason string) error {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return err
+	}
+
+	query := database.Rebind(`
+		UPDATE outbox 
+		SET updated_at = ?, attempts = ?, next_attempt_at = ?, last_error = ? 
+		WHERE id = ?
+	`)
+
+	_, err = dbExecutor.ExecContext(ctx, query, time.Now().Unix(), attempts, nextAttemptAt, reason, id)
+	return err
+}
+
+// Quarantine 隔离消息
+func (r *OutboxRepositoryImpl) Quarantine(ctx context.Context, id uint64, attempts int, reason string) error {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return err
+	}
+
+	query := database.Rebind(`
+		UPDATE outbox 
+		SET updated_at = ?, status = ?, attempts = ?, last_error = ? 
+		WHERE id = ?
+	`)
+
+	_, err = dbExecutor.ExecContext(ctx, query, time.Now().Unix(), outbox.StatusQuarantined, attempts, reason, id)
+	return err
+}
+
+// DeleteDelivered 删除过期的已投递消息
+func (r *OutboxRepositoryImpl) DeleteDelivered(ctx context.Context, before int64, limit int) (int64, error) {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return 0, err
+	}
+
+	// MySQL 不支持在 IN 子查询中使用 LIMIT，通过派生表限制单次删除的行数
+	query := database.Rebind(`
+		DELETE FROM outbox 
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id FROM outbox 
+				WHERE status = ? AND delivered_at < ? 
+				ORDER BY id 
+				LIMIT ?
+			) AS expired
+		)
+	`)
+
+	result, err := dbExecutor.ExecContext(ctx, query, outbox.StatusDelivered, before, limit)
+	if err != nil {
+		r.logger.Error("Failed to delete delivered outbox messages", zap.Error(err))
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *OutboxRepositoryImpl) modelToMessage(outboxModel *model.OutboxModel) (*outbox.Message, error) {
+	msg := &outbox.Message{
+		ID:        outboxModel.ID,
+		CreatedAt: outboxModel.CreatedAt,
+		Topic:     outboxModel.Topic,
+		Payload:   outboxModel.Payload,
+		Attempts:  outboxModel.Attempts,
+	}
+
+	if len(outboxModel.Headers) > 0 {
+		if err := json.Unmarshal(outboxModel.Headers, &msg.Headers); err != nil {
+			return nil, err
+		}
+	}
+
+	return msg, nil
+}
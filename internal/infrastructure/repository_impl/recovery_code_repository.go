@@ -0,0 +1,112 @@
+package repository_impl
+
+import (
+	"context"
+	"time"
+
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/domain/repository"
+	"github.com/lyonnee/go-template/internal/infrastructure/database"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// 保证对接口实现
+var _ repository.RecoveryCodeRepository = (*RecoveryCodeRepositoryImpl)(nil)
+
+// RecoveryCodeRepositoryImpl 恢复码存储库实现
+type RecoveryCodeRepositoryImpl struct {
+	logger *log.Logger
+}
+
+func init() {
+	err := di.AddSingletonImpl[repository.RecoveryCodeRepository, *RecoveryCodeRepositoryImpl](NewRecoveryCodeRepository)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewRecoveryCodeRepository 创建一个新的恢复码存储库实例
+func NewRecoveryCodeRepository() (*RecoveryCodeRepositoryImpl, error) {
+	repo := &RecoveryCodeRepositoryImpl{
+		logger: di.Get[*log.Logger](),
+	}
+
+	return repo, nil
+}
+
+// Replace 删除用户原有的恢复码并保存新的恢复码，应在事务中调用
+func (r *RecoveryCodeRepositoryImpl) Replace(ctx context.Context, userID uint64, codeHashes []string) error {
+	if err := r.DeleteByUserID(ctx, userID); err != nil {
+		return err
+	}
+
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return err
+	}
+
+	now := time.Now().Unix()
+	query := database.Rebind(`
+		INSERT INTO user_recovery_codes (created_at, user_id, code_hash)
+		VALUES (?, ?, ?)
+	`)
+
+	for _, codeHash := range codeHashes {
+		if _, err := dbExecutor.ExecContext(ctx, query, now, userID, codeHash); err != nil {
+			r.logger.Error("Failed to create recovery code", zap.Uint64("userId", userID), zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Consume 将恢复码标记为已使用，条件更新保证并发请求中只有一个能成功
+func (r *RecoveryCodeRepositoryImpl) Consume(ctx context.Context, userID uint64, codeHash string) error {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return err
+	}
+
+	query := database.Rebind(`
+		UPDATE user_recovery_codes
+		SET used_at = ?
+		WHERE user_id = ? AND code_hash = ? AND used_at = 0
+	`)
+
+	result, err := dbExecutor.ExecContext(ctx, query, time.Now().Unix(), userID, codeHash)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domainErrors.ErrInvalidMfaCode
+	}
+
+	return nil
+}
+
+// DeleteByUserID 删除用户的全部恢复码
+func (r *RecoveryCodeRepositoryImpl) DeleteByUserID(ctx context.Context, userID uint64) error {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return err
+	}
+
+	if _, err := dbExecutor.ExecContext(ctx, database.Rebind(`DELETE FROM user_recovery_codes WHERE user_id = ?`), userID); err != nil {
+		r.logger.Error("Failed to delete recovery codes", zap.Uint64("userId", userID), zap.Error(err))
+		return err
+	}
+
+	return nil
+}
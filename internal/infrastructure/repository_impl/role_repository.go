@@ -0,0 +1,132 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lyonnee/go-template/internal/domain/entity"
+	"github.com/lyonnee/go-template/internal/domain/repository"
+	"github.com/lyonnee/go-template/internal/infrastructure/database"
+	"github.com/lyonnee/go-template/internal/infrastructure/repository_impl/model"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// 保证对接口实现
+var _ repository.RoleRepository = (*RoleRepositoryImpl)(nil)
+
+// roleSelect 查询角色及其权限，每个权限一行，没有权限的角色 permission 为 NULL
+const roleSelect = `
+	SELECT r.id, r.created_at, r.updated_at, r.name, r.description, p.name AS permission
+	FROM roles r
+	LEFT JOIN role_permissions rp ON rp.role_id = r.id
+	LEFT JOIN permissions p ON p.id = rp.permission_id
+`
+
+// roleRow roleSelect 查询的一行
+type roleRow struct {
+	model.BaseModel
+
+	Name        string         `db:"name"`
+	Description string         `db:"description"`
+	Permission  sql.NullString `db:"permission"`
+}
+
+// RoleRepositoryImpl 角色存储库实现
+type RoleRepositoryImpl struct {
+	logger *log.Logger
+}
+
+func init() {
+	err := di.AddSingletonImpl[repository.RoleRepository, *RoleRepositoryImpl](NewRoleRepository)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewRoleRepository 创建一个新的角色存储库实例
+func NewRoleRepository() (*RoleRepositoryImpl, error) {
+	repo := &RoleRepositoryImpl{
+		logger: di.Get[*log.Logger](),
+	}
+
+	return repo, nil
+}
+
+// FindByUserID 查询用户拥有的角色
+func (r *RoleRepositoryImpl) FindByUserID(ctx context.Context, userID uint64) ([]*entity.Role, error) {
+	query := roleSelect + `
+	JOIN user_roles ur ON ur.role_id = r.id
+	WHERE ur.user_id = ?
+	ORDER BY r.id
+	`
+
+	return r.find(ctx, query, userID)
+}
+
+// FindByNames 根据角色名称批量查询
+func (r *RoleRepositoryImpl) FindByNames(ctx context.Context, names []string) ([]*entity.Role, error) {
+	if len(names) == 0 {
+		return []*entity.Role{}, nil
+	}
+
+	query, args, err := sqlx.In(roleSelect+`
+	WHERE r.name IN (?)
+	ORDER BY r.id
+	`, names)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.find(ctx, query, args...)
+}
+
+func (r *RoleRepositoryImpl) find(ctx context.Context, query string, args ...any) ([]*entity.Role, error) {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return nil, err
+	}
+
+	var rows []roleRow
+	if err := sqlx.SelectContext(ctx, dbExecutor, &rows, database.Rebind(query), args...); err != nil {
+		r.logger.Error("Failed to query roles", zap.Error(err))
+		return nil, err
+	}
+
+	// 按角色聚合权限，查询结果已按角色ID排序
+	var roleModels []*model.RoleModel
+	for _, row := range rows {
+		if len(roleModels) == 0 || roleModels[len(roleModels)-1].ID != row.ID {
+			roleModels = append(roleModels, &model.RoleModel{
+				BaseModel:   row.BaseModel,
+				Name:        row.Name,
+				Description: row.Description,
+				Permissions: []string{},
+			})
+		}
+		if row.Permission.Valid {
+			last := roleModels[len(roleModels)-1]
+			last.Permissions = append(last.Permissions, row.Permission.String)
+		}
+	}
+
+	roles := make([]*entity.Role, 0, len(roleModels))
+	for _, roleModel := range roleModels {
+		roles = append(roles, r.modelToEntity(roleModel))
+	}
+	return roles, nil
+}
+
+func (r *RoleRepositoryImpl) modelToEntity(roleModel *model.RoleModel) *entity.Role {
+	return &entity.Role{
+		ID:          roleModel.ID,
+		CreatedAt:   roleModel.CreatedAt,
+		UpdatedAt:   roleModel.UpdatedAt,
+		Name:        roleModel.Name,
+		Description: roleModel.Description,
+		Permissions: roleModel.Permissions,
+	}
+}
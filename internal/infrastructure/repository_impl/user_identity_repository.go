@@ -0,0 +1,126 @@
+package repository_impl
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/domain/entity"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/domain/repository"
+	"github.com/lyonnee/go-template/internal/infrastructure/database"
+	"github.com/lyonnee/go-template/internal/infrastructure/repository_impl/model"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// 保证对接口实现
+var _ repository.UserIdentityRepository = (*UserIdentityRepositoryImpl)(nil)
+
+// UserIdentityRepositoryImpl 第三方身份存储库实现
+type UserIdentityRepositoryImpl struct {
+	logger *log.Logger
+}
+
+func init() {
+	err := di.AddSingletonImpl[repository.UserIdentityRepository, *UserIdentityRepositoryImpl](NewUserIdentityRepository)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewUserIdentityRepository 创建一个新的第三方身份存储库实例
+func NewUserIdentityRepository() (*UserIdentityRepositoryImpl, error) {
+	repo := &UserIdentityRepositoryImpl{
+		logger: di.Get[*log.Logger](),
+	}
+
+	return repo, nil
+}
+
+// Create 关联第三方身份
+func (r *UserIdentityRepositoryImpl) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	if identity == nil || identity.UserID == 0 {
+		return domainErrors.ErrInvalidUserInput
+	}
+
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return err
+	}
+
+	now := time.Now().Unix()
+	query := `
+		INSERT INTO user_identities (created_at, updated_at, user_id, provider, subject, email)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	id, err := database.InsertReturningID(ctx, dbExecutor, query,
+		now,
+		now,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+	)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return domainErrors.ErrUserIdentityTaken
+		}
+		r.logger.Error("Failed to create user identity",
+			zap.Uint64("userId", identity.UserID),
+			zap.String("provider", identity.Provider),
+			zap.Error(err))
+		return err
+	}
+
+	identity.ID = id
+	identity.CreatedAt = now
+	identity.UpdatedAt = now
+
+	r.logger.Info("User identity linked successfully",
+		zap.Uint64("userId", identity.UserID),
+		zap.String("provider", identity.Provider))
+
+	return nil
+}
+
+// FindByProviderSubject 根据身份提供方和用户唯一标识查找身份
+func (r *UserIdentityRepositoryImpl) FindByProviderSubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return nil, err
+	}
+
+	query := database.Rebind(`
+		SELECT id, created_at, updated_at, user_id, provider, subject, email
+		FROM user_identities
+		WHERE provider = ? AND subject = ?
+	`)
+
+	var identityModel model.UserIdentityModel
+	if err := dbExecutor.QueryRowxContext(ctx, query, provider, subject).StructScan(&identityModel); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domainErrors.ErrUserIdentityNotFound
+		}
+		return nil, err
+	}
+
+	return r.modelToEntity(&identityModel), nil
+}
+
+func (r *UserIdentityRepositoryImpl) modelToEntity(identityModel *model.UserIdentityModel) *entity.UserIdentity {
+	return &entity.UserIdentity{
+		ID:        identityModel.ID,
+		CreatedAt: identityModel.CreatedAt,
+		UpdatedAt: identityModel.UpdatedAt,
+		UserID:    identityModel.UserID,
+		Provider:  identityModel.Provider,
+		Subject:   identityModel.Subject,
+		Email:     identityModel.Email,
+	}
+}
@@ -26,13 +26,6 @@ type UserRepositoryImpl struct {
 	logger *log.Logger
 }
 
-func init() {
-	err := di.AddSingletonImpl[repository.UserRepository, *UserRepositoryImpl](NewUserRepository)
-	if err != nil {
-		panic(err)
-	}
-}
-
 // NewUserRepository 创建一个新的用户存储库实例
 func NewUserRepository() (*UserRepositoryImpl, error) {
 	repo := &UserRepositoryImpl{
@@ -52,11 +45,11 @@ func (r *UserRepositoryImpl) FindById(ctx context.Context, userId uint64) (*enti
 		return nil, err
 	}
 
-	query := `
-		SELECT id, created_at, updated_at, username, pwd_secret, email, phone, deleted_at 
+	query := database.Rebind(`
+		SELECT id, created_at, updated_at, username, pwd_secret, email, phone, wallet_address, mfa_secret, mfa_enabled, deleted_at 
 		FROM users 
-		WHERE id = $1 AND deleted_at = 0
-	`
+		WHERE id = ? AND deleted_at = 0
+	`)
 
 	var userModel model.UserModel
 	err = dbExecutor.QueryRowxContext(ctx, query, userId).Scan(
@@ -67,6 +60,9 @@ func (r *UserRepositoryImpl) FindById(ctx context.Context, userId uint64) (*enti
 		&userModel.PwdSecret,
 		&userModel.Email,
 		&userModel.Phone,
+		&userModel.WalletAddress,
+		&userModel.MfaSecret,
+		&userModel.MfaEnabled,
 		&userModel.DeletedAt,
 	)
 
@@ -100,20 +96,19 @@ func (r *UserRepositoryImpl) Create(ctx context.Context, user *entity.User) erro
 
 	now := time.Now().Unix()
 	query := `
-		INSERT INTO users (created_at, updated_at, username, pwd_secret, email, phone) 
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id
+		INSERT INTO users (created_at, updated_at, username, pwd_secret, email, phone, wallet_address) 
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
-	var id uint64
-	err = dbExecutor.QueryRowxContext(ctx, query,
+	id, err := database.InsertReturningID(ctx, dbExecutor, query,
 		now,
 		now,
 		user.Username,
 		user.PwdSecret,
 		user.Email,
 		user.Phone,
-	).Scan(&id)
+		user.WalletAddress,
+	)
 
 	if err != nil {
 		r.logger.Error("Failed to create user",
@@ -154,11 +149,11 @@ func (r *UserRepositoryImpl) Update(ctx context.Context, user *entity.User) erro
 	}
 
 	now := time.Now().Unix()
-	query := `
+	query := database.Rebind(`
 		UPDATE users 
-		SET updated_at = $1, username = $2, pwd_secret = $3, email = $4, phone = $5 
-		WHERE id = $6
-	`
+		SET updated_at = ?, username = ?, pwd_secret = ?, email = ?, phone = ? 
+		WHERE id = ?
+	`)
 
 	_, err = dbExecutor.ExecContext(ctx, query,
 		now,
@@ -186,11 +181,11 @@ func (r *UserRepositoryImpl) Delete(ctx context.Context, userId uint64) error {
 	}
 
 	now := time.Now().Unix()
-	query := `
+	query := database.Rebind(`
 		UPDATE users 
-		SET deleted_at = $1 
-		WHERE id = $2
-	`
+		SET deleted_at = ? 
+		WHERE id = ?
+	`)
 
 	result, err := dbExecutor.ExecContext(ctx, query, now, userId)
 	if err != nil {
@@ -217,11 +212,11 @@ func (r *UserRepositoryImpl) FindByUsername(ctx context.Context, username string
 		return nil, err
 	}
 
-	query := `
-		SELECT id, created_at, updated_at, username, pwd_secret, email, phone, deleted_at 
+	query := database.Rebind(`
+		SELECT id, created_at, updated_at, username, pwd_secret, email, phone, wallet_address, mfa_secret, mfa_enabled, deleted_at 
 		FROM users 
-		WHERE username = $1 AND deleted_at = 0
-	`
+		WHERE username = ? AND deleted_at = 0
+	`)
 
 	var userModel model.UserModel
 
@@ -244,11 +239,11 @@ func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (*en
 		return nil, err
 	}
 
-	query := `
-		SELECT id, created_at, updated_at, username, pwd_secret, email, phone, deleted_at 
+	query := database.Rebind(`
+		SELECT id, created_at, updated_at, username, pwd_secret, email, phone, wallet_address, mfa_secret, mfa_enabled, deleted_at 
 		FROM users 
-		WHERE email = $1 AND deleted_at = 0
-	`
+		WHERE email = ? AND deleted_at = 0
+	`)
 
 	var userModel model.UserModel
 	err = dbExecutor.QueryRowxContext(ctx, query, email).Scan(
@@ -259,6 +254,9 @@ func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (*en
 		&userModel.PwdSecret,
 		&userModel.Email,
 		&userModel.Phone,
+		&userModel.WalletAddress,
+		&userModel.MfaSecret,
+		&userModel.MfaEnabled,
 		&userModel.DeletedAt,
 	)
 
@@ -280,11 +278,11 @@ func (r *UserRepositoryImpl) FindByPhone(ctx context.Context, phone string) (*en
 		return nil, err
 	}
 
-	query := `
-		SELECT id, created_at, updated_at, username, pwd_secret, email, phone, deleted_at 
+	query := database.Rebind(`
+		SELECT id, created_at, updated_at, username, pwd_secret, email, phone, wallet_address, mfa_secret, mfa_enabled, deleted_at 
 		FROM users 
-		WHERE phone = $1 AND deleted_at = 0
-	`
+		WHERE phone = ? AND deleted_at = 0
+	`)
 
 	var userModel model.UserModel
 	if err = dbExecutor.QueryRowxContext(ctx, query, phone).Scan(
@@ -295,6 +293,9 @@ func (r *UserRepositoryImpl) FindByPhone(ctx context.Context, phone string) (*en
 		&userModel.PwdSecret,
 		&userModel.Email,
 		&userModel.Phone,
+		&userModel.WalletAddress,
+		&userModel.MfaSecret,
+		&userModel.MfaEnabled,
 		&userModel.DeletedAt,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -341,11 +342,11 @@ func (r *UserRepositoryImpl) UpdateUsername(ctx context.Context, user *entity.Us
 	}
 
 	now := time.Now().Unix()
-	query := `
+	query := database.Rebind(`
 		UPDATE users 
-		SET updated_at = $1, username = $2 
-		WHERE id = $3
-	`
+		SET updated_at = ?, username = ? 
+		WHERE id = ?
+	`)
 
 	result, err := dbExecutor.ExecContext(ctx, query, now, user.Username, user.ID)
 	if err != nil {
@@ -389,11 +390,11 @@ func (r *UserRepositoryImpl) UpdatePwdSecret(ctx context.Context, user *entity.U
 	}
 
 	now := time.Now().Unix()
-	query := `
+	query := database.Rebind(`
 		UPDATE users 
-		SET updated_at = $1, pwd_secret = $2 
-		WHERE id = $3
-	`
+		SET updated_at = ?, pwd_secret = ? 
+		WHERE id = ?
+	`)
 
 	result, err := dbExecutor.ExecContext(ctx, query, now, user.PwdSecret, user.ID)
 	if err != nil {
@@ -435,11 +436,11 @@ func (r *UserRepositoryImpl) UpdateEmail(ctx context.Context, user *entity.User)
 	}
 
 	now := time.Now().Unix()
-	query := `
+	query := database.Rebind(`
 		UPDATE users 
-		SET updated_at = $1, email = $2 
-		WHERE id = $3
-	`
+		SET updated_at = ?, email = ? 
+		WHERE id = ?
+	`)
 
 	result, err := dbExecutor.ExecContext(ctx, query, now, user.Email, user.ID)
 	if err != nil {
@@ -481,11 +482,11 @@ func (r *UserRepositoryImpl) UpdatePhone(ctx context.Context, user *entity.User)
 	}
 
 	now := time.Now().Unix()
-	query := `
+	query := database.Rebind(`
 		UPDATE users 
-		SET updated_at = $1, phone = $2 
-		WHERE id = $3
-	`
+		SET updated_at = ?, phone = ? 
+		WHERE id = ?
+	`)
 
 	result, err := dbExecutor.ExecContext(ctx, query, now, user.Phone, user.ID)
 	if err != nil {
@@ -505,6 +506,114 @@ func (r *UserRepositoryImpl) UpdatePhone(ctx context.Context, user *entity.User)
 	return nil
 }
 
+// FindByWalletAddress 根据钱包地址查找用户
+func (r *UserRepositoryImpl) FindByWalletAddress(ctx context.Context, walletAddress string) (*entity.User, error) {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return nil, err
+	}
+
+	query := database.Rebind(`
+		SELECT id, created_at, updated_at, username, pwd_secret, email, phone, wallet_address, mfa_secret, mfa_enabled, deleted_at 
+		FROM users 
+		WHERE wallet_address = ? AND deleted_at = 0
+	`)
+
+	var userModel model.UserModel
+	if err = dbExecutor.QueryRowxContext(ctx, query, walletAddress).StructScan(&userModel); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domainErrors.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return r.modelToEntity(&userModel), nil
+}
+
+// UpdateWalletAddress 更新钱包地址
+func (r *UserRepositoryImpl) UpdateWalletAddress(ctx context.Context, user *entity.User) error {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return err
+	}
+
+	if user == nil || user.ID == 0 || user.WalletAddress == "" {
+		return domainErrors.ErrInvalidUserInput
+	}
+
+	// 检查钱包地址是否已被绑定
+	existingUser, err := r.FindByWalletAddress(ctx, user.WalletAddress)
+	if err != nil && !errors.Is(err, domainErrors.ErrUserNotFound) {
+		return err
+	}
+	if existingUser != nil && existingUser.ID != user.ID {
+		return domainErrors.ErrWalletAddressTaken
+	}
+
+	now := time.Now().Unix()
+	query := database.Rebind(`
+		UPDATE users 
+		SET updated_at = ?, wallet_address = ? 
+		WHERE id = ?
+	`)
+
+	result, err := dbExecutor.ExecContext(ctx, query, now, user.WalletAddress, user.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domainErrors.ErrUserNotFound
+	}
+
+	user.UpdatedAt = now
+	return nil
+}
+
+// UpdateMfa 更新多因素认证密钥和启用状态
+func (r *UserRepositoryImpl) UpdateMfa(ctx context.Context, user *entity.User) error {
+	dbExecutor, err := database.GetDBExecutor(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get DBExecutor", zap.Error(err))
+		return err
+	}
+
+	if user == nil || user.ID == 0 {
+		return domainErrors.ErrInvalidUserInput
+	}
+
+	now := time.Now().Unix()
+	query := database.Rebind(`
+		UPDATE users 
+		SET updated_at = ?, mfa_secret = ?, mfa_enabled = ? 
+		WHERE id = ? AND deleted_at = 0
+	`)
+
+	result, err := dbExecutor.ExecContext(ctx, query, now, user.MfaSecret, user.MfaEnabled, user.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return domainErrors.ErrUserNotFound
+	}
+
+	user.UpdatedAt = now
+	return nil
+}
+
 // 检查用户字段是否存在
 func (r *UserRepositoryImpl) CheckUserFieldsExist(ctx context.Context, username, email, phone string) (bool, error) {
 	dbExecutor, err := database.GetDBExecutor(ctx)
@@ -573,22 +682,28 @@ func (r *UserRepositoryImpl) entityToModel(user *entity.User) *model.UserModel {
 			},
 			DeletedAt: user.DeletedAt,
 		},
-		Username:  user.Username,
-		PwdSecret: user.PwdSecret,
-		Email:     user.Email,
-		Phone:     user.Phone,
+		Username:      user.Username,
+		PwdSecret:     user.PwdSecret,
+		Email:         user.Email,
+		Phone:         user.Phone,
+		WalletAddress: user.WalletAddress,
+		MfaSecret:     user.MfaSecret,
+		MfaEnabled:    user.MfaEnabled,
 	}
 }
 
 func (r *UserRepositoryImpl) modelToEntity(userModel *model.UserModel) *entity.User {
 	return &entity.User{
-		ID:        userModel.ID,
-		CreatedAt: userModel.CreatedAt,
-		UpdatedAt: userModel.UpdatedAt,
-		Username:  userModel.Username,
-		PwdSecret: userModel.PwdSecret,
-		Email:     userModel.Email,
-		Phone:     userModel.Phone,
-		DeletedAt: userModel.DeletedAt,
+		ID:            userModel.ID,
+		CreatedAt:     userModel.CreatedAt,
+		UpdatedAt:     userModel.UpdatedAt,
+		Username:      userModel.Username,
+		PwdSecret:     userModel.PwdSecret,
+		Email:         userModel.Email,
+		Phone:         userModel.Phone,
+		WalletAddress: userModel.WalletAddress,
+		MfaSecret:     userModel.MfaSecret,
+		MfaEnabled:    userModel.MfaEnabled,
+		DeletedAt:     userModel.DeletedAt,
 	}
 }
@@ -1 +1,34 @@
 package eventhandler
+
+import (
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/infrastructure/eventbus"
+	"github.com/lyonnee/go-template/pkg/di"
+)
+
+func init() {
+	RegisterHandlers(di.Get[*eventbus.EventBus]())
+}
+
+// RegisterHandlers 注册领域事件处理器
+func RegisterHandlers(bus *eventbus.EventBus) {
+	conf := di.Get[config.Config]()
+
+	// 用户相关
+	{
+		userEventHandler := di.Get[*UserEventHandler]()
+
+		eventbus.On(bus, userEventHandler.OnUserRegistered)
+		eventbus.On(bus, userEventHandler.OnUsernameChanged)
+		eventbus.On(bus, userEventHandler.OnUserLoggedIn)
+		eventbus.On(bus, userEventHandler.OnUserDeleted)
+		eventbus.On(bus, userEventHandler.OnWalletBound)
+		eventbus.On(bus, userEventHandler.OnMfaEnabled)
+		eventbus.On(bus, userEventHandler.OnMfaDisabled)
+
+		// 异步处理器依赖消息队列
+		if conf.Event.PublishToMQ {
+			eventbus.OnAsync(bus, "user-welcome", userEventHandler.SendWelcome)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package eventhandler
+
+import (
+	"context"
+
+	"github.com/lyonnee/go-template/internal/domain/event"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// UserEventHandler 用户领域事件处理器
+type UserEventHandler struct {
+	logger *log.Logger
+}
+
+func init() {
+	di.AddSingleton[*UserEventHandler](NewUserEventHandler)
+}
+
+func NewUserEventHandler() (*UserEventHandler, error) {
+	return &UserEventHandler{
+		logger: di.Get[*log.Logger](),
+	}, nil
+}
+
+// OnUserRegistered 用户注册
+func (h *UserEventHandler) OnUserRegistered(ctx context.Context, e *event.UserRegistered) error {
+	h.logger.Info("User registered", zap.Uint64("userId", e.UserID), zap.String("username", e.Username))
+	return nil
+}
+
+// OnUsernameChanged 用户名修改
+func (h *UserEventHandler) OnUsernameChanged(ctx context.Context, e *event.UsernameChanged) error {
+	h.logger.Info("Username changed",
+		zap.Uint64("userId", e.UserID),
+		zap.String("oldUsername", e.OldUsername),
+		zap.String("newUsername", e.NewUsername))
+	return nil
+}
+
+// OnUserLoggedIn 用户登录
+func (h *UserEventHandler) OnUserLoggedIn(ctx context.Context, e *event.UserLoggedIn) error {
+	h.logger.Info("User logged in", zap.Uint64("userId", e.UserID), zap.String("username", e.Username), zap.String("method", e.Method))
+	return nil
+}
+
+// OnWalletBound 绑定钱包
+func (h *UserEventHandler) OnWalletBound(ctx context.Context, e *event.WalletBound) error {
+	h.logger.Info("Wallet bound", zap.Uint64("userId", e.UserID), zap.String("walletAddress", e.WalletAddress))
+	return nil
+}
+
+// OnMfaEnabled 启用多因素认证
+func (h *UserEventHandler) OnMfaEnabled(ctx context.Context, e *event.MfaEnabled) error {
+	h.logger.Info("MFA enabled", zap.Uint64("userId", e.UserID))
+	return nil
+}
+
+// OnMfaDisabled 关闭多因素认证
+func (h *UserEventHandler) OnMfaDisabled(ctx context.Context, e *event.MfaDisabled) error {
+	h.logger.Info("MFA disabled", zap.Uint64("userId", e.UserID))
+	return nil
+}
+
+// OnUserDeleted 用户删除
+func (h *UserEventHandler) OnUserDeleted(ctx context.Context, e *event.UserDeleted) error {
+	h.logger.Info("User deleted", zap.Uint64("userId", e.UserID))
+	return nil
+}
+
+// SendWelcome 通过消息队列异步发送欢迎通知
+func (h *UserEventHandler) SendWelcome(ctx context.Context, e *event.UserRegistered) error {
+	// 在这里接入邮件、短信等通知渠道
+	h.logger.Info("Sending welcome notification", zap.Uint64("userId", e.UserID), zap.String("email", e.Email))
+	return nil
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// clientIP 返回调用方的IP，无法获取时返回空字符串
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// toStatusError 将领域错误转换为 gRPC 状态错误，非领域错误统一视为内部错误
+func toStatusError(err error) error {
+	var domainErr *domainErrors.DomainError
+	if !errors.As(err, &domainErr) {
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	return status.Error(domainErrorCode(domainErr), domainErr.Message)
+}
+
+func domainErrorCode(err *domainErrors.DomainError) codes.Code {
+	switch err {
+	case domainErrors.ErrUserNotFound,
+		domainErrors.ErrUserNotExist:
+		return codes.NotFound
+	case domainErrors.ErrUsernameTaken,
+		domainErrors.ErrEmailTaken,
+		domainErrors.ErrPhoneTaken,
+		domainErrors.ErrUserAlreadyExist:
+		return codes.AlreadyExists
+	case domainErrors.ErrUserDisabled,
+		domainErrors.ErrUserNotActive,
+		domainErrors.ErrUserDeleted:
+		return codes.PermissionDenied
+	case domainErrors.ErrLoginLocked,
+		domainErrors.ErrMfaLocked:
+		return codes.ResourceExhausted
+	default:
+		// 其余领域错误均为输入校验错误
+		return codes.InvalidArgument
+	}
+}
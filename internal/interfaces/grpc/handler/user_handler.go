@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lyonnee/go-template/internal/application/commands"
+	"github.com/lyonnee/go-template/internal/application/queries"
+	"github.com/lyonnee/go-template/internal/domain/entity"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/infrastructure/auth"
+	"github.com/lyonnee/go-template/internal/interfaces/grpc/pb"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 保证对接口实现
+var _ pb.UserServiceServer = (*UserHandler)(nil)
+
+// UserHandler gRPC 用户服务，复用 HTTP 控制器使用的应用服务
+type UserHandler struct {
+	pb.UnimplementedUserServiceServer
+
+	userCmdService   *commands.UserCommandService
+	authCmdService   *commands.AuthCommandService
+	userQueryService *queries.UserQueryService
+	logger           *log.Logger
+
+	permissionQueryService *queries.PermissionQueryService
+}
+
+func init() {
+	di.AddSingleton[*UserHandler](NewUserHandler)
+}
+
+func NewUserHandler() (*UserHandler, error) {
+	return &UserHandler{
+		userCmdService:   di.Get[*commands.UserCommandService](),
+		authCmdService:   di.Get[*commands.AuthCommandService](),
+		userQueryService: di.Get[*queries.UserQueryService](),
+		logger:           di.Get[*log.Logger](),
+
+		permissionQueryService: di.Get[*queries.PermissionQueryService](),
+	}, nil
+}
+
+// SignUp 用户注册
+func (h *UserHandler) SignUp(ctx context.Context, req *pb.SignUpRequest) (*pb.SignUpResponse, error) {
+	h.logger.Debug("SignUp request received", zap.String("username", req.GetUsername()), zap.String("email", req.GetEmail()))
+
+	result, err := h.userCmdService.SignUp(ctx, &commands.SignUpCmd{
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+		Email:    req.GetEmail(),
+		Phone:    req.GetPhone(),
+	})
+	if err != nil {
+		h.logger.Error("SignUp failed", zap.Error(err), zap.String("username", req.GetUsername()))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.SignUpResponse{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		User:         toUserInfo(result.User),
+	}, nil
+}
+
+// Login 用户登录
+func (h *UserHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	h.logger.Debug("Login request received", zap.String("username", req.GetUsername()))
+
+	result, err := h.authCmdService.Login(ctx, &commands.LoginCmd{
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+		ClientIP: clientIP(ctx),
+	})
+	if err != nil {
+		h.logger.Error("Login failed", zap.Error(err), zap.String("username", req.GetUsername()))
+		if errors.Is(err, domainErrors.ErrLoginLocked) {
+			return nil, toStatusError(err)
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid username or password")
+	}
+
+	return &pb.LoginResponse{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		MfaRequired:  result.MfaRequired,
+		MfaToken:     result.MfaToken,
+	}, nil
+}
+
+// VerifyMfa 两步登录第二步
+func (h *UserHandler) VerifyMfa(ctx context.Context, req *pb.VerifyMfaRequest) (*pb.LoginResponse, error) {
+	result, err := h.authCmdService.VerifyMfa(ctx, &commands.VerifyMfaCmd{
+		MfaToken: req.GetMfaToken(),
+		Code:     req.GetCode(),
+		ClientIP: clientIP(ctx),
+	})
+	if err != nil {
+		h.logger.Error("VerifyMfa failed", zap.Error(err))
+		switch {
+		case errors.Is(err, domainErrors.ErrInvalidMfaCode):
+			return nil, status.Error(codes.Unauthenticated, "invalid mfa code")
+		case errors.Is(err, domainErrors.ErrInvalidMfaChallenge):
+			return nil, status.Error(codes.Unauthenticated, "mfa challenge expired, login again")
+		}
+		return nil, toStatusError(err)
+	}
+
+	return &pb.LoginResponse{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+	}, nil
+}
+
+// RefreshToken 刷新token
+func (h *UserHandler) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	h.logger.Debug("RefreshToken request received")
+
+	result, err := h.authCmdService.RefreshToken(ctx, &commands.RefreshTokenCmd{
+		RefreshToken: req.GetRefreshToken(),
+	})
+	if err != nil {
+		h.logger.Error("RefreshToken failed", zap.Error(err))
+		if errors.Is(err, domainErrors.ErrRefreshTokenReused) {
+			return nil, status.Error(codes.Unauthenticated, "refresh token reused, session revoked")
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	return &pb.RefreshTokenResponse{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+	}, nil
+}
+
+// GetUser 获取用户信息
+func (h *UserHandler) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	h.logger.Debug("GetUser request received", zap.Uint64("userId", req.GetId()))
+
+	if err := h.checkAccess(ctx, req.GetId(), entity.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+
+	user, err := h.userQueryService.GetUserById(ctx, req.GetId())
+	if err != nil {
+		h.logger.Error("GetUser failed", zap.Error(err), zap.Uint64("userId", req.GetId()))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.GetUserResponse{
+		User: toUserInfo(user),
+	}, nil
+}
+
+// UpdateUsername 修改用户名
+func (h *UserHandler) UpdateUsername(ctx context.Context, req *pb.UpdateUsernameRequest) (*pb.UpdateUsernameResponse, error) {
+	h.logger.Debug("UpdateUsername request received", zap.Uint64("userId", req.GetId()), zap.String("newUsername", req.GetUsername()))
+
+	if err := h.checkAccess(ctx, req.GetId(), entity.PermissionUsersWrite); err != nil {
+		return nil, err
+	}
+
+	user, err := h.userCmdService.UpdateUsername(ctx, &commands.UpdateUsernameCmd{
+		UserID:   req.GetId(),
+		Username: req.GetUsername(),
+	})
+	if err != nil {
+		h.logger.Error("UpdateUsername failed", zap.Error(err), zap.Uint64("userId", req.GetId()), zap.String("newUsername", req.GetUsername()))
+		return nil, toStatusError(err)
+	}
+
+	return &pb.UpdateUsernameResponse{
+		User: toUserInfo(user),
+	}, nil
+}
+
+// checkAccess 检查权限：用户可以访问自己的信息，访问其他用户需要角色拥有 permission
+func (h *UserHandler) checkAccess(ctx context.Context, userID uint64, permission string) error {
+	claims, ok := auth.GetClaims(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "access denied, token not included in the request")
+	}
+
+	if claims.UserId == userID {
+		return nil
+	}
+
+	allowed, err := h.permissionQueryService.HasPermission(ctx, claims.Roles, permission)
+	if err != nil {
+		return status.Error(codes.Internal, "failed to check permission")
+	}
+	if !allowed {
+		h.logger.Warn("Unauthorized access attempt",
+			zap.Uint64("requestedUserId", userID),
+			zap.Uint64("authenticatedUserId", claims.UserId),
+			zap.String("permission", permission))
+		return status.Error(codes.PermissionDenied, "no permission to access this user")
+	}
+
+	return nil
+}
+
+func toUserInfo(user *entity.User) *pb.UserInfo {
+	if user == nil {
+		return nil
+	}
+
+	return &pb.UserInfo{
+		Id:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Phone:    user.Phone,
+	}
+}
@@ -0,0 +1,81 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/auth"
+	"github.com/lyonnee/go-template/pkg/di"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryJWTAuth 检查token，publicMethods 中声明的方法无需认证
+func UnaryJWTAuth(publicMethods ...string) grpc.UnaryServerInterceptor {
+	public := toSet(publicMethods)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := public[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamJWTAuth 检查token，publicMethods 中声明的方法无需认证
+func StreamJWTAuth(publicMethods ...string) grpc.StreamServerInterceptor {
+	public := toSet(publicMethods)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := public[info.FullMethod]; ok {
+			return handler(srv, ss)
+		}
+
+		ctx, err := authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, wrapStream(ss, ctx))
+	}
+}
+
+// authenticate 校验 authorization 元数据中的 Bearer token，并将 claims 保存到上下文
+func authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "Access Denied. Token not included in the request.")
+	}
+
+	//按空格拆分
+	parts := strings.SplitN(values[0], " ", 2)
+	if !(len(parts) == 2 && parts[0] == "Bearer") {
+		return nil, status.Error(codes.Unauthenticated, "The format of the auth in the request header is incorrect.")
+	}
+
+	sessionManager := di.Get[*auth.SessionManager]()
+	//解析token包含的信息，并确认会话未被撤销
+	claims, err := sessionManager.Authenticate(ctx, parts[1])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "Invalid JSON Web Token")
+	}
+
+	return auth.SetClaims(ctx, claims), nil
+}
+
+func toSet(items []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		set[item] = struct{}{}
+	}
+	return set
+}
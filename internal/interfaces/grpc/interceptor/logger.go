@@ -0,0 +1,66 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func UnaryLogger(logger *log.Logger) grpc.UnaryServerInterceptor {
+	logger = logger.WithOptions(zap.WithCaller(false)) // Skip the logger call in the stack trace
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now() // 请求的时间
+
+		resp, err := handler(ctx, req) // 执行后续拦截器
+
+		logRequest(logger, ctx, "unary", info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+func StreamLogger(logger *log.Logger) grpc.StreamServerInterceptor {
+	logger = logger.WithOptions(zap.WithCaller(false))
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, ss)
+
+		logRequest(logger, ss.Context(), "stream", info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+// logRequest 输出与 HTTP 访问日志相同字段的 gRPC 访问日志
+func logRequest(logger *log.Logger, ctx context.Context, method, path string, err error, cost time.Duration) {
+	var ip, userAgent, errMsg string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		userAgent = strings.Join(md.Get("user-agent"), " ")
+	}
+	if err != nil {
+		errMsg = err.Error()
+	}
+
+	logger.Info("grpc request",
+		zap.Int("status", int(status.Code(err))), // 状态码
+		zap.String("method", method),             // 调用类型
+		zap.String("path", path),                 // 请求的方法全名
+		zap.String("query", ""),                  // 请求的参数
+		zap.String("ip", ip),                     // 请求的IP
+		zap.String("user-agent", userAgent),      // 请求头
+		zap.String("errors", errMsg),             // 错误信息
+		zap.String("cost", cost.String()),        // 请求时间
+		zap.String("trace_id", GetTraceID(ctx)),  // 请求id
+	)
+}
@@ -0,0 +1,55 @@
+package interceptor
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var once sync.Once
+var recoveryLogger *zap.SugaredLogger
+
+func getRecoveryLogger() *zap.SugaredLogger {
+	if recoveryLogger == nil {
+		once.Do(func() {
+			recoveryLogger = di.Get[*log.Logger]().Sugar()
+		})
+	}
+	return recoveryLogger
+}
+
+func UnaryRecovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovery(p)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func StreamRecovery() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovery(p)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func recovery(err any) error {
+	getRecoveryLogger().Errorf("[Recovery] err=%v\nstack=%s", err, debug.Stack())
+	return status.Error(codes.Internal, "Internal Server Error")
+}
@@ -0,0 +1,25 @@
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// wrappedStream 替换 ServerStream 的上下文，使流式拦截器可以向后传递值
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+func wrapStream(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	if w, ok := ss.(*wrappedStream); ok {
+		w.ctx = ctx
+		return w
+	}
+	return &wrappedStream{ServerStream: ss, ctx: ctx}
+}
@@ -0,0 +1,50 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/lyonnee/go-template/pkg/idgen"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	TraceIDKey      = "trace_id"
+	traceIDMetadata = "x-trace-id"
+)
+
+func UnaryTrace() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withTrace(ctx), req)
+	}
+}
+
+func StreamTrace() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, wrapStream(ss, withTrace(ss.Context())))
+	}
+}
+
+// GetTraceID 从上下文获取 Trace ID
+func GetTraceID(ctx context.Context) string {
+	traceID, _ := ctx.Value(TraceIDKey).(string)
+	return traceID
+}
+
+func withTrace(ctx context.Context) context.Context {
+	// 从请求元数据获取或生成 Trace ID
+	var traceID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(traceIDMetadata); len(values) > 0 {
+			traceID = values[0]
+		}
+	}
+	if traceID == "" {
+		traceID = idgen.GenerateStringId()
+	}
+
+	// 设置响应头
+	_ = grpc.SetHeader(ctx, metadata.Pairs(traceIDMetadata, traceID))
+
+	return context.WithValue(ctx, TraceIDKey, traceID)
+}
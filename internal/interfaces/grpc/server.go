@@ -0,0 +1,55 @@
+//go:generate protoc --go_out=pb --go_opt=paths=source_relative --go-grpc_out=pb --go-grpc_opt=paths=source_relative user.proto
+
+package grpc
+
+import (
+	"github.com/lyonnee/go-template/internal/interfaces/grpc/handler"
+	"github.com/lyonnee/go-template/internal/interfaces/grpc/interceptor"
+	"github.com/lyonnee/go-template/internal/interfaces/grpc/pb"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	gogrpc "google.golang.org/grpc"
+)
+
+// publicMethods 无需认证即可调用的方法
+var publicMethods = []string{
+	pb.UserService_SignUp_FullMethodName,
+	pb.UserService_Login_FullMethodName,
+	pb.UserService_VerifyMfa_FullMethodName,
+	pb.UserService_RefreshToken_FullMethodName,
+}
+
+// NewServer 创建挂载了拦截器和全部服务的 gRPC Server
+func NewServer(opts ...gogrpc.ServerOption) *gogrpc.Server {
+	logger := di.Get[*log.Logger]()
+
+	// register interceptor
+	opts = append(opts,
+		gogrpc.ChainUnaryInterceptor(
+			interceptor.UnaryTrace(),
+			interceptor.UnaryLogger(logger),
+			interceptor.UnaryRecovery(),
+			interceptor.UnaryJWTAuth(publicMethods...),
+		),
+		gogrpc.ChainStreamInterceptor(
+			interceptor.StreamTrace(),
+			interceptor.StreamLogger(logger),
+			interceptor.StreamRecovery(),
+			interceptor.StreamJWTAuth(publicMethods...),
+		),
+	)
+
+	s := gogrpc.NewServer(opts...)
+	RegisterServices(s)
+
+	return s
+}
+
+func RegisterServices(s *gogrpc.Server) {
+	// 用户相关
+	{
+		userHandler := di.Get[*handler.UserHandler]()
+
+		pb.RegisterUserServiceServer(s, userHandler)
+	}
+}
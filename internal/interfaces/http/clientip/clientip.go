@@ -0,0 +1,58 @@
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// remoteIPHeaders 反向代理写入客户端地址的请求头，按顺序查找
+var remoteIPHeaders = []string{"X-Forwarded-For", "X-Real-IP"}
+
+// New 创建获取客户端IP的函数，只有直连地址属于 trustedProxies 时才读取 X-Forwarded-For / X-Real-IP，
+// 否则使用直连地址，避免客户端伪造请求头绕过按IP的限流和登录锁定
+// trustedProxies 为 CIDR 或单个IP，为空时不信任任何代理
+func New(trustedProxies []string) (app.ClientIP, error) {
+	cidrs, err := ParseCIDRs(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.ClientIPWithOption(app.ClientIPOptions{
+		RemoteIPHeaders: remoteIPHeaders,
+		TrustedCIDRs:    cidrs,
+	}), nil
+}
+
+// ParseCIDRs 解析 CIDR 列表，单个IP视为只包含该地址的网段
+func ParseCIDRs(values []string) ([]*net.IPNet, error) {
+	cidrs := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", value)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			cidrs = append(cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, cidr, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
+}
@@ -0,0 +1,108 @@
+package clientip
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/mock"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+// remoteConn 固定直连地址的测试连接
+type remoteConn struct {
+	*mock.Conn
+	addr net.Addr
+}
+
+func (c *remoteConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted []string
+		peer    string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "no trusted proxy ignores headers",
+			peer:    "203.0.113.7",
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Real-IP": "1.2.3.4"},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "untrusted peer ignores headers",
+			trusted: []string{"10.0.0.0/8"},
+			peer:    "203.0.113.7",
+			headers: map[string]string{"X-Forwarded-For": "1.2.3.4"},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "trusted cidr uses forwarded for",
+			trusted: []string{"10.0.0.0/8"},
+			peer:    "10.1.2.3",
+			headers: map[string]string{"X-Forwarded-For": "198.51.100.9, 10.0.0.2"},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "trusted single ip uses real ip",
+			trusted: []string{"10.1.2.3"},
+			peer:    "10.1.2.3",
+			headers: map[string]string{"X-Real-IP": "198.51.100.9"},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "trusted peer without headers",
+			trusted: []string{"10.0.0.0/8"},
+			peer:    "10.1.2.3",
+			want:    "10.1.2.3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, err := New(tt.trusted)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			var headers []ut.Header
+			for k, v := range tt.headers {
+				headers = append(headers, ut.Header{Key: k, Value: v})
+			}
+			reqCtx := ut.CreateUtRequestContext("GET", "/", nil, headers...)
+			reqCtx.SetConn(&remoteConn{
+				Conn: mock.NewConn(""),
+				addr: &net.TCPAddr{IP: net.ParseIP(tt.peer), Port: 40000},
+			})
+
+			if got := fn(reqCtx); got != tt.want {
+				t.Fatalf("client ip = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCIDRs(t *testing.T) {
+	cidrs, err := ParseCIDRs([]string{"10.0.0.0/8", " 192.168.1.1 ", "", "::1"})
+	if err != nil {
+		t.Fatalf("ParseCIDRs: %v", err)
+	}
+	if len(cidrs) != 3 {
+		t.Fatalf("len = %d, want 3", len(cidrs))
+	}
+	if got := cidrs[1].String(); got != "192.168.1.1/32" {
+		t.Fatalf("single ipv4 = %s, want 192.168.1.1/32", got)
+	}
+	if got := cidrs[2].String(); got != "::1/128" {
+		t.Fatalf("single ipv6 = %s, want ::1/128", got)
+	}
+
+	for _, bad := range []string{"not-an-ip", "10.0.0.0/33"} {
+		if _, err := ParseCIDRs([]string{bad}); err == nil {
+			t.Fatalf("ParseCIDRs(%q) expected error", bad)
+		}
+	}
+}
@@ -2,9 +2,15 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/lyonnee/go-template/internal/application/commands"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/infrastructure/auth"
 	"github.com/lyonnee/go-template/internal/interfaces/http/dto"
 	"github.com/lyonnee/go-template/pkg/di"
 	"github.com/lyonnee/go-template/pkg/log"
@@ -46,13 +52,25 @@ func (c *AuthController) Login(ctx context.Context, reqCtx *app.RequestContext)
 	cmd := &commands.LoginCmd{
 		Username: req.Username,
 		Password: req.Password,
+		ClientIP: reqCtx.ClientIP(),
 	}
 
 	// 执行登录
 	result, err := c.authCmdService.Login(ctx, cmd)
 	if err != nil {
 		c.logger.Error("Login failed", zap.Error(err), zap.String("username", req.Username))
-		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "用户名或密码错误")
+		if !failLoginLocked(reqCtx, err) {
+			dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "用户名或密码错误")
+		}
+		return
+	}
+
+	if result.MfaRequired {
+		c.logger.Info("Login requires mfa verification", zap.String("username", req.Username))
+		dto.Ok(reqCtx, "请输入验证码", dto.LoginResp{
+			MfaRequired: true,
+			MfaToken:    result.MfaToken,
+		})
 		return
 	}
 
@@ -67,6 +85,38 @@ func (c *AuthController) Login(ctx context.Context, reqCtx *app.RequestContext)
 	dto.Ok(reqCtx, "登录成功", resp)
 }
 
+// VerifyMfa 两步登录第二步，提交 TOTP 验证码或恢复码
+func (c *AuthController) VerifyMfa(ctx context.Context, reqCtx *app.RequestContext) {
+	var req dto.VerifyMfaReq
+	if err := reqCtx.Bind(&req); err != nil {
+		c.logger.Error("VerifyMfa bind params failed", zap.Error(err))
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "参数格式错误")
+		return
+	}
+
+	cmd := &commands.VerifyMfaCmd{
+		MfaToken: req.MfaToken,
+		Code:     req.Code,
+		ClientIP: reqCtx.ClientIP(),
+	}
+
+	result, err := c.authCmdService.VerifyMfa(ctx, cmd)
+	if err != nil {
+		c.logger.Error("VerifyMfa failed", zap.Error(err))
+		if !failLoginLocked(reqCtx, err) {
+			failMfa(reqCtx, err, "验证失败")
+		}
+		return
+	}
+
+	resp := dto.LoginResp{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+	}
+
+	dto.Ok(reqCtx, "登录成功", resp)
+}
+
 // RefreshToken 刷新token
 func (c *AuthController) RefreshToken(ctx context.Context, reqCtx *app.RequestContext) {
 	c.logger.Debug("RefreshToken request received")
@@ -91,7 +141,11 @@ func (c *AuthController) RefreshToken(ctx context.Context, reqCtx *app.RequestCo
 	result, err := c.authCmdService.RefreshToken(ctx, cmd)
 	if err != nil {
 		c.logger.Error("RefreshToken failed", zap.Error(err))
-		dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "刷新token无效")
+		if errors.Is(err, domainErrors.ErrRefreshTokenReused) {
+			dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "刷新token已被使用，请重新登录")
+		} else {
+			dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "刷新token无效")
+		}
 		return
 	}
 
@@ -99,8 +153,306 @@ func (c *AuthController) RefreshToken(ctx context.Context, reqCtx *app.RequestCo
 
 	// 构造响应
 	resp := dto.RefreshTokenResp{
-		AccessToken: result.AccessToken,
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
 	}
 
 	dto.Ok(reqCtx, "刷新成功", resp)
 }
+
+// Logout 退出登录，撤销当前会话
+func (c *AuthController) Logout(ctx context.Context, reqCtx *app.RequestContext) {
+	c.logger.Debug("Logout request received")
+
+	// 获取当前登录用户信息
+	claims, exists := reqCtx.Get("claims")
+	if !exists {
+		c.logger.Error("Logout - no claims found in context")
+		dto.Fail(reqCtx, dto.CODE_NOT_TOKEN, "未获取到用户信息")
+		return
+	}
+
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		c.logger.Error("Logout - invalid claims type in context")
+		dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "用户信息格式错误")
+		return
+	}
+
+	cmd := &commands.LogoutCmd{
+		UserID:    userClaims.UserId,
+		SessionID: userClaims.SessionID,
+	}
+
+	if err := c.authCmdService.Logout(ctx, cmd); err != nil {
+		c.logger.Error("Logout failed", zap.Error(err), zap.Uint64("userId", userClaims.UserId))
+		dto.Fail(reqCtx, dto.CODE_SERVER_ERROR, "退出登录失败")
+		return
+	}
+
+	c.logger.Info("User logged out successfully", zap.Uint64("userId", userClaims.UserId))
+
+	dto.Ok(reqCtx, "退出成功", "")
+}
+
+// UnlockLogin 管理员解除用户名或IP的登录锁定
+func (c *AuthController) UnlockLogin(ctx context.Context, reqCtx *app.RequestContext) {
+	claims, exists := reqCtx.Get("claims")
+	if !exists {
+		dto.Fail(reqCtx, dto.CODE_NOT_TOKEN, "未获取到用户信息")
+		return
+	}
+
+	userClaims, ok := claims.(*auth.Claims)
+	if !ok {
+		dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "用户信息格式错误")
+		return
+	}
+
+	var req dto.UnlockLoginReq
+	if err := reqCtx.Bind(&req); err != nil || (req.Username == "" && req.IP == "") {
+		c.logger.Error("UnlockLogin bind params failed", zap.Error(err))
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "参数格式错误")
+		return
+	}
+
+	cmd := &commands.UnlockLoginCmd{
+		OperatorID: userClaims.UserId,
+		Username:   req.Username,
+		IP:         req.IP,
+	}
+
+	if err := c.authCmdService.UnlockLogin(ctx, cmd); err != nil {
+		c.logger.Error("UnlockLogin failed", zap.Error(err), zap.Uint64("operatorId", userClaims.UserId))
+		dto.Fail(reqCtx, dto.CODE_SERVER_ERROR, "解除锁定失败")
+		return
+	}
+
+	dto.Ok(reqCtx, "解除锁定成功", "")
+}
+
+// SiweNonce 获取钱包登录 nonce
+func (c *AuthController) SiweNonce(ctx context.Context, reqCtx *app.RequestContext) {
+	c.logger.Debug("SiweNonce request received")
+
+	nonce, err := c.authCmdService.SiweNonce(ctx)
+	if err != nil {
+		c.logger.Error("SiweNonce failed", zap.Error(err))
+		dto.Fail(reqCtx, dto.CODE_SERVER_ERROR, "获取nonce失败")
+		return
+	}
+
+	resp := dto.SiweNonceResp{
+		Nonce: nonce,
+	}
+
+	dto.Ok(reqCtx, "获取成功", resp)
+}
+
+// SiweLogin 钱包登录（Sign-In with Ethereum）
+func (c *AuthController) SiweLogin(ctx context.Context, reqCtx *app.RequestContext) {
+	c.logger.Debug("SiweLogin request received")
+
+	var req dto.SiweLoginReq
+
+	// 绑定参数
+	if err := reqCtx.Bind(&req); err != nil {
+		c.logger.Error("SiweLogin bind params failed", zap.Error(err))
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "参数格式错误")
+		return
+	}
+
+	// 创建命令
+	cmd := &commands.SiweLoginCmd{
+		Message:   req.Message,
+		Signature: req.Signature,
+	}
+
+	// 执行登录
+	result, err := c.authCmdService.SiweLogin(ctx, cmd)
+	if err != nil {
+		c.logger.Error("SiweLogin failed", zap.Error(err))
+		failSiwe(reqCtx, err, "钱包登录失败")
+		return
+	}
+
+	if result.MfaRequired {
+		c.logger.Info("Wallet login requires mfa verification", zap.Uint64("userId", result.User.ID))
+		dto.Ok(reqCtx, "请输入验证码", dto.SiweLoginResp{
+			MfaRequired: true,
+			MfaToken:    result.MfaToken,
+		})
+		return
+	}
+
+	c.logger.Info("User logged in with wallet successfully", zap.Uint64("userId", result.User.ID))
+
+	// 构造响应
+	resp := dto.SiweLoginResp{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		User: &dto.UserInfo{
+			ID:            result.User.ID,
+			Username:      result.User.Username,
+			Email:         result.User.Email,
+			Phone:         result.User.Phone,
+			WalletAddress: result.User.WalletAddress,
+		},
+	}
+
+	dto.Ok(reqCtx, "登录成功", resp)
+}
+
+// OAuthLogin 跳转到第三方身份提供方授权页面
+func (c *AuthController) OAuthLogin(ctx context.Context, reqCtx *app.RequestContext) {
+	provider := reqCtx.Param("provider")
+
+	c.logger.Debug("OAuthLogin request received", zap.String("provider", provider))
+
+	authURL, err := c.authCmdService.OAuthLoginURL(ctx, provider)
+	if err != nil {
+		c.logger.Error("OAuthLogin failed", zap.Error(err), zap.String("provider", provider))
+		if errors.Is(err, domainErrors.ErrOAuthProviderNotFound) {
+			dto.Fail(reqCtx, dto.CODE_INVALID_PATH_ARGUMENT, "不支持的登录方式")
+		} else {
+			dto.Fail(reqCtx, dto.CODE_SERVER_ERROR, "第三方登录失败")
+		}
+		return
+	}
+
+	reqCtx.Redirect(http.StatusFound, []byte(authURL))
+}
+
+// OAuthCallback 第三方身份提供方授权回调
+func (c *AuthController) OAuthCallback(ctx context.Context, reqCtx *app.RequestContext) {
+	provider := reqCtx.Param("provider")
+
+	c.logger.Debug("OAuthCallback request received", zap.String("provider", provider))
+
+	var req dto.OAuthCallbackReq
+
+	// 绑定参数
+	if err := reqCtx.BindQuery(&req); err != nil {
+		c.logger.Error("OAuthCallback bind params failed", zap.Error(err))
+		dto.Fail(reqCtx, dto.CODE_INVALID_QUERY_ARGUMENT, "参数格式错误")
+		return
+	}
+
+	if req.Error != "" {
+		c.logger.Warn("OAuthCallback authorization denied",
+			zap.String("provider", provider),
+			zap.String("error", req.Error),
+			zap.String("errorDescription", req.ErrorDescription))
+		dto.Fail(reqCtx, dto.CODE_INVALID_QUERY_ARGUMENT, "第三方授权失败")
+		return
+	}
+
+	// 创建命令
+	cmd := &commands.OAuthCallbackCmd{
+		Provider: provider,
+		Code:     req.Code,
+		State:    req.State,
+	}
+
+	// 执行登录
+	result, err := c.authCmdService.OAuthCallback(ctx, cmd)
+	if err != nil {
+		c.logger.Error("OAuthCallback failed", zap.Error(err), zap.String("provider", provider))
+
+		switch {
+		case errors.Is(err, domainErrors.ErrOAuthProviderNotFound):
+			dto.Fail(reqCtx, dto.CODE_INVALID_PATH_ARGUMENT, "不支持的登录方式")
+		case errors.Is(err, domainErrors.ErrInvalidOAuthState):
+			dto.Fail(reqCtx, dto.CODE_INVALID_QUERY_ARGUMENT, "登录请求无效或已过期")
+		case errors.Is(err, domainErrors.ErrInvalidIDToken), errors.Is(err, domainErrors.ErrOAuthExchangeFailed):
+			dto.Fail(reqCtx, dto.CODE_INVALID_QUERY_ARGUMENT, "第三方身份校验失败")
+		case errors.Is(err, domainErrors.ErrUserDeleted):
+			dto.Fail(reqCtx, dto.CODE_INVALID_QUERY_ARGUMENT, "用户已删除")
+		default:
+			dto.Fail(reqCtx, dto.CODE_SERVER_ERROR, "第三方登录失败")
+		}
+		return
+	}
+
+	if result.MfaRequired {
+		c.logger.Info("OAuth login requires mfa verification", zap.String("provider", provider), zap.Uint64("userId", result.User.ID))
+		dto.Ok(reqCtx, "请输入验证码", dto.OAuthLoginResp{
+			MfaRequired: true,
+			MfaToken:    result.MfaToken,
+		})
+		return
+	}
+
+	c.logger.Info("User logged in with oauth successfully", zap.String("provider", provider), zap.Uint64("userId", result.User.ID))
+
+	// 构造响应
+	resp := dto.OAuthLoginResp{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		User: &dto.UserInfo{
+			ID:            result.User.ID,
+			Username:      result.User.Username,
+			Email:         result.User.Email,
+			Phone:         result.User.Phone,
+			WalletAddress: result.User.WalletAddress,
+		},
+	}
+
+	dto.Ok(reqCtx, "登录成功", resp)
+}
+
+// failSiwe 将 Sign-In with Ethereum 校验错误转换为响应
+func failSiwe(reqCtx *app.RequestContext, err error, defaultMsg string) {
+	switch {
+	case errors.Is(err, domainErrors.ErrInvalidSiweMessage):
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "签名消息格式错误")
+	case errors.Is(err, domainErrors.ErrInvalidSignature):
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "签名无效")
+	case errors.Is(err, domainErrors.ErrInvalidNonce):
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "nonce无效或已过期")
+	case errors.Is(err, domainErrors.ErrSiweMessageExpired):
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "签名消息已过期")
+	case errors.Is(err, domainErrors.ErrUserDeleted):
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "用户已删除")
+	default:
+		dto.Fail(reqCtx, dto.CODE_SERVER_ERROR, defaultMsg)
+	}
+}
+
+// failLoginLocked 登录被锁定时写入响应并返回 true，Retry-After 为剩余锁定秒数
+func failLoginLocked(reqCtx *app.RequestContext, err error) bool {
+	if !errors.Is(err, domainErrors.ErrLoginLocked) {
+		return false
+	}
+
+	var lockedErr *auth.LockedError
+	if errors.As(err, &lockedErr) {
+		reqCtx.Response.Header.Set("Retry-After", strconv.Itoa(int(math.Ceil(lockedErr.RetryAfter.Seconds()))))
+	}
+
+	dto.Fail(reqCtx, dto.CODE_LOGIN_LOCKED, "登录失败次数过多，请稍后再试")
+	return true
+}
+
+func failMfa(reqCtx *app.RequestContext, err error, defaultMsg string) {
+	switch {
+	case errors.Is(err, domainErrors.ErrInvalidMfaCode):
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "验证码错误")
+	case errors.Is(err, domainErrors.ErrInvalidMfaChallenge):
+		dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "登录已过期，请重新登录")
+	case errors.Is(err, domainErrors.ErrMfaLocked):
+		dto.Fail(reqCtx, dto.CODE_LOGIN_LOCKED, "验证码错误次数过多，请稍后再试")
+	case errors.Is(err, domainErrors.ErrMfaAlreadyEnabled):
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "已启用多因素认证")
+	case errors.Is(err, domainErrors.ErrMfaNotEnrolled):
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "请先生成认证器密钥")
+	case errors.Is(err, domainErrors.ErrMfaNotEnabled):
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "未启用多因素认证")
+	case errors.Is(err, domainErrors.ErrUserNotFound):
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "用户不存在")
+	case errors.Is(err, domainErrors.ErrUserDeleted):
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "用户已删除")
+	default:
+		dto.Fail(reqCtx, dto.CODE_SERVER_ERROR, defaultMsg)
+	}
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/lyonnee/go-template/internal/application/queries"
+	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
+	"github.com/lyonnee/go-template/internal/interfaces/http/dto"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+type EthController struct {
+	ethQueryService *queries.EthQueryService
+	logger          *log.Logger
+}
+
+func init() {
+	di.AddSingleton[*EthController](NewEthController)
+}
+
+func NewEthController() (*EthController, error) {
+	return &EthController{
+		ethQueryService: di.Get[*queries.EthQueryService](),
+		logger:          di.Get[*log.Logger](),
+	}, nil
+}
+
+// GetBalance 查询地址余额
+func (c *EthController) GetBalance(ctx context.Context, reqCtx *app.RequestContext) {
+	address := reqCtx.Param("address")
+
+	var req dto.GetBalanceReq
+	if err := reqCtx.BindQuery(&req); err != nil {
+		c.logger.Error("GetBalance bind params failed", zap.Error(err))
+		dto.Fail(reqCtx, dto.CODE_INVALID_QUERY_ARGUMENT, "参数格式错误")
+		return
+	}
+
+	c.logger.Debug("GetBalance request received", zap.String("address", address), zap.String("token", req.Token))
+
+	result, err := c.ethQueryService.GetBalance(ctx, address, req.Token)
+	if err != nil {
+		c.logger.Error("GetBalance failed", zap.Error(err), zap.String("address", address), zap.String("token", req.Token))
+		if errors.Is(err, domainErrors.ErrInvalidAddress) {
+			dto.Fail(reqCtx, dto.CODE_INVALID_PATH_ARGUMENT, "地址格式错误")
+		} else {
+			dto.Fail(reqCtx, dto.CODE_SERVER_ERROR, "查询余额失败")
+		}
+		return
+	}
+
+	resp := dto.GetBalanceResp{
+		Address:     result.Address,
+		Token:       result.Token,
+		Balance:     result.Balance,
+		BlockNumber: result.BlockNumber,
+	}
+
+	dto.Ok(reqCtx, "查询成功", resp)
+}
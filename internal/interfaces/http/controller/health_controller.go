@@ -2,10 +2,14 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/lyonnee/go-template/internal/infrastructure/blockchain"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/infrastructure/database"
 	"github.com/lyonnee/go-template/internal/interfaces/http/dto"
 	"github.com/lyonnee/go-template/pkg/di"
 	"github.com/lyonnee/go-template/pkg/log"
@@ -17,13 +21,21 @@ func init() {
 
 // HealthController 健康检查控制器
 type HealthController struct {
-	logger *log.Logger
+	logger    *log.Logger
+	dbContext *database.Database
+
+	blockchainEnabled bool
+	blockchainClient  *blockchain.Client
 }
 
 // NewHealthController 创建健康检查控制器
 func NewHealthController() (*HealthController, error) {
 	return &HealthController{
-		logger: di.Get[*log.Logger](),
+		logger:    di.Get[*log.Logger](),
+		dbContext: di.Get[*database.Database](),
+
+		blockchainEnabled: di.Get[config.Config]().Blockchain.Enable,
+		blockchainClient:  di.Get[*blockchain.Client](),
 	}, nil
 }
 
@@ -51,12 +63,32 @@ func (c *HealthController) HealthCheck(ctx context.Context, reqCtx *app.RequestC
 }
 
 // ReadinessCheck 就绪检查
+// 启用 blockchain 时报告节点链ID的校验结果：链ID不一致时返回 503；节点暂不可用时仍然就绪，只影响链上相关接口
+// tx_retry 为进程启动以来的事务重试统计，exhausted 持续增长说明并发冲突严重，需要排查热点数据
 func (c *HealthController) ReadinessCheck(ctx context.Context, reqCtx *app.RequestContext) {
 	// 这里可以检查依赖服务的可用性
 	// 例如数据库连接、缓存连接等
+	status, code := "ready", http.StatusOK
+	checks := map[string]string{
+		"blockchain": "disabled",
+	}
 
-	reqCtx.JSON(http.StatusOK, map[string]interface{}{
-		"status": "ready",
+	if c.blockchainEnabled {
+		switch err := c.blockchainClient.ChainIDStatus(); {
+		case err == nil:
+			checks["blockchain"] = "ok"
+		case errors.Is(err, blockchain.ErrChainIDMismatch):
+			status, code = "not_ready", http.StatusServiceUnavailable
+			checks["blockchain"] = err.Error()
+		default:
+			checks["blockchain"] = "unavailable: " + err.Error()
+		}
+	}
+
+	reqCtx.JSON(code, map[string]interface{}{
+		"status":   status,
+		"checks":   checks,
+		"tx_retry": c.dbContext.RetryStats(),
 	})
 }
 
@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/lyonnee/go-template/internal/infrastructure/auth"
+	"github.com/lyonnee/go-template/pkg/di"
+)
+
+func init() {
+	di.AddSingleton[*JWKSController](NewJWKSController)
+}
+
+// JWKSController 公开 JWT 验证公钥，供其他服务离线校验本服务签发的令牌
+type JWKSController struct {
+	keyring *auth.Keyring
+}
+
+// NewJWKSController 创建 JWKS 控制器
+func NewJWKSController() (*JWKSController, error) {
+	return &JWKSController{
+		keyring: di.Get[*auth.Keyring](),
+	}, nil
+}
+
+// JWKS 返回 RFC 7517 格式的公钥集合，不使用统一响应结构
+func (c *JWKSController) JWKS(ctx context.Context, reqCtx *app.RequestContext) {
+	reqCtx.Header("Cache-Control", "public, max-age=300")
+	reqCtx.JSON(http.StatusOK, c.keyring.JWKS())
+}
@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/lyonnee/go-template/internal/application/commands"
+	"github.com/lyonnee/go-template/internal/infrastructure/auth"
+	"github.com/lyonnee/go-template/internal/interfaces/http/dto"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// MfaController 当前登录用户的多因素认证管理
+type MfaController struct {
+	mfaCmdService *commands.MfaCommandService
+	logger        *log.Logger
+}
+
+func init() {
+	di.AddSingleton[*MfaController](NewMfaController)
+}
+
+func NewMfaController() (*MfaController, error) {
+	return &MfaController{
+		mfaCmdService: di.Get[*commands.MfaCommandService](),
+		logger:        di.Get[*log.Logger](),
+	}, nil
+}
+
+// EnrollTOTP 生成待确认的 TOTP 密钥
+func (c *MfaController) EnrollTOTP(ctx context.Context, reqCtx *app.RequestContext) {
+	claims, ok := c.claims(reqCtx)
+	if !ok {
+		return
+	}
+
+	result, err := c.mfaCmdService.EnrollTOTP(ctx, claims.UserId)
+	if err != nil {
+		c.logger.Error("EnrollTOTP failed", zap.Error(err), zap.Uint64("userId", claims.UserId))
+		failMfa(reqCtx, err, "生成认证器密钥失败")
+		return
+	}
+
+	resp := dto.EnrollTOTPResp{
+		Secret:     result.Secret,
+		OtpauthURI: result.OtpauthURI,
+	}
+
+	dto.Ok(reqCtx, "请使用认证器应用扫码并提交验证码确认", resp)
+}
+
+// ConfirmTOTP 提交验证码确认登记，启用多因素认证
+func (c *MfaController) ConfirmTOTP(ctx context.Context, reqCtx *app.RequestContext) {
+	cmd, ok := c.bindCodeCmd(reqCtx)
+	if !ok {
+		return
+	}
+
+	codes, err := c.mfaCmdService.ConfirmTOTP(ctx, cmd)
+	if err != nil {
+		c.logger.Error("ConfirmTOTP failed", zap.Error(err), zap.Uint64("userId", cmd.UserID))
+		failMfa(reqCtx, err, "启用多因素认证失败")
+		return
+	}
+
+	dto.Ok(reqCtx, "已启用多因素认证，请妥善保存恢复码", dto.RecoveryCodesResp{RecoveryCodes: codes})
+}
+
+// Disable 关闭多因素认证
+func (c *MfaController) Disable(ctx context.Context, reqCtx *app.RequestContext) {
+	cmd, ok := c.bindCodeCmd(reqCtx)
+	if !ok {
+		return
+	}
+
+	if err := c.mfaCmdService.DisableMfa(ctx, cmd); err != nil {
+		c.logger.Error("DisableMfa failed", zap.Error(err), zap.Uint64("userId", cmd.UserID))
+		failMfa(reqCtx, err, "关闭多因素认证失败")
+		return
+	}
+
+	dto.Ok(reqCtx, "已关闭多因素认证", "")
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (c *MfaController) RegenerateRecoveryCodes(ctx context.Context, reqCtx *app.RequestContext) {
+	cmd, ok := c.bindCodeCmd(reqCtx)
+	if !ok {
+		return
+	}
+
+	codes, err := c.mfaCmdService.RegenerateRecoveryCodes(ctx, cmd)
+	if err != nil {
+		c.logger.Error("RegenerateRecoveryCodes failed", zap.Error(err), zap.Uint64("userId", cmd.UserID))
+		failMfa(reqCtx, err, "生成恢复码失败")
+		return
+	}
+
+	dto.Ok(reqCtx, "已重新生成恢复码，原有恢复码已失效", dto.RecoveryCodesResp{RecoveryCodes: codes})
+}
+
+// claims 获取当前登录用户信息，失败时写入响应
+func (c *MfaController) claims(reqCtx *app.RequestContext) (*auth.Claims, bool) {
+	value, exists := reqCtx.Get("claims")
+	if !exists {
+		dto.Fail(reqCtx, dto.CODE_NOT_TOKEN, "未获取到用户信息")
+		return nil, false
+	}
+
+	claims, ok := value.(*auth.Claims)
+	if !ok {
+		dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "用户信息格式错误")
+		return nil, false
+	}
+	return claims, true
+}
+
+// bindCodeCmd 绑定验证码参数并构造命令，失败时写入响应
+func (c *MfaController) bindCodeCmd(reqCtx *app.RequestContext) (*commands.MfaCodeCmd, bool) {
+	claims, ok := c.claims(reqCtx)
+	if !ok {
+		return nil, false
+	}
+
+	var req dto.MfaCodeReq
+	if err := reqCtx.Bind(&req); err != nil {
+		c.logger.Error("Mfa bind params failed", zap.Error(err), zap.Uint64("userId", claims.UserId))
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "参数格式错误")
+		return nil, false
+	}
+
+	return &commands.MfaCodeCmd{
+		UserID: claims.UserId,
+		Code:   req.Code,
+	}, true
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/lyonnee/go-template/internal/application/commands"
 	"github.com/lyonnee/go-template/internal/application/queries"
 	domainErrors "github.com/lyonnee/go-template/internal/domain/errors"
-	"github.com/lyonnee/go-template/internal/infrastructure/auth"
 	"github.com/lyonnee/go-template/internal/interfaces/http/dto"
 	"github.com/lyonnee/go-template/pkg/di"
 	"github.com/lyonnee/go-template/pkg/log"
@@ -106,30 +105,6 @@ func (c *UserController) GetUser(ctx context.Context, reqCtx *app.RequestContext
 
 	c.logger.Debug("GetUser request received", zap.Uint64("userId", userID))
 
-	// 获取当前登录用户信息
-	claims, exists := reqCtx.Get("claims")
-	if !exists {
-		c.logger.Error("GetUser - no claims found in context")
-		dto.Fail(reqCtx, dto.CODE_NOT_TOKEN, "未获取到用户信息")
-		return
-	}
-
-	userClaims, ok := claims.(*auth.Claims)
-	if !ok {
-		c.logger.Error("GetUser - invalid claims type in context")
-		dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "用户信息格式错误")
-		return
-	}
-
-	// 检查权限：只能查看自己的信息
-	if userClaims.UserId != userID {
-		c.logger.Warn("GetUser unauthorized access attempt",
-			zap.Uint64("requestedUserId", userID),
-			zap.Uint64("authenticatedUserId", userClaims.UserId))
-		dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "无权查看该用户信息")
-		return
-	}
-
 	// 获取用户信息
 	user, err := c.userQueryService.GetUserById(ctx, userID)
 	if err != nil {
@@ -147,10 +122,11 @@ func (c *UserController) GetUser(ctx context.Context, reqCtx *app.RequestContext
 	// 构造响应
 	resp := dto.GetUserResp{
 		User: &dto.UserInfo{
-			ID:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			Phone:    user.Phone,
+			ID:            user.ID,
+			Username:      user.Username,
+			Email:         user.Email,
+			Phone:         user.Phone,
+			WalletAddress: user.WalletAddress,
 		},
 	}
 
@@ -170,30 +146,6 @@ func (c *UserController) UpdateUsername(ctx context.Context, reqCtx *app.Request
 
 	c.logger.Debug("UpdateUsername request received", zap.Uint64("userId", userID))
 
-	// 获取当前登录用户信息
-	claims, exists := reqCtx.Get("claims")
-	if !exists {
-		c.logger.Error("UpdateUsername - no claims found in context")
-		dto.Fail(reqCtx, dto.CODE_NOT_TOKEN, "未获取到用户信息")
-		return
-	}
-
-	userClaims, ok := claims.(*auth.Claims)
-	if !ok {
-		c.logger.Error("UpdateUsername - invalid claims type in context")
-		dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "用户信息格式错误")
-		return
-	}
-
-	// 检查权限：只能修改自己的信息
-	if userClaims.UserId != userID {
-		c.logger.Warn("UpdateUsername unauthorized access attempt",
-			zap.Uint64("requestedUserId", userID),
-			zap.Uint64("authenticatedUserId", userClaims.UserId))
-		dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "无权修改该用户信息")
-		return
-	}
-
 	// 绑定参数
 	var req dto.UpdateUsernameReq
 	if err := reqCtx.Bind(&req); err != nil {
@@ -242,3 +194,65 @@ func (c *UserController) UpdateUsername(ctx context.Context, reqCtx *app.Request
 
 	dto.Ok(reqCtx, "修改成功", resp)
 }
+
+// BindWallet 绑定钱包地址
+func (c *UserController) BindWallet(ctx context.Context, reqCtx *app.RequestContext) {
+	// 从路径参数获取用户ID
+	userIDStr := reqCtx.Param("id")
+	userID, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		c.logger.Error("BindWallet invalid user ID format", zap.String("userIdStr", userIDStr), zap.Error(err))
+		dto.Fail(reqCtx, dto.CODE_INVALID_PATH_ARGUMENT, "用户ID格式错误")
+		return
+	}
+
+	c.logger.Debug("BindWallet request received", zap.Uint64("userId", userID))
+
+	// 绑定参数
+	var req dto.BindWalletReq
+	if err := reqCtx.Bind(&req); err != nil {
+		c.logger.Error("BindWallet bind params failed", zap.Error(err), zap.Uint64("userId", userID))
+		dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "参数格式错误")
+		return
+	}
+
+	// 创建命令
+	cmd := &commands.BindWalletCmd{
+		UserID:    userID,
+		Message:   req.Message,
+		Signature: req.Signature,
+	}
+
+	// 执行绑定
+	user, err := c.userCmdService.BindWallet(ctx, cmd)
+	if err != nil {
+		c.logger.Error("BindWallet failed", zap.Error(err), zap.Uint64("userId", userID))
+
+		switch {
+		case errors.Is(err, domainErrors.ErrUserNotFound):
+			dto.Fail(reqCtx, dto.CODE_INVALID_PATH_ARGUMENT, "用户不存在")
+		case errors.Is(err, domainErrors.ErrWalletAddressTaken):
+			dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "钱包地址已被其他用户绑定")
+		case errors.Is(err, domainErrors.ErrWalletAlreadyBound):
+			dto.Fail(reqCtx, dto.CODE_INVALID_BODY_ARGUMENT, "用户已绑定其他钱包地址")
+		default:
+			failSiwe(reqCtx, err, "绑定钱包失败")
+		}
+		return
+	}
+
+	c.logger.Info("Wallet bound successfully", zap.Uint64("userId", userID), zap.String("walletAddress", user.WalletAddress))
+
+	// 构造响应
+	resp := dto.BindWalletResp{
+		User: &dto.UserInfo{
+			ID:            user.ID,
+			Username:      user.Username,
+			Email:         user.Email,
+			Phone:         user.Phone,
+			WalletAddress: user.WalletAddress,
+		},
+	}
+
+	dto.Ok(reqCtx, "绑定成功", resp)
+}
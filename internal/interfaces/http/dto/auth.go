@@ -6,10 +6,24 @@ type LoginReq struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// LoginResp 登录响应
+// LoginResp 登录响应，mfa_required 为 true 时不返回令牌，需使用 mfa_token 提交验证码
 type LoginResp struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	MfaRequired  bool   `json:"mfa_required,omitempty"`
+	MfaToken     string `json:"mfa_token,omitempty"`
+}
+
+// UnlockLoginReq 解除登录锁定请求，username 和 ip 至少提供一个
+type UnlockLoginReq struct {
+	Username string `json:"username"`
+	IP       string `json:"ip"`
+}
+
+// VerifyMfaReq 两步登录第二步请求
+type VerifyMfaReq struct {
+	MfaToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"` // TOTP 验证码或恢复码
 }
 
 // RefreshTokenReq 刷新token请求
@@ -17,9 +31,47 @@ type RefreshTokenReq struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
-// RefreshTokenResp 刷新token响应
+// RefreshTokenResp 刷新token响应，旧的刷新令牌随即失效，客户端需保存新的刷新令牌
 type RefreshTokenResp struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// SiweNonceResp 钱包登录 nonce 响应
+type SiweNonceResp struct {
+	Nonce string `json:"nonce"`
+}
+
+// SiweLoginReq 钱包登录请求
+type SiweLoginReq struct {
+	Message   string `json:"message" binding:"required"`   // EIP-4361 消息原文
+	Signature string `json:"signature" binding:"required"` // personal_sign 签名，0x 前缀的 65 字节十六进制
+}
+
+// SiweLoginResp 钱包登录响应，mfa_required 为 true 时不返回令牌和用户信息，需使用 mfa_token 提交验证码
+type SiweLoginResp struct {
+	AccessToken  string    `json:"access_token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	MfaRequired  bool      `json:"mfa_required,omitempty"`
+	MfaToken     string    `json:"mfa_token,omitempty"`
+	User         *UserInfo `json:"user,omitempty"`
+}
+
+// OAuthCallbackReq 第三方登录回调请求
+type OAuthCallbackReq struct {
+	Code             string `query:"code"`
+	State            string `query:"state"`
+	Error            string `query:"error"`             // 用户拒绝授权等情况下身份提供方返回的错误
+	ErrorDescription string `query:"error_description"` // 错误描述
+}
+
+// OAuthLoginResp 第三方登录响应，mfa_required 为 true 时不返回令牌和用户信息，需使用 mfa_token 提交验证码
+type OAuthLoginResp struct {
+	AccessToken  string    `json:"access_token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	MfaRequired  bool      `json:"mfa_required,omitempty"`
+	MfaToken     string    `json:"mfa_token,omitempty"`
+	User         *UserInfo `json:"user,omitempty"`
 }
 
 // SignUpReq 注册请求
@@ -30,7 +82,7 @@ type SignUpReq struct {
 	Phone    string `json:"phone" binding:"required"`
 }
 
-// SignUpResp 注册响应
+// SignUpResp 注册响应，通过 Idempotency-Key 重放的响应不包含令牌
 type SignUpResp struct {
 	AccessToken  string    `json:"access_token"`
 	RefreshToken string    `json:"refresh_token"`
@@ -39,10 +91,11 @@ type SignUpResp struct {
 
 // UserInfo 用户信息
 type UserInfo struct {
-	ID       uint64 `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
+	ID            uint64 `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	Phone         string `json:"phone"`
+	WalletAddress string `json:"wallet_address,omitempty"`
 }
 
 // UpdateUsernameReq 修改用户名请求
@@ -13,11 +13,17 @@ const (
 	CODE_NOT_TOKEN              = 10001
 	CODE_TOKEN_FORMAT_INCORRECT = 10002
 	CODE_TOKEN_INVALID          = 10003
+	CODE_PERMISSION_DENIED      = 10004
+	CODE_LOGIN_LOCKED           = 10005
+	CODE_TOO_MANY_REQUESTS      = 10006
 
 	// 参数错误 (20000-29999)
-	CODE_INVALID_QUERY_ARGUMENT = 20001
-	CODE_INVALID_PATH_ARGUMENT  = 20002
-	CODE_INVALID_BODY_ARGUMENT  = 20003
+	CODE_INVALID_QUERY_ARGUMENT  = 20001
+	CODE_INVALID_PATH_ARGUMENT   = 20002
+	CODE_INVALID_BODY_ARGUMENT   = 20003
+	CODE_IDEMPOTENCY_KEY_INVALID = 20004
+	CODE_IDEMPOTENCY_KEY_REUSED  = 20005
+	CODE_REQUEST_IN_PROGRESS     = 20006
 
 	// 内部错误 (30000-39999)
 	CODE_SERVER_ERROR = 30001
@@ -0,0 +1,14 @@
+package dto
+
+// GetBalanceReq 余额查询请求
+type GetBalanceReq struct {
+	Token string `query:"token"` // ERC-20 合约地址，为空时查询原生代币
+}
+
+// GetBalanceResp 余额查询响应
+type GetBalanceResp struct {
+	Address     string `json:"address"`
+	Token       string `json:"token,omitempty"`
+	Balance     string `json:"balance"` // 最小单位（wei）的十进制字符串
+	BlockNumber uint64 `json:"block_number"`
+}
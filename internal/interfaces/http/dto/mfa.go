@@ -0,0 +1,17 @@
+package dto
+
+// EnrollTOTPResp TOTP 登记响应，密钥只返回这一次
+type EnrollTOTPResp struct {
+	Secret     string `json:"secret"`      // base32 编码的密钥，供无法扫码时手动输入
+	OtpauthURI string `json:"otpauth_uri"` // 认证器应用扫码使用的 URI
+}
+
+// MfaCodeReq 提交验证码的请求
+type MfaCodeReq struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResp 恢复码响应，恢复码只返回这一次，每个只能使用一次
+type RecoveryCodesResp struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
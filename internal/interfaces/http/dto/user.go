@@ -9,3 +9,14 @@ type GetUserResp struct {
 type UpdateUsernameResp struct {
 	User *UserInfo `json:"user"`
 }
+
+// BindWalletReq 绑定钱包请求
+type BindWalletReq struct {
+	Message   string `json:"message" binding:"required"`   // EIP-4361 消息原文
+	Signature string `json:"signature" binding:"required"` // personal_sign 签名，0x 前缀的 65 字节十六进制
+}
+
+// BindWalletResp 绑定钱包响应
+type BindWalletResp struct {
+	User *UserInfo `json:"user"`
+}
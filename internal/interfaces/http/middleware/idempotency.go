@@ -0,0 +1,281 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/lyonnee/go-template/internal/infrastructure/auth"
+	"github.com/lyonnee/go-template/internal/infrastructure/cache"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/interfaces/http/dto"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+const (
+	idempotencyHeader       = "Idempotency-Key"
+	idempotencyMaxKeyLength = 255
+
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultIdempotencyLockTTL = 30 * time.Second
+
+	minFingerprintKeyLength = 32
+)
+
+// idempotencyRedactedFields 保存响应前从 data 中移除的字段
+// 令牌不以明文保存，重放时也不会返回可能已被轮换的刷新令牌，客户端需重新登录获取令牌
+var idempotencyRedactedFields = []string{"access_token", "refresh_token", "mfa_token"}
+
+// idempotencyRecord 保存的请求指纹和响应
+type idempotencyRecord struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	ContentType string      `json:"content_type"`
+	Headers     [][2]string `json:"headers,omitempty"`
+	Body        []byte      `json:"body"`
+}
+
+// Idempotency 中间件，支持通过 Idempotency-Key 请求头安全地重试写操作
+// 首次请求的响应按请求方、接口和 Idempotency-Key 保存，重试时直接返回保存的响应；
+// 相同的 Idempotency-Key 携带不同的请求体时拒绝请求，首次请求仍在处理中时拒绝并发的重复请求。
+// 在 JWTAuth 之后使用时按用户隔离，否则按 Authorization 请求头隔离。
+// 未携带 Idempotency-Key 的请求和 GET 等只读请求直接放行；缓存不可用时放行请求。
+// 保存的响应不包含令牌，见 idempotencyRedactedFields。
+func Idempotency() app.HandlerFunc {
+	conf := di.Get[config.Config]().Http.Idempotency
+	// 请求体可能包含密码，指纹使用服务端密钥的 HMAC，多副本需使用相同的密钥
+	if len(conf.FingerprintKey) < minFingerprintKeyLength {
+		panic("http.idempotency.fingerprint_key must be at least 32 bytes")
+	}
+	fingerprintKey := []byte(conf.FingerprintKey)
+	ttl := conf.TTL
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	lockTTL := conf.LockTTL
+	if lockTTL <= 0 {
+		lockTTL = defaultIdempotencyLockTTL
+	}
+
+	cacheCtx := di.Get[cache.CacheContext]()
+	locker := di.Get[cache.Locker]()
+	logger := di.Get[*log.Logger]()
+
+	return func(ctx context.Context, reqCtx *app.RequestContext) {
+		key := string(reqCtx.Request.Header.Peek(idempotencyHeader))
+		if key == "" || !isMutatingMethod(string(reqCtx.Method())) {
+			reqCtx.Next(ctx)
+			return
+		}
+		if len(key) > idempotencyMaxKeyLength {
+			reqCtx.JSON(http.StatusBadRequest, dto.NewResponse(dto.CODE_IDEMPOTENCY_KEY_INVALID, "Idempotency-Key 长度不能超过 255", ""))
+			reqCtx.Abort()
+			return
+		}
+
+		scope := idempotencyScope(reqCtx, fingerprintKey, key)
+		cacheKey := cache.IdempotencyKey.Key(scope)
+		fingerprint := requestFingerprint(reqCtx, fingerprintKey)
+
+		replayed, err := replayIdempotentResponse(ctx, reqCtx, cacheCtx, cacheKey, fingerprint)
+		if err != nil {
+			logger.Warn("Idempotency store unavailable", zap.Error(err))
+			reqCtx.Next(ctx)
+			return
+		}
+		if replayed {
+			return
+		}
+
+		lock, err := locker.Acquire(ctx, "idempotency:"+scope, lockTTL)
+		if errors.Is(err, cache.ErrLockNotAcquired) {
+			reqCtx.Response.Header.Set("Retry-After", "1")
+			reqCtx.JSON(http.StatusConflict, dto.NewResponse(dto.CODE_REQUEST_IN_PROGRESS, "相同 Idempotency-Key 的请求正在处理中", ""))
+			reqCtx.Abort()
+			return
+		}
+		if err != nil {
+			logger.Warn("Idempotency lock unavailable", zap.Error(err))
+			reqCtx.Next(ctx)
+			return
+		}
+		defer func() {
+			// 客户端断开时仍需释放锁，否则重试要等到锁过期
+			if err := locker.Release(context.WithoutCancel(ctx), lock); err != nil {
+				logger.Warn("Failed to release idempotency lock", zap.String("key", key), zap.Error(err))
+			}
+		}()
+
+		// 获取锁之前首次请求可能刚好完成，再检查一次
+		replayed, err = replayIdempotentResponse(ctx, reqCtx, cacheCtx, cacheKey, fingerprint)
+		if err != nil {
+			logger.Warn("Idempotency store unavailable", zap.Error(err))
+		}
+		if replayed {
+			return
+		}
+
+		reqCtx.Next(ctx)
+
+		if !shouldStoreResponse(reqCtx) {
+			return
+		}
+		record := &idempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      reqCtx.Response.StatusCode(),
+			ContentType: string(reqCtx.Response.Header.ContentType()),
+			Body:        redactResponseBody(reqCtx.Response.Body()),
+		}
+		reqCtx.Response.Header.VisitAll(func(k, v []byte) {
+			if storableHeader(string(k)) {
+				record.Headers = append(record.Headers, [2]string{string(k), string(v)})
+			}
+		})
+
+		data, err := json.Marshal(record)
+		if err == nil {
+			err = cacheCtx.SetWithTTL(ctx, cacheKey, string(data), ttl)
+		}
+		if err != nil {
+			logger.Warn("Failed to store idempotent response", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// replayIdempotentResponse 存在保存的响应时写入响应并返回 true
+// 指纹不一致时拒绝请求，同样返回 true
+func replayIdempotentResponse(ctx context.Context, reqCtx *app.RequestContext, cacheCtx cache.CacheContext, cacheKey, fingerprint string) (bool, error) {
+	data, err := cacheCtx.Get(ctx, cacheKey)
+	if errors.Is(err, cache.ErrCacheMiss) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return false, err
+	}
+
+	if record.Fingerprint != fingerprint {
+		reqCtx.JSON(http.StatusUnprocessableEntity, dto.NewResponse(dto.CODE_IDEMPOTENCY_KEY_REUSED, "Idempotency-Key 已被用于不同的请求", ""))
+		reqCtx.Abort()
+		return true, nil
+	}
+
+	for _, header := range record.Headers {
+		reqCtx.Response.Header.Set(header[0], header[1])
+	}
+	reqCtx.Response.Header.Set("Idempotent-Replayed", "true")
+	reqCtx.Data(record.Status, record.ContentType, record.Body)
+	reqCtx.Abort()
+	return true, nil
+}
+
+// idempotencyScope 返回 Idempotency-Key 的作用范围，不同请求方、不同接口的相同 Idempotency-Key 互不影响
+func idempotencyScope(reqCtx *app.RequestContext, fingerprintKey []byte, key string) string {
+	subject := "anonymous"
+	if value, exists := reqCtx.Get("claims"); exists {
+		if claims, ok := value.(*auth.Claims); ok {
+			subject = "user:" + strconv.FormatUint(claims.UserId, 10)
+		}
+	} else if authHeader := reqCtx.Request.Header.Peek("Authorization"); len(authHeader) > 0 {
+		mac := hmac.New(sha256.New, fingerprintKey)
+		mac.Write(authHeader)
+		subject = "auth:" + hex.EncodeToString(mac.Sum(nil)[:8])
+	}
+
+	return subject + ":" + string(reqCtx.Method()) + " " + string(reqCtx.Path()) + ":" + key
+}
+
+// requestFingerprint 请求方法、路径、查询参数和请求体的 HMAC
+func requestFingerprint(reqCtx *app.RequestContext, fingerprintKey []byte) string {
+	h := hmac.New(sha256.New, fingerprintKey)
+	h.Write(reqCtx.Method())
+	h.Write([]byte{0})
+	h.Write(reqCtx.Path())
+	h.Write([]byte{0})
+	h.Write(reqCtx.URI().QueryString())
+	h.Write([]byte{0})
+	h.Write(reqCtx.Request.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// redactResponseBody 移除响应 data 中的令牌字段，非 JSON 或 data 不是对象时原样返回
+func redactResponseBody(body []byte) []byte {
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return append([]byte(nil), body...)
+	}
+
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal(resp["data"], &data); err != nil {
+		return append([]byte(nil), body...)
+	}
+
+	redacted := false
+	for _, field := range idempotencyRedactedFields {
+		if _, ok := data[field]; ok {
+			delete(data, field)
+			redacted = true
+		}
+	}
+	if !redacted {
+		return append([]byte(nil), body...)
+	}
+
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return append([]byte(nil), body...)
+	}
+	resp["data"] = rawData
+
+	redactedBody, err := json.Marshal(resp)
+	if err != nil {
+		return append([]byte(nil), body...)
+	}
+	return redactedBody
+}
+
+// shouldStoreResponse 只保存确定的结果，服务端错误允许客户端重试
+func shouldStoreResponse(reqCtx *app.RequestContext) bool {
+	if reqCtx.Response.StatusCode() >= http.StatusInternalServerError {
+		return false
+	}
+
+	var resp struct {
+		Code uint16 `json:"code"`
+	}
+	if err := json.Unmarshal(reqCtx.Response.Body(), &resp); err == nil && resp.Code == dto.CODE_SERVER_ERROR {
+		return false
+	}
+	return true
+}
+
+// storableHeader 与本次请求相关的响应头不保存
+func storableHeader(name string) bool {
+	switch strings.ToLower(name) {
+	case "content-type", "content-length", "date", "server", "retry-after", "x-trace-id":
+		return false
+	}
+	return !strings.HasPrefix(strings.ToLower(name), "ratelimit-")
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/lyonnee/go-template/internal/interfaces/http/dto"
+)
+
+func TestRedactResponseBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "tokens removed",
+			body: `{"code":200,"msg":"注册成功","data":{"access_token":"a","refresh_token":"r","user":{"id":1}}}`,
+			want: `{"code":200,"data":{"user":{"id":1}},"msg":"注册成功"}`,
+		},
+		{
+			name: "mfa token removed",
+			body: `{"code":200,"data":{"mfa_required":true,"mfa_token":"m"}}`,
+			want: `{"code":200,"data":{"mfa_required":true}}`,
+		},
+		{
+			name: "no tokens unchanged",
+			body: `{"code":200, "data":{"id":1}}`,
+			want: `{"code":200, "data":{"id":1}}`,
+		},
+		{
+			name: "data not object unchanged",
+			body: `{"code":20003,"msg":"参数格式错误","data":""}`,
+			want: `{"code":20003,"msg":"参数格式错误","data":""}`,
+		},
+		{
+			name: "not json unchanged",
+			body: `plain text`,
+			want: `plain text`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(redactResponseBody([]byte(tt.body))); got != tt.want {
+				t.Fatalf("redactResponseBody = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestFingerprintUsesKey(t *testing.T) {
+	newReqCtx := func(body string) *app.RequestContext {
+		return ut.CreateUtRequestContext(http.MethodPost, "/api/users?x=1", &ut.Body{Body: strings.NewReader(body), Len: len(body)})
+	}
+	keyA := []byte(strings.Repeat("a", minFingerprintKeyLength))
+	keyB := []byte(strings.Repeat("b", minFingerprintKeyLength))
+
+	fp := requestFingerprint(newReqCtx(`{"password":"secret"}`), keyA)
+	if fp != requestFingerprint(newReqCtx(`{"password":"secret"}`), keyA) {
+		t.Fatal("fingerprint is not deterministic")
+	}
+	if fp == requestFingerprint(newReqCtx(`{"password":"secret"}`), keyB) {
+		t.Fatal("fingerprint does not depend on the key")
+	}
+	if fp == requestFingerprint(newReqCtx(`{"password":"other"}`), keyA) {
+		t.Fatal("fingerprint does not depend on the body")
+	}
+}
+
+func TestIdempotencyReplaysWithoutTokens(t *testing.T) {
+	var calls atomic.Int32
+	h := server.New()
+	h.POST("/api/users", Idempotency(), func(ctx context.Context, reqCtx *app.RequestContext) {
+		calls.Add(1)
+		dto.Ok(reqCtx, "注册成功", dto.SignUpResp{
+			AccessToken:  "access",
+			RefreshToken: "refresh",
+			User:         &dto.UserInfo{ID: 1, Username: "alice"},
+		})
+	})
+
+	key := ut.Header{Key: idempotencyHeader, Value: t.Name()}
+	contentType := ut.Header{Key: "Content-Type", Value: "application/json"}
+	perform := func(body string) *ut.ResponseRecorder {
+		return ut.PerformRequest(h.Engine, http.MethodPost, "/api/users",
+			&ut.Body{Body: strings.NewReader(body), Len: len(body)}, key, contentType)
+	}
+
+	first := perform(`{"username":"alice","password":"secret"}`)
+	var firstResp dto.Response[dto.SignUpResp]
+	if err := json.Unmarshal(first.Body.Bytes(), &firstResp); err != nil {
+		t.Fatalf("decode first response: %v", err)
+	}
+	if firstResp.Data.AccessToken != "access" || firstResp.Data.RefreshToken != "refresh" {
+		t.Fatalf("first response tokens = %+v, want issued tokens", firstResp.Data)
+	}
+
+	replay := perform(`{"username":"alice","password":"secret"}`)
+	if got := string(replay.Header().Peek("Idempotent-Replayed")); got != "true" {
+		t.Fatalf("Idempotent-Replayed = %q, want true", got)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler calls = %d, want 1", calls.Load())
+	}
+	var replayResp dto.Response[dto.SignUpResp]
+	if err := json.Unmarshal(replay.Body.Bytes(), &replayResp); err != nil {
+		t.Fatalf("decode replay: %v", err)
+	}
+	if replayResp.Data.AccessToken != "" || replayResp.Data.RefreshToken != "" {
+		t.Fatalf("replay contains tokens: %s", replay.Body.Bytes())
+	}
+	if replayResp.Data.User == nil || replayResp.Data.User.ID != 1 {
+		t.Fatalf("replay user = %+v, want id 1", replayResp.Data.User)
+	}
+
+	mismatch := perform(`{"username":"alice","password":"other"}`)
+	if mismatch.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("mismatched body status = %d, want 422", mismatch.Code)
+	}
+}
@@ -28,9 +28,9 @@ func JWTAuth() app.HandlerFunc {
 			return
 		}
 
-		jwtGenerator := di.Get[*auth.JWTGenerator]()
-		//解析token包含的信息
-		claims, err := jwtGenerator.ValidateToken(parts[1])
+		sessionManager := di.Get[*auth.SessionManager]()
+		//解析token包含的信息，并确认会话未被撤销
+		claims, err := sessionManager.Authenticate(ctx, parts[1])
 		if err != nil {
 			dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "Invalid JSON Web Token")
 			reqCtx.Abort()
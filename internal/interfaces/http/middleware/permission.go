@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/lyonnee/go-template/internal/application/queries"
+	"github.com/lyonnee/go-template/internal/infrastructure/auth"
+	"github.com/lyonnee/go-template/internal/interfaces/http/dto"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// AccessRule 权限检查前的放行规则，返回 true 时无需拥有权限即可访问
+type AccessRule func(reqCtx *app.RequestContext, claims *auth.Claims) bool
+
+// OwnResource 路径参数 param 为当前登录用户ID时放行，即用户总是可以访问自己的资源
+func OwnResource(param string) AccessRule {
+	return func(reqCtx *app.RequestContext, claims *auth.Claims) bool {
+		id, err := strconv.ParseUint(reqCtx.Param(param), 10, 64)
+		return err == nil && id == claims.UserId
+	}
+}
+
+// RequirePermission 中间件，要求当前用户的角色拥有 permission，或满足任一放行规则
+// 需要在 JWTAuth 之后使用
+func RequirePermission(permission string, rules ...AccessRule) app.HandlerFunc {
+	return func(ctx context.Context, reqCtx *app.RequestContext) {
+		claims, ok := requireClaims(reqCtx)
+		if !ok {
+			return
+		}
+
+		for _, rule := range rules {
+			if rule(reqCtx, claims) {
+				reqCtx.Next(ctx)
+				return
+			}
+		}
+
+		allowed, err := di.Get[*queries.PermissionQueryService]().HasPermission(ctx, claims.Roles, permission)
+		if err != nil {
+			dto.Fail(reqCtx, dto.CODE_SERVER_ERROR, "权限校验失败")
+			reqCtx.Abort()
+			return
+		}
+		if !allowed {
+			di.Get[*log.Logger]().Warn("Permission denied",
+				zap.Uint64("userId", claims.UserId),
+				zap.Strings("roles", claims.Roles),
+				zap.String("permission", permission),
+				zap.String("path", string(reqCtx.Path())))
+			dto.Fail(reqCtx, dto.CODE_PERMISSION_DENIED, "无权访问该资源")
+			reqCtx.Abort()
+			return
+		}
+
+		reqCtx.Next(ctx)
+	}
+}
+
+// RequireOwner 中间件，只允许路径参数 param 为当前登录用户ID的请求，任何角色和权限都不能代替本人操作
+// 用于绑定钱包等关联登录凭证的操作，避免拥有写权限的角色将自己的凭证绑定到他人账号上接管账号
+// 需要在 JWTAuth 之后使用
+func RequireOwner(param string) app.HandlerFunc {
+	isOwner := OwnResource(param)
+
+	return func(ctx context.Context, reqCtx *app.RequestContext) {
+		claims, ok := requireClaims(reqCtx)
+		if !ok {
+			return
+		}
+
+		if !isOwner(reqCtx, claims) {
+			di.Get[*log.Logger]().Warn("Non-owner access denied",
+				zap.Uint64("userId", claims.UserId),
+				zap.String("resourceId", reqCtx.Param(param)),
+				zap.String("path", string(reqCtx.Path())))
+			dto.Fail(reqCtx, dto.CODE_PERMISSION_DENIED, "只能操作自己的账号")
+			reqCtx.Abort()
+			return
+		}
+
+		reqCtx.Next(ctx)
+	}
+}
+
+// requireClaims 返回 JWTAuth 写入的当前用户信息，不存在时响应错误并中止请求
+func requireClaims(reqCtx *app.RequestContext) (*auth.Claims, bool) {
+	value, exists := reqCtx.Get("claims")
+	if !exists {
+		dto.Fail(reqCtx, dto.CODE_NOT_TOKEN, "未获取到用户信息")
+		reqCtx.Abort()
+		return nil, false
+	}
+	claims, ok := value.(*auth.Claims)
+	if !ok {
+		dto.Fail(reqCtx, dto.CODE_TOKEN_INVALID, "用户信息格式错误")
+		reqCtx.Abort()
+		return nil, false
+	}
+	return claims, true
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/lyonnee/go-template/internal/infrastructure/auth"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/infrastructure/ratelimit"
+	"github.com/lyonnee/go-template/internal/interfaces/http/dto"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// RateLimit 中间件，按配置中 group 对应的规则限制请求频率
+// 未启用限流或 group 未配置规则时直接放行；按用户限流需要在 JWTAuth 之后使用，未登录的请求按IP限流
+// 限流后端不可用时放行请求，避免限流故障导致整个服务不可用
+func RateLimit(group string) app.HandlerFunc {
+	conf := di.Get[config.Config]().Http.RateLimit
+	rule, ok := conf.Groups[group]
+	if !conf.Enable || !ok || rule.Limit <= 0 {
+		return func(ctx context.Context, reqCtx *app.RequestContext) {
+			reqCtx.Next(ctx)
+		}
+	}
+
+	limiter := di.Get[ratelimit.Limiter]()
+	logger := di.Get[*log.Logger]()
+
+	return func(ctx context.Context, reqCtx *app.RequestContext) {
+		result, err := limiter.Allow(ctx, group+":"+rateLimitSubject(reqCtx, rule.Key), rule)
+		if err != nil {
+			logger.Warn("Rate limiter unavailable",
+				zap.String("group", group),
+				zap.Error(err))
+			reqCtx.Next(ctx)
+			return
+		}
+
+		reqCtx.Response.Header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		reqCtx.Response.Header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		reqCtx.Response.Header.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+
+		if !result.Allowed {
+			reqCtx.Response.Header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+			reqCtx.JSON(http.StatusTooManyRequests, dto.NewResponse(dto.CODE_TOO_MANY_REQUESTS, "请求过于频繁，请稍后再试", ""))
+			reqCtx.Abort()
+			return
+		}
+
+		reqCtx.Next(ctx)
+	}
+}
+
+// rateLimitSubject 返回限流对象的标识
+func rateLimitSubject(reqCtx *app.RequestContext, key string) string {
+	switch key {
+	case "user":
+		if value, exists := reqCtx.Get("claims"); exists {
+			if claims, ok := value.(*auth.Claims); ok {
+				return "user:" + strconv.FormatUint(claims.UserId, 10)
+			}
+		}
+	case "route":
+		return "route:" + string(reqCtx.Method()) + " " + reqCtx.FullPath()
+	}
+
+	// ClientIP 只信任 http.trusted_proxies 中代理写入的转发头，见 services/http.go
+	return "ip:" + reqCtx.ClientIP()
+}
+
+// ceilSeconds 向上取整为秒，最少为 1 秒
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Max(1, math.Ceil(d.Seconds()))))
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"github.com/cloudwego/hertz/pkg/app/middlewares/server/recovery"
 	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/lyonnee/go-template/internal/domain/entity"
 	"github.com/lyonnee/go-template/internal/interfaces/http/controller"
 	"github.com/lyonnee/go-template/internal/interfaces/http/middleware"
 	"github.com/lyonnee/go-template/pkg/di"
@@ -18,8 +19,16 @@ func RegisterRoutes(hz *server.Hertz) {
 	hz.Use(middleware.CORS())
 	hz.Use(middleware.AddTrace())
 
+	// JWT 验证公钥
+	{
+		jwksController := di.Get[*controller.JWKSController]()
+
+		hz.GET("/.well-known/jwks.json", jwksController.JWKS)
+	}
+
 	// register handler
-	apiRouter := hz.Group("/api")
+	// 限流规则见配置 http.rate_limit.groups
+	apiRouter := hz.Group("/api", middleware.RateLimit("api"))
 
 	// 健康检查
 	{
@@ -34,9 +43,34 @@ func RegisterRoutes(hz *server.Hertz) {
 	{
 		authController := di.Get[*controller.AuthController]()
 
-		authRouter := apiRouter.Group("/auth")
+		authRouter := apiRouter.Group("/auth", middleware.RateLimit("auth"))
 		authRouter.POST("/login", authController.Login)
 		authRouter.POST("/refresh", authController.RefreshToken)
+		authRouter.POST("/siwe/nonce", authController.SiweNonce)
+		authRouter.POST("/siwe/login", authController.SiweLogin)
+		authRouter.GET("/oauth/:provider/login", authController.OAuthLogin)
+		authRouter.GET("/oauth/:provider/callback", authController.OAuthCallback)
+		authRouter.POST("/logout", middleware.JWTAuth(), authController.Logout)
+		authRouter.POST("/mfa/verify", authController.VerifyMfa)
+	}
+
+	// 多因素认证管理 (需要认证)
+	{
+		mfaController := di.Get[*controller.MfaController]()
+
+		mfaRouter := apiRouter.Group("/auth/mfa", middleware.JWTAuth())
+		mfaRouter.POST("/totp/enroll", mfaController.EnrollTOTP)
+		mfaRouter.POST("/totp/confirm", mfaController.ConfirmTOTP)
+		mfaRouter.POST("/disable", mfaController.Disable)
+		mfaRouter.POST("/recovery-codes", mfaController.RegenerateRecoveryCodes)
+	}
+
+	// 管理员操作 (需要认证)
+	{
+		authController := di.Get[*controller.AuthController]()
+
+		adminRouter := apiRouter.Group("/admin", middleware.JWTAuth())
+		adminRouter.POST("/login-locks/unlock", middleware.RequirePermission(entity.PermissionUsersUnlock), authController.UnlockLogin)
 	}
 
 	// 用户相关 (需要认证)
@@ -44,10 +78,21 @@ func RegisterRoutes(hz *server.Hertz) {
 		userController := di.Get[*controller.UserController]()
 
 		userRouter := apiRouter.Group("/users")
-		userRouter.POST("", userController.Register)
+		userRouter.POST("", middleware.Idempotency(), userController.Register)
+
+		userRouter.Use(middleware.JWTAuth(), middleware.RateLimit("users"), middleware.Idempotency())
+		// 普通用户只能访问自己的信息，拥有权限的角色（如管理员）可以访问任意用户
+		userRouter.GET("/:id", middleware.RequirePermission(entity.PermissionUsersRead, middleware.OwnResource("id")), userController.GetUser)
+		userRouter.PUT("/:id/username", middleware.RequirePermission(entity.PermissionUsersWrite, middleware.OwnResource("id")), userController.UpdateUsername)
+		// 绑定钱包后可以用钱包登录该账号，只允许本人操作
+		userRouter.PUT("/:id/wallet", middleware.RequireOwner("id"), userController.BindWallet)
+	}
+
+	// 链上数据
+	{
+		ethController := di.Get[*controller.EthController]()
 
-		userRouter.Use(middleware.JWTAuth())
-		userRouter.GET("/:id", userController.GetUser)
-		userRouter.PUT("/:id/username", userController.UpdateUsername)
+		ethRouter := apiRouter.Group("/eth")
+		ethRouter.GET("/balance/:address", ethController.GetBalance)
 	}
 }
@@ -4,26 +4,58 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/lyonnee/go-template/internal/infrastructure/database"
 	"github.com/lyonnee/go-template/pkg/log"
 	"github.com/lyonnee/go-template/services"
+	"go.uber.org/zap"
 
 	_ "github.com/lyonnee/go-template/internal/infrastructure/repository_impl"
+	_ "github.com/lyonnee/go-template/internal/interfaces/event_handler"
+)
+
+const (
+	startTimeout    = 30 * time.Second
+	shutdownTimeout = 5 * time.Second
 )
 
 func main() {
-	services.StartAll()
+	os.Exit(run())
+}
+
+func run() int {
+	defer log.Sync()
+	defer database.Close()
+
+	startCtx, cancelStart := context.WithTimeout(context.Background(), startTimeout)
+	err := services.StartAll(startCtx)
+	cancelStart()
+	if err != nil {
+		log.Error("Failed to start services", zap.Error(err))
+		return 1
+	}
 
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+
+	exitCode := 0
+	select {
+	case sig := <-quit:
+		log.Info("Received shutdown signal", zap.String("signal", sig.String()))
+	case err := <-services.Errors():
+		log.Error("Service failed, shutting down", zap.Error(err))
+		exitCode = 1
+	}
 
-	_, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
-	services.StopAll()
-	database.Close()
-	log.Sync()
+	if err := services.StopAll(ctx); err != nil {
+		log.Error("Failed to stop services gracefully", zap.Error(err))
+		exitCode = 1
+	}
+
+	return exitCode
 }
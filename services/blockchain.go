@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/blockchain"
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/pkg/di"
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// chainIDRetryInterval 节点不可用时重新校验链ID的间隔
+const chainIDRetryInterval = 30 * time.Second
+
+func init() {
+	conf := di.Get[config.Config]()
+	if !conf.Blockchain.Enable {
+		return
+	}
+
+	s := NewBlockchainService()
+	RegisterService(s)
+}
+
+// BlockchainService 在后台校验 EVM 节点的链ID与 blockchain.ethereum.chain_id 一致
+// 校验结果不影响其他服务启动：节点不可用时定期重试，链ID不一致时记录错误，结果通过 /api/ready 报告
+type BlockchainService struct {
+	client *blockchain.Client
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewBlockchainService() *BlockchainService {
+	return &BlockchainService{
+		client: di.Get[*blockchain.Client](),
+	}
+}
+
+func (s *BlockchainService) Name() string {
+	return "blockchain"
+}
+
+func (s *BlockchainService) Start(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		s.verifyChainID(runCtx)
+	}()
+
+	return nil
+}
+
+// Stop 停止校验
+func (s *BlockchainService) Stop(ctx context.Context) error {
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// verifyChainID 校验链ID，节点不可用时定期重试，直到校验成功或链ID不一致
+func (s *BlockchainService) verifyChainID(ctx context.Context) {
+	for {
+		err := s.client.VerifyChainID(ctx)
+		switch {
+		case err == nil:
+			log.Info("Blockchain chain id verified", zap.Int64("chainId", s.client.ChainID()))
+			return
+		case errors.Is(err, blockchain.ErrChainIDMismatch):
+			log.Error("Blockchain node is on a different network", zap.Error(err))
+			return
+		case ctx.Err() != nil:
+			return
+		}
+
+		log.Warn("Blockchain node unavailable, retrying chain id verification",
+			zap.Error(err),
+			zap.Duration("retryIn", chainIDRetryInterval))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(chainIDRetryInterval):
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"time"
 
 	"github.com/lyonnee/go-template/internal/application/scheduler"
@@ -22,11 +23,22 @@ func NewCronService() *CronService {
 	}
 }
 
-func (s *CronService) Start() {
+func (s *CronService) Name() string {
+	return "cron"
+}
+
+func (s *CronService) Start(ctx context.Context) error {
 	scheduler.RegisterScheduledJobs(s.c)
 	s.c.Start()
+	return nil
 }
 
-func (s *CronService) Stop() {
-	s.c.Stop()
+// Stop 停止调度并等待正在执行的任务结束
+func (s *CronService) Stop(ctx context.Context) error {
+	select {
+	case <-s.c.Stop().Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -1,21 +1,80 @@
 package services
 
+import (
+	"context"
+	"net"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	grpcapi "github.com/lyonnee/go-template/internal/interfaces/grpc"
+	"github.com/lyonnee/go-template/pkg/di"
+	"google.golang.org/grpc"
+)
+
 func init() {
 	s := NewGRPCService()
 	RegisterService(s)
 }
 
 type GRPCService struct {
+	s    *grpc.Server
+	port string
+
+	errCh chan error
 }
 
 func NewGRPCService() *GRPCService {
-	return &GRPCService{}
+	conf := di.Get[config.Config]()
+
+	return &GRPCService{
+		port:  conf.Grpc.Port,
+		errCh: make(chan error, 1),
+	}
+}
+
+func (s *GRPCService) Name() string {
+	return "grpc"
+}
+
+// DependsOn 请求处理中提交事务后会发布领域事件，消息队列需先于当前服务启动、晚于当前服务停止
+func (s *GRPCService) DependsOn() []string {
+	return []string{"mq"}
 }
 
-func (s *GRPCService) Start() {
-	// 启动 gRPC 服务
+// Start 创建 gRPC Server 并启动，监听成功后返回
+func (s *GRPCService) Start(ctx context.Context) error {
+	s.s = grpcapi.NewServer()
+
+	lis, err := (&net.ListenConfig{}).Listen(ctx, "tcp", s.port)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.s.Serve(lis); err != nil {
+			s.errCh <- err
+		}
+	}()
+
+	return nil
+}
+
+// Stop 优雅关闭 gRPC，超时后强制关闭
+func (s *GRPCService) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.s.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.s.Stop()
+		return ctx.Err()
+	}
 }
 
-func (s *GRPCService) Stop() {
-	// 停止 gRPC 服务
+func (s *GRPCService) Errors() <-chan error {
+	return s.errCh
 }
@@ -1,9 +1,14 @@
 package services
 
 import (
+	"context"
+	"sync/atomic"
+	"time"
+
 	"github.com/cloudwego/hertz/pkg/app/server"
 	"github.com/lyonnee/go-template/internal/infrastructure/config"
 	"github.com/lyonnee/go-template/internal/interfaces/http"
+	"github.com/lyonnee/go-template/internal/interfaces/http/clientip"
 	"github.com/lyonnee/go-template/pkg/di"
 )
 
@@ -14,24 +19,78 @@ func init() {
 
 type HTTPService struct {
 	h *server.Hertz
+
+	stopping atomic.Bool
+	errCh    chan error
 }
 
 func NewHTTPService() *HTTPService {
 	conf := di.Get[config.Config]()
 
+	// 限流和登录锁定按客户端IP计数，只信任配置的反向代理写入的转发头
+	clientIP, err := clientip.New(conf.Http.TrustedProxies)
+	if err != nil {
+		panic(err)
+	}
+
 	s := server.New(
 		server.WithHostPorts(conf.Http.Port),
 	)
+	s.SetClientIPFunc(clientIP)
 	return &HTTPService{
-		h: s,
+		h:     s,
+		errCh: make(chan error, 1),
 	}
 }
 
-func (s *HTTPService) Start() {
+func (s *HTTPService) Name() string {
+	return "http"
+}
+
+// DependsOn 请求处理中提交事务后会发布领域事件，消息队列需先于当前服务启动、晚于当前服务停止
+func (s *HTTPService) DependsOn() []string {
+	return []string{"mq"}
+}
+
+// Start 注册路由并启动 Hertz，监听成功后返回
+func (s *HTTPService) Start(ctx context.Context) error {
 	http.RegisterRoutes(s.h)
-	s.h.Spin()
+
+	runErr := make(chan error, 1)
+	go func() {
+		err := s.h.Run()
+		if s.stopping.Load() {
+			return
+		}
+		runErr <- err
+		if err != nil {
+			s.errCh <- err
+		}
+	}()
+
+	// 等待端口监听成功，端口被占用等错误会直接返回
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-runErr:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if s.h.IsRunning() {
+				return nil
+			}
+		}
+	}
+}
+
+// Stop 优雅关闭 Hertz，等待处理中的请求完成
+func (s *HTTPService) Stop(ctx context.Context) error {
+	s.stopping.Store(true)
+	return s.h.Shutdown(ctx)
 }
 
-func (s *HTTPService) Stop() {
-	s.h.Close()
+func (s *HTTPService) Errors() <-chan error {
+	return s.errCh
 }
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/mq"
+	"github.com/lyonnee/go-template/pkg/di"
+)
+
+func init() {
+	s := NewMQService()
+	RegisterService(s)
+}
+
+// MQService 运行通过 mq.RegisterConsumer 注册的消费者
+type MQService struct {
+	broker mq.Broker
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	errCh  chan error
+}
+
+func NewMQService() *MQService {
+	return &MQService{
+		broker: di.Get[mq.Broker](),
+		errCh:  make(chan error, 1),
+	}
+}
+
+func (s *MQService) Name() string {
+	return "mq"
+}
+
+func (s *MQService) Start(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, c := range mq.Consumers() {
+		s.wg.Add(1)
+		go func(c mq.Consumer) {
+			defer s.wg.Done()
+
+			if err := s.broker.Subscribe(runCtx, c.Topic, c.Group, c.Handler); err != nil {
+				select {
+				case s.errCh <- err:
+				default:
+				}
+			}
+		}(c)
+	}
+
+	return nil
+}
+
+// Stop 停止拉取新消息，等待处理中的消息完成后关闭消息队列
+func (s *MQService) Stop(ctx context.Context) error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return s.broker.Close()
+}
+
+func (s *MQService) Errors() <-chan error {
+	return s.errCh
+}
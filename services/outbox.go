@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+
+	"github.com/lyonnee/go-template/internal/infrastructure/config"
+	"github.com/lyonnee/go-template/internal/infrastructure/outbox"
+	"github.com/lyonnee/go-template/pkg/di"
+)
+
+func init() {
+	conf := di.Get[config.Config]()
+	if !conf.Event.PublishToMQ || !conf.Event.Outbox.Enable {
+		return
+	}
+
+	s := NewOutboxService()
+	RegisterService(s)
+}
+
+// OutboxService 运行事务发件箱 relay
+type OutboxService struct {
+	relay *outbox.Relay
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewOutboxService() *OutboxService {
+	return &OutboxService{
+		relay: di.Get[*outbox.Relay](),
+	}
+}
+
+func (s *OutboxService) Name() string {
+	return "outbox"
+}
+
+// DependsOn relay 向消息队列发布消息，需在消息队列之后启动、之前停止
+func (s *OutboxService) DependsOn() []string {
+	return []string{"mq"}
+}
+
+func (s *OutboxService) Start(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		s.relay.Run(runCtx)
+	}()
+
+	return nil
+}
+
+// Stop 停止轮询，等待当前批次投递完成
+func (s *OutboxService) Stop(ctx context.Context) error {
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,28 +1,225 @@
 package services
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/lyonnee/go-template/pkg/log"
+	"go.uber.org/zap"
+)
+
+// Service 由 Manager 管理生命周期的服务
 type Service interface {
-	Start()
-	Stop()
+	// Name 服务名称，用于依赖声明和日志
+	Name() string
+	// Start 启动服务，服务就绪后返回；长期运行的逻辑需自行放入协程
+	Start(ctx context.Context) error
+	// Stop 停止服务，需在 ctx 截止前完成
+	Stop(ctx context.Context) error
+}
+
+// Dependent 可选接口，声明当前服务依赖的其他服务名称
+// 依赖的服务会先于当前服务启动，并晚于当前服务停止
+type Dependent interface {
+	DependsOn() []string
+}
+
+// Watcher 可选接口，服务就绪后运行期间出现的致命错误通过 Errors 上报
+type Watcher interface {
+	Errors() <-chan error
 }
 
-var (
+// Manager 按依赖顺序启动服务，并按相反顺序停止
+type Manager struct {
+	mu       sync.Mutex
 	services []Service
-)
+	started  []Service
 
-func RegisterService(s Service) {
-	services = append(services, s)
+	errCh chan error
+	done  chan struct{}
 }
 
-// StartAll 启动所有服务
-func StartAll() {
-	for _, s := range services {
-		go s.Start()
+func NewManager() *Manager {
+	return &Manager{
+		errCh: make(chan error, 1),
+		done:  make(chan struct{}),
 	}
 }
 
-// StopAll 停止所有服务
-func StopAll() {
+// Register 注册服务，同名服务只能注册一次
+func (m *Manager) Register(s Service) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, registered := range m.services {
+		if registered.Name() == s.Name() {
+			panic(fmt.Sprintf("service %q already registered", s.Name()))
+		}
+	}
+	m.services = append(m.services, s)
+}
+
+// Errors 返回服务运行期间的致命错误，收到错误后应停止所有服务并退出
+func (m *Manager) Errors() <-chan error {
+	return m.errCh
+}
+
+// StartAll 按依赖顺序逐个启动服务
+// 任一服务启动失败时，已启动的服务会按相反顺序停止，并返回启动错误
+func (m *Manager) StartAll(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ordered, err := sortByDependency(m.services)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range ordered {
+		log.Info("Starting service", zap.String("service", s.Name()))
+
+		if err := s.Start(ctx); err != nil {
+			startErr := fmt.Errorf("start service %q: %w", s.Name(), err)
+			if stopErr := m.stopStarted(ctx); stopErr != nil {
+				return errors.Join(startErr, stopErr)
+			}
+			return startErr
+		}
+
+		m.started = append(m.started, s)
+		m.watch(s)
+
+		log.Info("Service started", zap.String("service", s.Name()))
+	}
+
+	return nil
+}
+
+// StopAll 按启动的相反顺序停止服务，返回所有停止错误
+func (m *Manager) StopAll(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stopStarted(ctx)
+}
+
+func (m *Manager) stopStarted(ctx context.Context) error {
+	select {
+	case <-m.done:
+	default:
+		close(m.done)
+	}
+
+	var errs []error
+	for i := len(m.started) - 1; i >= 0; i-- {
+		s := m.started[i]
+		log.Info("Stopping service", zap.String("service", s.Name()))
+
+		if err := s.Stop(ctx); err != nil {
+			log.Error("Failed to stop service", zap.String("service", s.Name()), zap.Error(err))
+			errs = append(errs, fmt.Errorf("stop service %q: %w", s.Name(), err))
+			continue
+		}
+
+		log.Info("Service stopped", zap.String("service", s.Name()))
+	}
+	m.started = nil
+
+	return errors.Join(errs...)
+}
+
+// watch 将服务运行期错误转发到 Manager 的错误通道
+func (m *Manager) watch(s Service) {
+	w, ok := s.(Watcher)
+	if !ok {
+		return
+	}
+
+	go func() {
+		select {
+		case err, ok := <-w.Errors():
+			if !ok || err == nil {
+				return
+			}
+			select {
+			case m.errCh <- fmt.Errorf("service %q failed: %w", s.Name(), err):
+			default:
+			}
+		case <-m.done:
+		}
+	}()
+}
+
+// sortByDependency 对服务做拓扑排序，无依赖关系的服务保持注册顺序
+func sortByDependency(services []Service) ([]Service, error) {
+	byName := make(map[string]Service, len(services))
+	for _, s := range services {
+		byName[s.Name()] = s
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(services))
+	ordered := make([]Service, 0, len(services))
+
+	var visit func(s Service) error
+	visit = func(s Service) error {
+		switch state[s.Name()] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("service %q has a circular dependency", s.Name())
+		}
+		state[s.Name()] = visiting
+
+		if d, ok := s.(Dependent); ok {
+			for _, name := range d.DependsOn() {
+				dep, ok := byName[name]
+				if !ok {
+					return fmt.Errorf("service %q depends on unregistered service %q", s.Name(), name)
+				}
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+
+		state[s.Name()] = visited
+		ordered = append(ordered, s)
+		return nil
+	}
+
 	for _, s := range services {
-		s.Stop()
+		if err := visit(s); err != nil {
+			return nil, err
+		}
 	}
+
+	return ordered, nil
+}
+
+var defaultManager = NewManager()
+
+func RegisterService(s Service) {
+	defaultManager.Register(s)
+}
+
+// StartAll 启动所有服务
+func StartAll(ctx context.Context) error {
+	return defaultManager.StartAll(ctx)
+}
+
+// StopAll 停止所有服务
+func StopAll(ctx context.Context) error {
+	return defaultManager.StopAll(ctx)
+}
+
+// Errors 返回服务运行期间的致命错误
+func Errors() <-chan error {
+	return defaultManager.Errors()
 }
@@ -0,0 +1,2 @@
+-- 删除用户表
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,18 @@
+-- 创建用户表
+CREATE TABLE IF NOT EXISTS users (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '用户ID，自增主键',
+    created_at BIGINT NOT NULL COMMENT '用户创建时间戳（UTC时区）',
+    updated_at BIGINT NOT NULL COMMENT '用户信息更新时间戳（UTC时区）',
+
+    deleted_at BIGINT NOT NULL DEFAULT 0 COMMENT '删除时间戳（UTC时区）',
+
+    username VARCHAR(255) NOT NULL UNIQUE,
+    pwd_secret VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL UNIQUE DEFAULT '',
+    phone VARCHAR(50) NOT NULL UNIQUE DEFAULT '',
+
+    -- 创建索引
+    INDEX idx_users_username (username),
+    INDEX idx_users_email (email),
+    INDEX idx_users_phone (phone)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户表';
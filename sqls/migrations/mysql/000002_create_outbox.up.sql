@@ -0,0 +1,19 @@
+-- 创建事务发件箱表
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '消息ID，自增主键',
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+
+    topic VARCHAR(255) NOT NULL COMMENT '消息队列 topic',
+    payload LONGBLOB NOT NULL,
+    headers JSON NOT NULL DEFAULT ('{}'),
+
+    status SMALLINT NOT NULL DEFAULT 0 COMMENT '状态：0 待投递，1 已投递，2 已隔离',
+    attempts INT NOT NULL DEFAULT 0 COMMENT '已投递失败次数',
+    next_attempt_at BIGINT NOT NULL DEFAULT 0 COMMENT '下次投递时间戳（UTC时区）',
+    last_error TEXT NOT NULL DEFAULT ('') COMMENT '最近一次投递失败原因',
+    delivered_at BIGINT NOT NULL DEFAULT 0 COMMENT '投递成功时间戳（UTC时区）',
+
+    -- 创建索引
+    INDEX idx_outbox_status_next_attempt_at (status, next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='事务发件箱表，与业务数据在同一事务中写入，由 relay 投递到消息队列';
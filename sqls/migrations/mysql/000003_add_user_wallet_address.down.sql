@@ -0,0 +1,9 @@
+-- 删除钱包地址字段
+DROP INDEX uk_users_wallet_address ON users;
+ALTER TABLE users DROP COLUMN wallet_address;
+
+-- 恢复邮箱和手机号的唯一约束，存在多个空值时需先处理数据
+DROP INDEX uk_users_email ON users;
+DROP INDEX uk_users_phone ON users;
+ALTER TABLE users ADD UNIQUE INDEX email (email);
+ALTER TABLE users ADD UNIQUE INDEX phone (phone);
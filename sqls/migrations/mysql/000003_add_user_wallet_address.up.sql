@@ -0,0 +1,12 @@
+-- 用户表增加钱包地址字段，用于 Sign-In with Ethereum 登录
+ALTER TABLE users ADD COLUMN wallet_address VARCHAR(42) NOT NULL DEFAULT '' COMMENT '钱包地址（EIP-55 校验和格式），未绑定时为空';
+
+-- 钱包用户没有邮箱和手机号，唯一约束改为只约束非空值
+-- MySQL 不支持部分索引，使用函数索引，空字符串转换为 NULL 后不参与唯一约束（需要 MySQL 8.0.13+）
+ALTER TABLE users DROP INDEX email;
+ALTER TABLE users DROP INDEX phone;
+CREATE UNIQUE INDEX uk_users_email ON users ((NULLIF(email, '')));
+CREATE UNIQUE INDEX uk_users_phone ON users ((NULLIF(phone, '')));
+
+-- 创建索引
+CREATE UNIQUE INDEX uk_users_wallet_address ON users ((NULLIF(wallet_address, '')));
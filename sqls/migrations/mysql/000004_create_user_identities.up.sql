@@ -0,0 +1,16 @@
+-- 创建第三方身份表
+CREATE TABLE IF NOT EXISTS user_identities (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '身份ID，自增主键',
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '关联的用户ID',
+    provider VARCHAR(64) NOT NULL COMMENT '身份提供方名称',
+    subject VARCHAR(255) NOT NULL COMMENT '身份提供方中的用户唯一标识（ID Token 的 sub）',
+    email VARCHAR(255) NOT NULL DEFAULT '' COMMENT '关联时身份提供方返回的邮箱',
+
+    UNIQUE (provider, subject),
+
+    -- 创建索引
+    INDEX idx_user_identities_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='第三方身份表，记录用户在 OAuth2 / OIDC 身份提供方的账号';
@@ -0,0 +1,56 @@
+-- 创建角色表
+CREATE TABLE IF NOT EXISTS roles (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+
+    name VARCHAR(64) NOT NULL UNIQUE COMMENT '角色名称，唯一',
+    description VARCHAR(255) NOT NULL DEFAULT '' COMMENT '角色描述'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='角色表';
+
+-- 创建权限表
+CREATE TABLE IF NOT EXISTS permissions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+
+    name VARCHAR(128) NOT NULL UNIQUE COMMENT '权限名称，唯一',
+    description VARCHAR(255) NOT NULL DEFAULT '' COMMENT '权限描述'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='权限表，权限格式为 资源:操作，* 和 资源:* 表示通配';
+
+-- 创建角色权限关联表
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id BIGINT UNSIGNED NOT NULL,
+    permission_id BIGINT UNSIGNED NOT NULL,
+
+    PRIMARY KEY (role_id, permission_id),
+    FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE,
+    FOREIGN KEY (permission_id) REFERENCES permissions (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='角色权限关联表';
+
+-- 创建用户角色关联表
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id BIGINT UNSIGNED NOT NULL,
+    role_id BIGINT UNSIGNED NOT NULL,
+    created_at BIGINT NOT NULL,
+
+    PRIMARY KEY (user_id, role_id),
+    FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE,
+
+    -- 创建索引
+    INDEX idx_user_roles_role_id (role_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='用户角色关联表';
+
+-- 初始化数据：管理员拥有全部权限
+INSERT IGNORE INTO permissions (created_at, updated_at, name, description) VALUES
+    (UNIX_TIMESTAMP(), UNIX_TIMESTAMP(), '*', '全部权限'),
+    (UNIX_TIMESTAMP(), UNIX_TIMESTAMP(), 'users:read', '查看任意用户信息'),
+    (UNIX_TIMESTAMP(), UNIX_TIMESTAMP(), 'users:write', '修改任意用户信息'),
+    (UNIX_TIMESTAMP(), UNIX_TIMESTAMP(), 'users:unlock', '解除登录锁定');
+
+INSERT IGNORE INTO roles (created_at, updated_at, name, description) VALUES
+    (UNIX_TIMESTAMP(), UNIX_TIMESTAMP(), 'admin', '管理员，可以查看和管理任意用户');
+
+INSERT IGNORE INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name = '*';
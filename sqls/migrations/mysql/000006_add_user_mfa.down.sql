@@ -0,0 +1,5 @@
+-- 删除恢复码表
+DROP TABLE IF EXISTS user_recovery_codes;
+
+-- 删除多因素认证字段
+ALTER TABLE users DROP COLUMN mfa_enabled, DROP COLUMN mfa_secret;
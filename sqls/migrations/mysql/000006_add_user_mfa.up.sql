@@ -0,0 +1,16 @@
+-- 用户表增加多因素认证字段
+ALTER TABLE users
+    ADD COLUMN mfa_secret VARCHAR(255) NOT NULL DEFAULT '' COMMENT '加密后的 TOTP 密钥（AES-GCM），启用确认前为待确认的密钥',
+    ADD COLUMN mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE COMMENT '是否已启用多因素认证';
+
+-- 创建恢复码表
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    created_at BIGINT NOT NULL,
+
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '关联的用户ID',
+    code_hash VARCHAR(64) NOT NULL COMMENT '恢复码的 SHA-256 哈希（十六进制），不保存明文',
+    used_at BIGINT NOT NULL DEFAULT 0 COMMENT '使用时间戳（UTC时区），0 表示未使用',
+
+    UNIQUE (user_id, code_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='多因素认证恢复码表，每个恢复码只能使用一次';
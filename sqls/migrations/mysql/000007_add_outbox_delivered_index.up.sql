@@ -0,0 +1,2 @@
+-- 发件箱增加投递时间索引，用于定时清理过期的已投递消息
+CREATE INDEX idx_outbox_status_delivered_at ON outbox (status, delivered_at);
@@ -0,0 +1,2 @@
+-- 删除事务发件箱表
+DROP TABLE IF EXISTS outbox;
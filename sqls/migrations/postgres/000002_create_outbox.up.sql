@@ -0,0 +1,28 @@
+-- 创建事务发件箱表
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+
+    topic VARCHAR(255) NOT NULL,
+    payload BYTEA NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+
+    status SMALLINT NOT NULL DEFAULT 0,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at BIGINT NOT NULL DEFAULT 0
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_outbox_status_next_attempt_at ON outbox (status, next_attempt_at);
+
+COMMENT ON TABLE outbox IS '事务发件箱表，与业务数据在同一事务中写入，由 relay 投递到消息队列';
+COMMENT ON COLUMN outbox.id IS '消息ID，自增主键';
+COMMENT ON COLUMN outbox.topic IS '消息队列 topic';
+COMMENT ON COLUMN outbox.status IS '状态：0 待投递，1 已投递，2 已隔离';
+COMMENT ON COLUMN outbox.attempts IS '已投递失败次数';
+COMMENT ON COLUMN outbox.next_attempt_at IS '下次投递时间戳（UTC时区）';
+COMMENT ON COLUMN outbox.last_error IS '最近一次投递失败原因';
+COMMENT ON COLUMN outbox.delivered_at IS '投递成功时间戳（UTC时区）';
@@ -0,0 +1,9 @@
+-- 删除钱包地址字段
+DROP INDEX IF EXISTS uk_users_wallet_address;
+ALTER TABLE users DROP COLUMN IF EXISTS wallet_address;
+
+-- 恢复邮箱和手机号的唯一约束，存在多个空值时需先处理数据
+DROP INDEX IF EXISTS uk_users_email;
+DROP INDEX IF EXISTS uk_users_phone;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users ADD CONSTRAINT users_phone_key UNIQUE (phone);
@@ -0,0 +1,13 @@
+-- 用户表增加钱包地址字段，用于 Sign-In with Ethereum 登录
+ALTER TABLE users ADD COLUMN IF NOT EXISTS wallet_address VARCHAR(42) NOT NULL DEFAULT '';
+
+-- 钱包用户没有邮箱和手机号，唯一约束改为只约束非空值
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_phone_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uk_users_email ON users (email) WHERE email <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS uk_users_phone ON users (phone) WHERE phone <> '';
+
+-- 创建索引
+CREATE UNIQUE INDEX IF NOT EXISTS uk_users_wallet_address ON users (wallet_address) WHERE wallet_address <> '';
+
+COMMENT ON COLUMN users.wallet_address IS '钱包地址（EIP-55 校验和格式），未绑定时为空';
@@ -0,0 +1,2 @@
+-- 删除第三方身份表
+DROP TABLE IF EXISTS user_identities;
@@ -0,0 +1,23 @@
+-- 创建第三方身份表
+CREATE TABLE IF NOT EXISTS user_identities (
+    id BIGSERIAL PRIMARY KEY,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+
+    user_id BIGINT NOT NULL,
+    provider VARCHAR(64) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+
+    UNIQUE (provider, subject)
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
+
+COMMENT ON TABLE user_identities IS '第三方身份表，记录用户在 OAuth2 / OIDC 身份提供方的账号';
+COMMENT ON COLUMN user_identities.id IS '身份ID，自增主键';
+COMMENT ON COLUMN user_identities.user_id IS '关联的用户ID';
+COMMENT ON COLUMN user_identities.provider IS '身份提供方名称';
+COMMENT ON COLUMN user_identities.subject IS '身份提供方中的用户唯一标识（ID Token 的 sub）';
+COMMENT ON COLUMN user_identities.email IS '关联时身份提供方返回的邮箱';
@@ -0,0 +1,67 @@
+-- 创建角色表
+CREATE TABLE IF NOT EXISTS roles (
+    id BIGSERIAL PRIMARY KEY,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+
+    name VARCHAR(64) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+-- 创建权限表
+CREATE TABLE IF NOT EXISTS permissions (
+    id BIGSERIAL PRIMARY KEY,
+    created_at BIGINT NOT NULL,
+    updated_at BIGINT NOT NULL,
+
+    name VARCHAR(128) NOT NULL UNIQUE,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+-- 创建角色权限关联表
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id BIGINT NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id BIGINT NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+
+    PRIMARY KEY (role_id, permission_id)
+);
+
+-- 创建用户角色关联表
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id BIGINT NOT NULL,
+    role_id BIGINT NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    created_at BIGINT NOT NULL,
+
+    PRIMARY KEY (user_id, role_id)
+);
+
+-- 创建索引
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles (role_id);
+
+COMMENT ON TABLE roles IS '角色表';
+COMMENT ON COLUMN roles.name IS '角色名称，唯一';
+COMMENT ON COLUMN roles.description IS '角色描述';
+
+COMMENT ON TABLE permissions IS '权限表，权限格式为 资源:操作，* 和 资源:* 表示通配';
+COMMENT ON COLUMN permissions.name IS '权限名称，唯一';
+COMMENT ON COLUMN permissions.description IS '权限描述';
+
+COMMENT ON TABLE role_permissions IS '角色权限关联表';
+COMMENT ON TABLE user_roles IS '用户角色关联表';
+
+-- 初始化数据：管理员拥有全部权限
+INSERT INTO permissions (created_at, updated_at, name, description) VALUES
+    (EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT, '*', '全部权限'),
+    (EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT, 'users:read', '查看任意用户信息'),
+    (EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT, 'users:write', '修改任意用户信息'),
+    (EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT, 'users:unlock', '解除登录锁定')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO roles (created_at, updated_at, name, description) VALUES
+    (EXTRACT(EPOCH FROM NOW())::BIGINT, EXTRACT(EPOCH FROM NOW())::BIGINT, 'admin', '管理员，可以查看和管理任意用户')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.name = '*'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,23 @@
+-- 用户表增加多因素认证字段
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_secret VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN users.mfa_secret IS '加密后的 TOTP 密钥（AES-GCM），启用确认前为待确认的密钥';
+COMMENT ON COLUMN users.mfa_enabled IS '是否已启用多因素认证';
+
+-- 创建恢复码表
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    created_at BIGINT NOT NULL,
+
+    user_id BIGINT NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at BIGINT NOT NULL DEFAULT 0,
+
+    UNIQUE (user_id, code_hash)
+);
+
+COMMENT ON TABLE user_recovery_codes IS '多因素认证恢复码表，每个恢复码只能使用一次';
+COMMENT ON COLUMN user_recovery_codes.user_id IS '关联的用户ID';
+COMMENT ON COLUMN user_recovery_codes.code_hash IS '恢复码的 SHA-256 哈希（十六进制），不保存明文';
+COMMENT ON COLUMN user_recovery_codes.used_at IS '使用时间戳（UTC时区），0 表示未使用';
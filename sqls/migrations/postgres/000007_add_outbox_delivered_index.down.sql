@@ -0,0 +1,2 @@
+-- 删除投递时间索引
+DROP INDEX IF EXISTS idx_outbox_status_delivered_at;
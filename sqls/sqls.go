@@ -0,0 +1,21 @@
+// Package sqls 数据库迁移脚本，编译时嵌入到程序中
+package sqls
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+)
+
+//go:embed migrations/*/*.sql
+var migrations embed.FS
+
+// Migrations 返回方言对应的迁移脚本目录，文件名格式为 {版本号}_{名称}.up.sql 和 {版本号}_{名称}.down.sql
+// 各方言的迁移版本号保持一致
+func Migrations(dialect string) fs.FS {
+	sub, err := fs.Sub(migrations, path.Join("migrations", dialect))
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}